@@ -20,6 +20,9 @@ import (
 	"github.com/mhdiiilham/gosm/repository"
 	"github.com/mhdiiilham/gosm/service"
 	"github.com/mhdiiilham/gosm/thirdparty/kirimwa"
+	"github.com/mhdiiilham/gosm/thirdparty/notifier"
+	"github.com/mhdiiilham/gosm/thirdparty/oidc"
+	"github.com/mhdiiilham/gosm/thirdparty/storage"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
@@ -66,38 +69,208 @@ func main() {
 
 	logger.Infof(ctx, ops, "starting echo...")
 	e := echo.New()
+	ipExtractor, err := delivery.NewIPExtractor(cfg.TrustedProxies)
+	if err != nil {
+		logger.Fatalf(ctx, ops, "invalid trusted proxies: %v", err)
+	}
+	e.IPExtractor = ipExtractor
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	e.Use(middleware.RequestID())
+	e.Use(delivery.RequestContextMiddleware)
 	e.Use(middleware.Logger())
-	e.Use(middleware.CORS())
+	// browsers only let clients read the ETag header, needed to change events, when it is exposed.
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{ExposeHeaders: []string{"ETag"}}))
 	e.GET("/api", delivery.RootHandler(dbConn))
 
 	// pkg here:
-	passwordHasher := pkg.Hasher{}
-	jwtToken := pkg.NewJwtGenerator(cfg.Name, cfg.JWTKey)
+	passwordHasher := pkg.NewHasher(pkg.HasherConfig{
+		Algorithm:         cfg.Auth.PasswordHashing.Algorithm,
+		BcryptCost:        cfg.Auth.PasswordHashing.BcryptCost,
+		Argon2Memory:      cfg.Auth.PasswordHashing.Argon2Memory,
+		Argon2Iterations:  cfg.Auth.PasswordHashing.Argon2Iterations,
+		Argon2Parallelism: cfg.Auth.PasswordHashing.Argon2Parallelism,
+	})
+	jwtToken := pkg.NewJwtGenerator(cfg.Name, cfg.JWTKey, cfg.Auth.AccessTokenTTL)
 	kirimWaClient := kirimwa.NewKirimWAClient(cfg.Service.KirimWa.Key, cfg.Service.KirimWa.DeviceID)
+	if cfg.Auth.AllowInsecureSSOIssuer && cfg.Env == "production" {
+		logger.Fatalf(ctx, ops, "single sign-on issuers served over plain http cannot be allowed in production")
+	}
+	oidcClient := oidc.NewClient(cfg.Auth.AllowInsecureSSOIssuer)
+
+	// never serve the working directory when no storage path is configured.
+	if cfg.Storage.LocalPath == "" {
+		cfg.Storage.LocalPath = "uploads"
+	}
+	fileStorage := storage.NewLocalStorage(cfg.Storage.LocalPath, cfg.Storage.PublicURL)
+	e.Static("/uploads", cfg.Storage.LocalPath)
+
+	// the log and file drivers never deliver the reset, verification and invitation links.
+	var notificationSender service.Notifier
+	switch cfg.Notifier.Driver {
+	case "smtp":
+		if cfg.Notifier.SMTP.Host == "" || cfg.Notifier.SMTP.From == "" {
+			logger.Fatalf(ctx, ops, "the smtp notifier requires notifier.smtp.host and notifier.smtp.from")
+		}
+		notificationSender = notifier.NewSMTPNotifier(
+			cfg.Notifier.SMTP.Host,
+			cfg.Notifier.SMTP.Port,
+			cfg.Notifier.SMTP.Username,
+			cfg.Notifier.SMTP.Password,
+			cfg.Notifier.SMTP.From,
+		)
+	case "log", "file":
+		if cfg.Env == "production" {
+			logger.Fatalf(ctx, ops, "the %s notifier does not deliver notifications, it cannot be used in production", cfg.Notifier.Driver)
+		}
+		notificationSender = notifier.NewLogNotifier()
+		if cfg.Notifier.Driver == "file" {
+			notificationSender = notifier.NewFileNotifier(cfg.Notifier.FilePath)
+		}
+	default:
+		logger.Fatalf(ctx, ops, "unknown notifier driver %q", cfg.Notifier.Driver)
+	}
 
 	// Repositories here:
 	userRepository := repository.NewUserRepository(dbConn)
 	eventRepository := repository.NewEventRepository(dbConn)
 	companyRepository := repository.NewCompanyRepository(dbConn)
+	refreshTokenRepository := repository.NewRefreshTokenRepository(dbConn)
+	sessionRepository := repository.NewSessionRepository(dbConn)
+	passwordResetRepository := repository.NewPasswordResetRepository(dbConn)
+	emailVerificationRepository := repository.NewEmailVerificationRepository(dbConn)
+	invitationRepository := repository.NewInvitationRepository(dbConn)
+	rolePermissionRepository := repository.NewRolePermissionRepository(dbConn)
+	apiKeyRepository := repository.NewAPIKeyRepository(dbConn)
+	twoFactorRepository := repository.NewTwoFactorRepository(dbConn)
+	ssoRepository := repository.NewSSORepository(dbConn)
+	auditRepository := repository.NewAuditRepository(dbConn)
+	planRepository := repository.NewPlanRepository(dbConn)
+
+	var signInAttemptStore service.SignInAttemptStore = repository.NewMemorySignInAttemptStore()
+	if cfg.Auth.SignInThrottle.Store == "postgres" {
+		signInAttemptStore = repository.NewSignInAttemptRepository(dbConn)
+	}
 
 	// Usecase here:
-	authService := service.NewAuthorizationService(userRepository, companyRepository, passwordHasher, jwtToken)
-	eventService := service.NewEventService(eventRepository, kirimWaClient, eventRepository.RunInTransactions)
+	auditService := service.NewAuditService(auditRepository)
+	planService := service.NewPlanService(planRepository)
+	guestDataService := service.NewGuestDataService(eventRepository, auditService)
+	authService := service.NewAuthorizationService(
+		userRepository,
+		companyRepository,
+		refreshTokenRepository,
+		sessionRepository,
+		passwordResetRepository,
+		emailVerificationRepository,
+		twoFactorRepository,
+		signInAttemptStore,
+		passwordHasher,
+		jwtToken,
+		notificationSender,
+		auditService,
+		service.AuthenticatorConfig{
+			RefreshTokenTTL:           cfg.Auth.RefreshTokenTTL,
+			RememberRefreshTokenTTL:   cfg.Auth.RememberRefreshTokenTTL,
+			PasswordResetTokenTTL:     cfg.Auth.PasswordResetTokenTTL,
+			PasswordResetURL:          cfg.FrontendURL + "/reset-password",
+			EmailVerificationTokenTTL: cfg.Auth.EmailVerificationTokenTTL,
+			EmailVerificationURL:      cfg.FrontendURL + "/verify-email",
+			TwoFactorChallengeTTL:     cfg.Auth.TwoFactorChallengeTTL,
+			TwoFactorIssuer:           cfg.Name,
+			SignInThrottle: service.SignInThrottleConfig{
+				MaxFailuresPerEmail: cfg.Auth.SignInThrottle.MaxFailuresPerEmail,
+				MaxFailuresPerIP:    cfg.Auth.SignInThrottle.MaxFailuresPerIP,
+				BaseLockout:         cfg.Auth.SignInThrottle.BaseLockout,
+				MaxLockout:          cfg.Auth.SignInThrottle.MaxLockout,
+				FailureWindow:       cfg.Auth.SignInThrottle.FailureWindow,
+			},
+		},
+	)
+	teamService := service.NewTeamService(
+		userRepository,
+		companyRepository,
+		invitationRepository,
+		refreshTokenRepository,
+		apiKeyRepository,
+		passwordHasher,
+		notificationSender,
+		kirimWaClient,
+		planService,
+		cfg.Auth.InvitationTTL,
+		cfg.FrontendURL+"/accept-invitation",
+		invitationRepository.RunInTransactions,
+	)
+	eventService := service.NewEventService(eventRepository, kirimWaClient, auditService, planService, planService, eventRepository.RunInTransactions)
+	companyService := service.NewCompanyService(companyRepository, fileStorage, auditService)
+	permissionService := service.NewPermissionService(rolePermissionRepository, cfg.Auth.PermissionCacheTTL)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, permissionService, userRepository)
+	backOfficeService := service.NewBackOfficeService(
+		companyRepository,
+		userRepository,
+		eventRepository,
+		planRepository,
+		jwtToken,
+		auditService,
+		cfg.Auth.ImpersonationTokenTTL,
+	)
+	ssoService := service.NewSSOService(
+		ssoRepository,
+		userRepository,
+		companyRepository,
+		passwordHasher,
+		oidcClient,
+		authService,
+		cfg.FrontendURL+"/sso/callback",
+		cfg.Auth.SSOStateTTL,
+		cfg.Auth.AllowInsecureSSOIssuer,
+	)
 
 	// register routes here:
 	e.GET("/api/v1/public/guests", delivery.GetGuestByItShortID(eventService))
 	e.POST("/api/v1/public/guests/:eventId", delivery.AddGuestToEvent(eventService))
 	e.GET("/api/v1/public/guests/:eventId/messages", delivery.HandleGetGuestMessages(eventService))
 
-	middleware := delivery.NewMiddleware(jwtToken, userRepository)
+	middleware := delivery.NewMiddleware(
+		jwtToken,
+		userRepository,
+		companyRepository,
+		sessionRepository,
+		eventRepository,
+		permissionService,
+		apiKeyService,
+		cfg.Auth.RequireEmailVerification,
+	)
 
 	authHandler := delivery.NewAuthHandler(authService)
 	authHandler.RegisterAuthRoutes(e.Group("api/v1/auth"), middleware)
 
+	teamHandler := delivery.NewTeamHandler(teamService, authService)
+	teamHandler.RegisterTeamRoutes(e.Group("api/v1/team"), middleware)
+
 	eventHandler := delivery.NewEventHandler(eventService)
 	eventHandler.RegisterEventRoutes(e.Group("api/v1/events"), middleware)
 
+	permissionHandler := delivery.NewPermissionHandler(permissionService)
+	permissionHandler.RegisterPermissionRoutes(e.Group("api/v1/permissions"), middleware)
+
+	apiKeyHandler := delivery.NewAPIKeyHandler(apiKeyService)
+	apiKeyHandler.RegisterAPIKeyRoutes(e.Group("api/v1/api-keys"), middleware)
+
+	ssoHandler := delivery.NewSSOHandler(ssoService)
+	ssoHandler.RegisterSSORoutes(e.Group("api/v1/sso"), middleware)
+
+	companyHandler := delivery.NewCompanyHandler(companyService, planService)
+	companyHandler.RegisterCompanyRoutes(e.Group("api/v1/company"), middleware)
+
+	auditHandler := delivery.NewAuditHandler(auditService)
+	auditHandler.RegisterAuditRoutes(e.Group("api/v1/audit"), middleware)
+
+	guestDataHandler := delivery.NewGuestDataHandler(guestDataService)
+	guestDataHandler.RegisterGuestDataRoutes(e.Group("api/v1/guest-data"), middleware)
+
+	backOfficeHandler := delivery.NewBackOfficeHandler(backOfficeService)
+	backOfficeHandler.RegisterBackOfficeRoutes(e.Group("api/v1/admin"), middleware)
+
 	// Start server
 	go func() {
 		if err := e.Start(cfg.GetPort()); err != nil && err != http.ErrServerClosed {
@@ -0,0 +1,265 @@
+// Command stub-idp runs a minimal OpenID Connect provider for trying the single sign-on locally.
+// It signs in whoever claims an email address, so it must never be exposed.
+//
+// Set auth.allowInsecureSsoIssuer in the configuration of the API, since the stub is served over plain http,
+// configure a company with the issuer printed at startup and the client ID given with -client-id, then:
+//
+//	go run cmd/stub-idp/main.go -addr :9000 -client-id gosm
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const keyID = "stub-idp"
+
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	email         string
+	name          string
+	expiresAt     time.Time
+}
+
+type stubProvider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+var signInPage = template.Must(template.New("sign-in").Parse(`<!doctype html>
+<title>stub-idp</title>
+<form method="get">
+	{{range $name, $values := .}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">{{end}}{{end}}
+	<p><label>Email <input name="email" type="email" required></label></p>
+	<p><label>Name <input name="name"></label></p>
+	<button type="submit">Sign in</button>
+</form>`))
+
+func main() {
+	addr := flag.String("addr", ":9000", "address to listen on")
+	issuer := flag.String("issuer", "", "issuer url, defaults to http://localhost<addr>")
+	clientID := flag.String("client-id", "gosm", "accepted client id")
+	clientSecret := flag.String("client-secret", "", "accepted client secret, empty to accept public clients")
+	flag.Parse()
+
+	if *issuer == "" {
+		*issuer = "http://localhost" + *addr
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatalf("failed to generate signing key: %v", err)
+	}
+
+	provider := &stubProvider{
+		issuer:       strings.TrimSuffix(*issuer, "/"),
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		key:          key,
+		codes:        map[string]authorization{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", provider.handleDiscovery)
+	mux.HandleFunc("/jwks", provider.handleJWKS)
+	mux.HandleFunc("/authorize", provider.handleAuthorize)
+	mux.HandleFunc("/token", provider.handleToken)
+
+	log.Printf("stub-idp issuer %s, client id %q", provider.issuer, provider.clientID)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
+
+func (p *stubProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *stubProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// handleAuthorize signs in the email given as `email` or `login_hint` query parameter,
+// or shows a form asking for one, and sends the user back to the client with a code.
+func (p *stubProvider) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("client_id") != p.clientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "only the authorization code flow with S256 PKCE is supported", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirectURI.Scheme == "" {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	email := query.Get("email")
+	if email == "" {
+		email = query.Get("login_hint")
+	}
+
+	if email == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = signInPage.Execute(w, query)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		clientID:      p.clientID,
+		redirectURI:   redirectURI.String(),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		email:         email,
+		name:          query.Get("name"),
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	callbackQuery := redirectURI.Query()
+	callbackQuery.Set("code", code)
+	callbackQuery.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callbackQuery.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (p *stubProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	if clientID != p.clientID || (p.clientSecret != "" && clientSecret != p.clientSecret) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	auth, found := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !found ||
+		time.Now().After(auth.expiresAt) ||
+		r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("redirect_uri") != auth.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.issuer,
+		"aud":            auth.clientID,
+		"sub":            "stub|" + strings.ToLower(auth.email),
+		"email":          auth.email,
+		"email_verified": true,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	}
+
+	if auth.nonce != "" {
+		claims["nonce"] = auth.nonce
+	}
+
+	if auth.name != "" {
+		claims["name"] = auth.name
+		if given, family, found := strings.Cut(auth.name, " "); found {
+			claims["given_name"], claims["family_name"] = given, family
+		} else {
+			claims["given_name"] = auth.name
+		}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func randomString() string {
+	random := make([]byte, 24)
+	if _, err := rand.Read(random); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -3,19 +3,98 @@ package config
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/mhdiiilham/gosm/logger"
 	"github.com/spf13/viper"
 )
 
 // Configuration represent variables need to run the service.
+// TrustedProxies are the CIDR ranges of the reverse proxies whose X-Forwarded-For header gives the client IP address,
+// the header is ignored when none is set.
 type Configuration struct {
-	Env      string   `mapstructure:"env"`
-	Name     string   `mapstructure:"name"`
-	Port     string   `mapstructure:"port"`
-	JWTKey   string   `mapstructure:"jwtKey"`
-	Database Database `mapstructure:"database"`
-	Service  Service  `mapstructure:"services"`
+	Env            string   `mapstructure:"env"`
+	Name           string   `mapstructure:"name"`
+	Port           string   `mapstructure:"port"`
+	JWTKey         string   `mapstructure:"jwtKey"`
+	FrontendURL    string   `mapstructure:"frontendUrl"`
+	TrustedProxies []string `mapstructure:"trustedProxies"`
+	Auth           Auth     `mapstructure:"auth"`
+	Database       Database `mapstructure:"database"`
+	Service        Service  `mapstructure:"services"`
+	Notifier       Notifier `mapstructure:"notifier"`
+	Storage        Storage  `mapstructure:"storage"`
+}
+
+// Auth represent variables required to issue access and refresh tokens.
+// RequireEmailVerification blocks authenticated endpoints until the user verified their email address.
+// AllowInsecureSSOIssuer lets companies use identity providers served over plain http, such as the stub IdP,
+// it is meant for development and refused in production.
+type Auth struct {
+	AccessTokenTTL            time.Duration   `mapstructure:"accessTokenTTL"`
+	RefreshTokenTTL           time.Duration   `mapstructure:"refreshTokenTTL"`
+	RememberRefreshTokenTTL   time.Duration   `mapstructure:"rememberRefreshTokenTTL"`
+	PasswordResetTokenTTL     time.Duration   `mapstructure:"passwordResetTokenTTL"`
+	EmailVerificationTokenTTL time.Duration   `mapstructure:"emailVerificationTokenTTL"`
+	RequireEmailVerification  bool            `mapstructure:"requireEmailVerification"`
+	InvitationTTL             time.Duration   `mapstructure:"invitationTTL"`
+	PermissionCacheTTL        time.Duration   `mapstructure:"permissionCacheTTL"`
+	TwoFactorChallengeTTL     time.Duration   `mapstructure:"twoFactorChallengeTTL"`
+	SSOStateTTL               time.Duration   `mapstructure:"ssoStateTTL"`
+	AllowInsecureSSOIssuer    bool            `mapstructure:"allowInsecureSsoIssuer"`
+	ImpersonationTokenTTL     time.Duration   `mapstructure:"impersonationTokenTTL"`
+	SignInThrottle            SignInThrottle  `mapstructure:"signInThrottle"`
+	PasswordHashing           PasswordHashing `mapstructure:"passwordHashing"`
+}
+
+// PasswordHashing represent variables required to hash passwords.
+// Algorithm is either `bcrypt` or `argon2id`, Argon2Memory is expressed in KiB.
+// Existing hashes produced with another algorithm or weaker parameters are upgraded on the next sign in.
+type PasswordHashing struct {
+	Algorithm         string `mapstructure:"algorithm"`
+	BcryptCost        int    `mapstructure:"bcryptCost"`
+	Argon2Memory      uint32 `mapstructure:"argon2Memory"`
+	Argon2Iterations  uint32 `mapstructure:"argon2Iterations"`
+	Argon2Parallelism uint8  `mapstructure:"argon2Parallelism"`
+}
+
+// SignInThrottle represent variables required to throttle failed sign in attempts.
+// Store is either `memory` or `postgres`, the latter is required when running more than one instance.
+type SignInThrottle struct {
+	Store               string        `mapstructure:"store"`
+	MaxFailuresPerEmail int           `mapstructure:"maxFailuresPerEmail"`
+	MaxFailuresPerIP    int           `mapstructure:"maxFailuresPerIp"`
+	BaseLockout         time.Duration `mapstructure:"baseLockout"`
+	MaxLockout          time.Duration `mapstructure:"maxLockout"`
+	FailureWindow       time.Duration `mapstructure:"failureWindow"`
+}
+
+// Notifier represent variables required to deliver notifications such as password reset links.
+// Driver is either `smtp`, `log` or `file`, FilePath is only used by the `file` driver and SMTP by the `smtp` driver.
+// The `log` and `file` drivers do not deliver anything, they are refused in production.
+type Notifier struct {
+	Driver   string `mapstructure:"driver"`
+	FilePath string `mapstructure:"filePath"`
+	SMTP     SMTP   `mapstructure:"smtp"`
+}
+
+// SMTP represent variables required to send emails through an SMTP server.
+// Username and Password are only used when Username is set.
+type SMTP struct {
+	Host     string `mapstructure:"host"`
+	Port     string `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+// Storage represent variables required to store uploaded files such as company logos.
+// Driver is `local`, which writes the files into LocalPath and serves them under `/uploads`,
+// so PublicURL has to be the public address of the API followed by `/uploads`.
+type Storage struct {
+	Driver    string `mapstructure:"driver"`
+	LocalPath string `mapstructure:"localPath"`
+	PublicURL string `mapstructure:"publicUrl"`
 }
 
 // Database represent variables required to connect to database.
@@ -0,0 +1,13 @@
+CREATE TABLE "refresh_tokens" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" INTEGER NOT NULL,
+    "family_id" VARCHAR NOT NULL,
+    "token_hash" VARCHAR NOT NULL UNIQUE,
+    "remember" BOOLEAN NOT NULL DEFAULT false,
+    "expires_at" TIMESTAMP NOT NULL,
+    "revoked_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX "refresh_tokens_family_id_idx" ON "refresh_tokens" ("family_id");
+CREATE INDEX "refresh_tokens_user_id_idx" ON "refresh_tokens" ("user_id");
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    DROP COLUMN password_changed_at;
+
+DROP TABLE IF EXISTS "password_reset_tokens";
@@ -0,0 +1,13 @@
+CREATE TABLE "password_reset_tokens" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" INTEGER NOT NULL,
+    "token_hash" VARCHAR NOT NULL UNIQUE,
+    "expires_at" TIMESTAMP NOT NULL,
+    "used_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX "password_reset_tokens_user_id_idx" ON "password_reset_tokens" ("user_id");
+
+ALTER TABLE users
+    ADD COLUMN password_changed_at TIMESTAMP;
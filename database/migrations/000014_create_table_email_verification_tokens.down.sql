@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "email_verification_tokens";
+
+ALTER TABLE users
+    DROP COLUMN email_verified_at;
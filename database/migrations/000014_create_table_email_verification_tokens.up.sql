@@ -0,0 +1,16 @@
+ALTER TABLE users
+    ADD COLUMN email_verified_at TIMESTAMP;
+
+-- users registered before verification existed are considered verified.
+UPDATE users SET email_verified_at = now();
+
+CREATE TABLE "email_verification_tokens" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" INTEGER NOT NULL,
+    "token_hash" VARCHAR NOT NULL UNIQUE,
+    "expires_at" TIMESTAMP NOT NULL,
+    "used_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX "email_verification_tokens_user_id_idx" ON "email_verification_tokens" ("user_id");
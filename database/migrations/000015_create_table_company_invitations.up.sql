@@ -0,0 +1,15 @@
+CREATE TABLE "company_invitations" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "company_id" INTEGER NOT NULL,
+    "email" VARCHAR,
+    "phone" VARCHAR,
+    "role" VARCHAR NOT NULL,
+    "token_hash" VARCHAR NOT NULL UNIQUE,
+    "invited_by" INTEGER NOT NULL,
+    "expires_at" TIMESTAMP NOT NULL,
+    "accepted_at" TIMESTAMP,
+    "revoked_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX "company_invitations_company_id_idx" ON "company_invitations" ("company_id");
@@ -0,0 +1,6 @@
+ALTER TABLE event_user_organizers
+    DROP CONSTRAINT event_user_organizers_pkey,
+    DROP COLUMN created_at,
+    DROP COLUMN permissions,
+    ALTER COLUMN event_id DROP NOT NULL,
+    ALTER COLUMN user_id DROP NOT NULL;
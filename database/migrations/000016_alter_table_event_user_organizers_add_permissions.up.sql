@@ -0,0 +1,10 @@
+DELETE FROM event_user_organizers
+WHERE user_id IS NULL
+    OR event_id IS NULL;
+
+ALTER TABLE event_user_organizers
+    ALTER COLUMN user_id SET NOT NULL,
+    ALTER COLUMN event_id SET NOT NULL,
+    ADD COLUMN permissions TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN created_at TIMESTAMP DEFAULT (now()),
+    ADD CONSTRAINT event_user_organizers_pkey PRIMARY KEY (event_id, user_id);
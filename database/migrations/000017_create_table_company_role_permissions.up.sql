@@ -0,0 +1,7 @@
+CREATE TABLE "company_role_permissions" (
+    "company_id" INTEGER NOT NULL,
+    "role" VARCHAR NOT NULL,
+    "permissions" TEXT[] NOT NULL DEFAULT '{}',
+    "updated_at" TIMESTAMP DEFAULT (now()),
+    PRIMARY KEY ("company_id", "role")
+);
@@ -0,0 +1,15 @@
+CREATE TABLE "api_keys" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "company_id" INTEGER NOT NULL,
+    "name" VARCHAR NOT NULL,
+    "prefix" VARCHAR NOT NULL UNIQUE,
+    "key_hash" VARCHAR NOT NULL UNIQUE,
+    "scopes" TEXT[] NOT NULL DEFAULT '{}',
+    "created_by" INTEGER NOT NULL,
+    "expires_at" TIMESTAMP,
+    "last_used_at" TIMESTAMP,
+    "revoked_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX "api_keys_company_id_idx" ON "api_keys" ("company_id");
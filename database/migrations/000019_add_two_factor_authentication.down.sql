@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "two_factor_challenges";
+DROP TABLE IF EXISTS "two_factor_recovery_codes";
+
+ALTER TABLE companies
+    DROP COLUMN require_admin_two_factor;
+
+ALTER TABLE users
+    DROP COLUMN two_factor_enabled_at,
+    DROP COLUMN two_factor_secret;
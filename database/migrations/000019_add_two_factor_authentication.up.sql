@@ -0,0 +1,27 @@
+ALTER TABLE users
+    ADD COLUMN two_factor_secret VARCHAR,
+    ADD COLUMN two_factor_enabled_at TIMESTAMP;
+
+ALTER TABLE companies
+    ADD COLUMN require_admin_two_factor BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE "two_factor_recovery_codes" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" INTEGER NOT NULL,
+    "code_hash" VARCHAR NOT NULL,
+    "used_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE INDEX "two_factor_recovery_codes_user_id_idx" ON "two_factor_recovery_codes" ("user_id");
+
+CREATE TABLE "two_factor_challenges" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" INTEGER NOT NULL,
+    "token_hash" VARCHAR NOT NULL UNIQUE,
+    "remember" BOOLEAN NOT NULL DEFAULT false,
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "expires_at" TIMESTAMP NOT NULL,
+    "used_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (now())
+);
@@ -0,0 +1,6 @@
+CREATE TABLE "sign_in_attempts" (
+    "key" VARCHAR PRIMARY KEY,
+    "failures" INTEGER NOT NULL DEFAULT 0,
+    "last_failed_at" TIMESTAMP NOT NULL,
+    "locked_until" TIMESTAMP
+);
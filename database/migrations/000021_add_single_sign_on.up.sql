@@ -0,0 +1,32 @@
+CREATE TABLE "company_sso_configs" (
+    "company_id" INTEGER PRIMARY KEY,
+    "issuer" VARCHAR NOT NULL,
+    "client_id" VARCHAR NOT NULL,
+    "client_secret" VARCHAR NOT NULL DEFAULT '',
+    "default_role" VARCHAR NOT NULL,
+    "enabled" BOOLEAN NOT NULL DEFAULT true,
+    "created_at" TIMESTAMP DEFAULT (now()),
+    "updated_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE TABLE "sso_login_states" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "company_id" INTEGER NOT NULL,
+    "state_hash" VARCHAR NOT NULL UNIQUE,
+    "code_verifier" VARCHAR NOT NULL,
+    "nonce" VARCHAR NOT NULL,
+    "expires_at" TIMESTAMP NOT NULL,
+    "used_at" TIMESTAMP,
+    "created_at" TIMESTAMP DEFAULT (now())
+);
+
+CREATE TABLE "user_identities" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" INTEGER NOT NULL,
+    "issuer" VARCHAR NOT NULL,
+    "subject" VARCHAR NOT NULL,
+    "created_at" TIMESTAMP DEFAULT (now()),
+    UNIQUE ("issuer", "subject")
+);
+
+CREATE INDEX "user_identities_user_id_idx" ON "user_identities" ("user_id");
@@ -0,0 +1,18 @@
+CREATE TABLE "audit_logs" (
+    "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "company_id" INTEGER,
+    "actor_id" INTEGER,
+    "api_key_id" INTEGER,
+    "action" VARCHAR NOT NULL,
+    "target_type" VARCHAR NOT NULL,
+    "target_id" VARCHAR NOT NULL DEFAULT '',
+    "before" JSONB,
+    "after" JSONB,
+    "ip" VARCHAR NOT NULL DEFAULT '',
+    "request_id" VARCHAR NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX "audit_logs_company_id_created_at_idx" ON "audit_logs" ("company_id", "created_at" DESC);
+CREATE INDEX "audit_logs_company_id_target_idx" ON "audit_logs" ("company_id", "target_type", "target_id");
+CREATE INDEX "audit_logs_actor_id_idx" ON "audit_logs" ("actor_id");
@@ -0,0 +1,4 @@
+ALTER TABLE refresh_tokens
+    DROP COLUMN company_id;
+
+DROP TABLE IF EXISTS "company_memberships";
@@ -0,0 +1,19 @@
+CREATE TABLE "company_memberships" (
+    "user_id" INTEGER NOT NULL,
+    "company_id" INTEGER NOT NULL,
+    "role" VARCHAR NOT NULL,
+    "created_at" TIMESTAMP DEFAULT (now()),
+    "updated_at" TIMESTAMP DEFAULT (now()),
+    PRIMARY KEY ("user_id", "company_id")
+);
+
+CREATE INDEX "company_memberships_company_id_idx" ON "company_memberships" ("company_id");
+
+INSERT INTO company_memberships (user_id, company_id, role, created_at)
+SELECT id, company_id, role, created_at
+FROM users
+WHERE company_id IS NOT NULL
+    AND deleted_at IS NULL;
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN company_id INTEGER;
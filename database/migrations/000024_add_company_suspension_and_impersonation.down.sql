@@ -0,0 +1,6 @@
+ALTER TABLE audit_logs
+    DROP COLUMN impersonator_id;
+
+ALTER TABLE companies
+    DROP COLUMN suspension_reason,
+    DROP COLUMN suspended_at;
@@ -0,0 +1,6 @@
+ALTER TABLE companies
+    ADD COLUMN suspended_at TIMESTAMP,
+    ADD COLUMN suspension_reason VARCHAR;
+
+ALTER TABLE audit_logs
+    ADD COLUMN impersonator_id INTEGER;
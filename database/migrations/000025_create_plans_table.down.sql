@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "company_message_usage";
+
+ALTER TABLE companies
+    DROP COLUMN plan_code;
+
+DROP TABLE IF EXISTS "plans";
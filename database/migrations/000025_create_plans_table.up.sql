@@ -0,0 +1,26 @@
+CREATE TABLE "plans" (
+    "code" VARCHAR PRIMARY KEY,
+    "name" VARCHAR NOT NULL,
+    "max_active_events" INTEGER,
+    "max_guests_per_event" INTEGER,
+    "max_messages_per_month" INTEGER,
+    "created_at" TIMESTAMP DEFAULT (now()),
+    "updated_at" TIMESTAMP DEFAULT (now())
+);
+
+-- a NULL limit is unlimited.
+INSERT INTO plans (code, name, max_active_events, max_guests_per_event, max_messages_per_month)
+VALUES
+    ('free', 'Free', 1, 100, 100),
+    ('pro', 'Pro', 10, 1000, 5000),
+    ('enterprise', 'Enterprise', NULL, NULL, NULL);
+
+ALTER TABLE companies
+    ADD COLUMN plan_code VARCHAR NOT NULL DEFAULT 'free' REFERENCES plans (code);
+
+CREATE TABLE "company_message_usage" (
+    "company_id" INTEGER NOT NULL,
+    "month" DATE NOT NULL,
+    "message_count" INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY ("company_id", "month")
+);
@@ -0,0 +1,2 @@
+ALTER TABLE guests
+    ADD COLUMN anonymised_at TIMESTAMP;
@@ -0,0 +1,14 @@
+CREATE TABLE "user_sessions" (
+    "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    "user_id" INTEGER NOT NULL,
+    "company_id" INTEGER,
+    "family_id" VARCHAR NOT NULL UNIQUE,
+    "device" VARCHAR NOT NULL DEFAULT '',
+    "ip" VARCHAR NOT NULL DEFAULT '',
+    "user_agent" VARCHAR NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP NOT NULL DEFAULT (now()),
+    "last_seen_at" TIMESTAMP NOT NULL DEFAULT (now()),
+    "revoked_at" TIMESTAMP
+);
+
+CREATE INDEX "user_sessions_user_id_idx" ON "user_sessions" ("user_id");
@@ -0,0 +1,5 @@
+ALTER TABLE guests
+    DROP COLUMN version;
+
+ALTER TABLE events
+    DROP COLUMN version;
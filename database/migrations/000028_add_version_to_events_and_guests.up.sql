@@ -0,0 +1,5 @@
+ALTER TABLE events
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE guests
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
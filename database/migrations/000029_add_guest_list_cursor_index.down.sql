@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "guests_event_id_is_vip_id_idx";
@@ -0,0 +1 @@
+CREATE INDEX "guests_event_id_is_vip_id_idx" ON "guests" ("event_id", "is_vip" DESC, "id");
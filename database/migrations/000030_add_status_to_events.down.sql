@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "events_company_id_status_idx";
+
+ALTER TABLE events
+    DROP COLUMN status;
@@ -0,0 +1,13 @@
+ALTER TABLE events
+    ADD COLUMN status VARCHAR NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'published', 'ongoing', 'completed', 'cancelled', 'archived'));
+
+-- existing events were all open to their guests, they take the status matching their dates.
+UPDATE events
+    SET status = CASE
+        WHEN end_time < now() THEN 'completed'
+        WHEN start_time <= now() THEN 'ongoing'
+        ELSE 'published'
+    END;
+
+CREATE INDEX "events_company_id_status_idx" ON "events" ("company_id", "status");
@@ -0,0 +1,2 @@
+ALTER TABLE user_sessions
+    DROP COLUMN company_locked;
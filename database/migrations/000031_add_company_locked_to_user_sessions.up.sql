@@ -0,0 +1,3 @@
+-- sessions started with single sign-on are limited to the company of the identity provider.
+ALTER TABLE user_sessions
+    ADD COLUMN company_locked BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+ALTER TABLE two_factor_challenges
+    DROP COLUMN sso_company_id;
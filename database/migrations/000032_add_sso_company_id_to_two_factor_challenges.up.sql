@@ -0,0 +1,3 @@
+-- challenges of a single sign-on complete into a session locked to the company of the identity provider.
+ALTER TABLE two_factor_challenges
+    ADD COLUMN sso_company_id INTEGER;
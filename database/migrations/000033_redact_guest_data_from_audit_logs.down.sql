@@ -0,0 +1,2 @@
+-- the redacted personal data can not be restored.
+SELECT 1;
@@ -0,0 +1,5 @@
+-- guest audit logs used to keep the personal data of the guests, which then survived its erasure.
+UPDATE audit_logs
+    SET after = after - 'name' - 'email' - 'phone' - 'message'
+WHERE action IN ('guest.register', 'guest.update')
+    AND after IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE events
+    DROP COLUMN guest_list_version;
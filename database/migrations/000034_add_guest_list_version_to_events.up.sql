@@ -0,0 +1,3 @@
+-- bumped whenever a guest of the event is added, changed or deleted, it is the ETag of the guest list.
+ALTER TABLE events
+    ADD COLUMN guest_list_version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- the removed message:send grants can not be told apart once removed.
+SELECT 1;
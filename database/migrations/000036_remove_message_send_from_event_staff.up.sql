@@ -0,0 +1,4 @@
+-- message:send can no longer be granted on a single event.
+UPDATE event_user_organizers
+    SET permissions = array_remove(permissions, 'message:send')
+WHERE 'message:send' = ANY(permissions);
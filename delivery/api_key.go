@@ -0,0 +1,137 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+// APIKeyService defines the service interface for managing the API keys of a company.
+type APIKeyService interface {
+	CreateAPIKey(
+		ctx context.Context,
+		companyID, createdBy int,
+		creatorRole entity.UserRole,
+		name string,
+		scopes []entity.Permission,
+		expiresAt *time.Time,
+	) (*entity.APIKey, string, error)
+	ListAPIKeys(ctx context.Context, companyID int) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, companyID, apiKeyID int) error
+}
+
+// APIKeyHandler handles HTTP requests related to API keys.
+type APIKeyHandler struct {
+	apiKeyService APIKeyService
+}
+
+// NewAPIKeyHandler creates a new instance of APIKeyHandler.
+func NewAPIKeyHandler(apiKeyService APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// RegisterAPIKeyRoutes registers the API key related routes within the Echo router group.
+func (h *APIKeyHandler) RegisterAPIKeyRoutes(e *echo.Group, middleware *Middleware) {
+	apiKeyManagers := []entity.Permission{entity.PermissionAPIKeyManage}
+
+	e.GET("", middleware.PermissionMiddleware(apiKeyManagers, h.handleGetAPIKeys))
+	e.POST("", middleware.PermissionMiddleware(apiKeyManagers, h.handleCreateAPIKey))
+	e.DELETE("/:id", middleware.PermissionMiddleware(apiKeyManagers, h.handleRevokeAPIKey))
+}
+
+// handleCreateAPIKey creates an API key for the caller's company.
+//
+//	@Summary		Create an API key
+//	@Description	Creates an API key scoped to the caller's company. The key is only returned once.
+//	@Tags			api-keys
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		CreateAPIKeyRequest	true	"API key payload"
+//	@Success		201		{object}	Response{data=CreateAPIKeyResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		403		{object}	Response	"Forbidden"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/api-keys [post]
+func (h *APIKeyHandler) handleCreateAPIKey(c echo.Context) error {
+	var request CreateAPIKeyRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	apiKey, key, err := h.apiKeyService.CreateAPIKey(
+		c.Request().Context(),
+		c.Get("company_id").(int),
+		c.Get("user_id").(int),
+		c.Get("user_role").(entity.UserRole),
+		request.Name,
+		request.Scopes,
+		request.ExpiresAt,
+	)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, Response{
+		StatusCode: http.StatusCreated,
+		Message:    fmt.Sprintf("api key %s created", apiKey.Name),
+		Data:       CreateAPIKeyResponse{APIKey: *apiKey, Key: key},
+		Error:      nil,
+	})
+}
+
+// handleGetAPIKeys lists the API keys of the caller's company.
+//
+//	@Summary		List API keys
+//	@Description	Lists the API keys of the caller's company that have not been revoked.
+//	@Tags			api-keys
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	Response{data=[]entity.APIKey}
+//	@Failure		403	{object}	Response	"Forbidden"
+//	@Failure		500	{object}	Response	"Internal Server Error"
+//	@Router			/api-keys [get]
+func (h *APIKeyHandler) handleGetAPIKeys(c echo.Context) error {
+	apiKeys, err := h.apiKeyService.ListAPIKeys(c.Request().Context(), c.Get("company_id").(int))
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       apiKeys,
+		Error:      nil,
+	})
+}
+
+// handleRevokeAPIKey revokes an API key of the caller's company.
+//
+//	@Summary		Revoke an API key
+//	@Description	Revokes an API key of the caller's company, requests using it are refused right away.
+//	@Tags			api-keys
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"API key ID"
+//	@Success		200	{object}	Response
+//	@Failure		403	{object}	Response	"Forbidden"
+//	@Failure		404	{object}	Response	"API Key Not Found"
+//	@Failure		500	{object}	Response	"Internal Server Error"
+//	@Router			/api-keys/{id} [delete]
+func (h *APIKeyHandler) handleRevokeAPIKey(c echo.Context) error {
+	apiKeyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid api key id"})
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request().Context(), c.Get("company_id").(int), apiKeyID); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "api key revoked"})
+}
@@ -0,0 +1,22 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+// CreateAPIKeyRequest represents the payload required to create an API key.
+// ExpiresAt is optional, keys without an expiry time are valid until revoked.
+type CreateAPIKeyRequest struct {
+	Name      string              `json:"name"`
+	Scopes    []entity.Permission `json:"scopes"`
+	ExpiresAt *time.Time          `json:"expiresAt"`
+}
+
+// CreateAPIKeyResponse represents a newly created API key.
+// Key is only returned once, at creation.
+type CreateAPIKeyResponse struct {
+	entity.APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,142 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+const (
+	// defaultAuditLogsPerPage is the page size used when the request does not give one.
+	defaultAuditLogsPerPage = 50
+
+	// maxAuditLogsPerPage is the largest page size a request may ask for.
+	maxAuditLogsPerPage = 200
+)
+
+// AuditService defines the service interface for browsing the audit log of a company.
+type AuditService interface {
+	ListAuditLogs(ctx context.Context, filter entity.AuditLogFilter, request entity.PaginationRequest) (entity.PaginationResponse, error)
+}
+
+// AuditHandler handles HTTP requests related to the audit log.
+type AuditHandler struct {
+	auditService AuditService
+}
+
+// NewAuditHandler creates a new instance of AuditHandler.
+func NewAuditHandler(auditService AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// RegisterAuditRoutes registers the audit log related routes within the Echo router group.
+func (h *AuditHandler) RegisterAuditRoutes(e *echo.Group, middleware *Middleware) {
+	e.GET("", middleware.PermissionMiddleware([]entity.Permission{entity.PermissionAuditView}, h.handleGetAuditLogs))
+}
+
+// handleGetAuditLogs lists the audit logs of the caller's company.
+//
+//	@Summary		List audit logs
+//	@Description	Lists the state-changing actions recorded for the caller's company, newest first.
+//	@Tags			audit
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			actorId		query		int		false	"Only actions performed by this user"
+//	@Param			action		query		string	false	"Only this action, such as event.update"
+//	@Param			targetType	query		string	false	"Only actions on this kind of entity, such as event"
+//	@Param			targetId	query		string	false	"Only actions on this entity, requires targetType"
+//	@Param			from		query		string	false	"Only actions performed at or after this time (RFC3339)"
+//	@Param			to			query		string	false	"Only actions performed before this time (RFC3339)"
+//	@Param			page		query		int		false	"Page number, starts at 1"
+//	@Param			perPage		query		int		false	"Number of audit logs per page, at most 200"
+//	@Success		200			{object}	Response{data=entity.PaginationResponse{records=[]entity.AuditLog}}
+//	@Failure		400			{object}	Response	"Bad Request"
+//	@Failure		403			{object}	Response	"Forbidden"
+//	@Failure		500			{object}	Response	"Internal Server Error"
+//	@Router			/audit [get]
+func (h *AuditHandler) handleGetAuditLogs(c echo.Context) error {
+	filter := entity.AuditLogFilter{
+		CompanyID:  c.Get("company_id").(int),
+		Action:     entity.AuditAction(c.QueryParam("action")),
+		TargetType: entity.AuditTargetType(c.QueryParam("targetType")),
+		TargetID:   c.QueryParam("targetId"),
+	}
+
+	if filter.TargetID != "" && filter.TargetType == "" {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "query parameter 'targetId' requires 'targetType'"})
+	}
+
+	var err error
+	if actorID := c.QueryParam("actorId"); actorID != "" {
+		if filter.ActorID, err = strconv.Atoi(actorID); err != nil {
+			return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid query parameter 'actorId' value."})
+		}
+	}
+
+	if filter.From, err = parseTimeQueryParam(c, "from"); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid query parameter 'from' value, expected RFC3339."})
+	}
+
+	if filter.To, err = parseTimeQueryParam(c, "to"); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid query parameter 'to' value, expected RFC3339."})
+	}
+
+	paginationRequest, err := parsePaginationQueryParams(c, "perPage", defaultAuditLogsPerPage, maxAuditLogsPerPage)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: err.Error()})
+	}
+
+	auditLogs, err := h.auditService.ListAuditLogs(c.Request().Context(), filter, paginationRequest)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       auditLogs,
+		Error:      nil,
+	})
+}
+
+// parsePaginationQueryParams parses the optional "page" query parameter and the page size,
+// given in the perPageParam query parameter. The returned error is the message to answer with when either of them is invalid.
+func parsePaginationQueryParams(c echo.Context, perPageParam string, defaultPerPage, maxPerPage int) (entity.PaginationRequest, error) {
+	var err error
+
+	paginationRequest := entity.PaginationRequest{Page: 1, PerPage: defaultPerPage}
+	if page := c.QueryParam("page"); page != "" {
+		if paginationRequest.Page, err = strconv.Atoi(page); err != nil || paginationRequest.Page < 1 {
+			return paginationRequest, errors.New("invalid query parameter 'page' value.")
+		}
+	}
+
+	if perPage := c.QueryParam(perPageParam); perPage != "" {
+		if paginationRequest.PerPage, err = strconv.Atoi(perPage); err != nil || paginationRequest.PerPage < 1 || paginationRequest.PerPage > maxPerPage {
+			return paginationRequest, fmt.Errorf("invalid query parameter '%s' value.", perPageParam)
+		}
+	}
+
+	return paginationRequest, nil
+}
+
+// parseTimeQueryParam parses an optional RFC3339 query parameter, nil is returned when it is not given.
+func parseTimeQueryParam(c echo.Context, name string) (*time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/AlekSi/pointer"
 	"github.com/labstack/echo/v4"
@@ -14,10 +15,27 @@ import (
 // AuthService defines authentication-related operations.
 type AuthService interface {
 	RegisterNewUser(ctx context.Context, user entity.User, companyName string) (createdUser *entity.User, company *entity.Company, err error)
-	GenerateAccessToken(ctx context.Context, userID int, companyID int, userEmail string, userRole entity.UserRole) (authResponse *entity.AuthResponse, err error)
-	UserSignIn(ctx context.Context, email, password string, remember bool) (user *entity.User, company *entity.Company, accessToken string, err error)
+	GenerateAccessToken(ctx context.Context, userID int, companyID int, userEmail string, userRole entity.UserRole, remember bool) (authResponse *entity.AuthResponse, err error)
+	UserSignIn(ctx context.Context, email, password, clientIP string, remember bool) (user *entity.User, company *entity.Company, authResponse *entity.AuthResponse, err error)
+	RefreshAccessToken(ctx context.Context, refreshToken string) (authResponse *entity.AuthResponse, err error)
+	SignOut(ctx context.Context, refreshToken string) (err error)
+	RequestPasswordReset(ctx context.Context, email string) (err error)
+	ResetPassword(ctx context.Context, resetToken, newPassword string) (err error)
+	SendEmailVerification(ctx context.Context, userID int) (err error)
+	VerifyEmail(ctx context.Context, verificationToken string) (err error)
 	GetUserByID(ctx context.Context, userID int) (targetUser *entity.User, err error)
-	GetCompanyByID(ctx context.Context, ID int) (company *entity.Company, err error)
+	UpdateProfile(ctx context.Context, userID int, profile entity.User) (*entity.User, error)
+	ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string) (authResponse *entity.AuthResponse, err error)
+	ListCompanies(ctx context.Context, userID int) ([]entity.CompanyMembership, error)
+	SwitchCompany(ctx context.Context, userID, sessionID, companyID int) (user *entity.User, company *entity.Company, authResponse *entity.AuthResponse, err error)
+	SetupTwoFactor(ctx context.Context, userID int) (*entity.TwoFactorSetup, error)
+	EnableTwoFactor(ctx context.Context, userID int, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, userID int, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error)
+	CompleteTwoFactorSignIn(ctx context.Context, challengeToken, code, clientIP string) (user *entity.User, company *entity.Company, authResponse *entity.AuthResponse, err error)
+	ListSessions(ctx context.Context, userID int) ([]entity.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID int) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID int) (int, error)
 }
 
 // AuthHandler handles authentication-related HTTP requests.
@@ -34,8 +52,28 @@ func NewAuthHandler(authService AuthService) *AuthHandler {
 func (h *AuthHandler) RegisterAuthRoutes(e *echo.Group, middleware *Middleware) {
 	e.POST("", h.HandleSignIn)
 	e.POST("/signup", h.HandleSignUp)
+	e.POST("/refresh", h.HandleRefreshToken)
+	e.POST("/logout", h.HandleSignOut)
+	e.POST("/password/forgot", h.HandleForgotPassword)
+	e.POST("/password/reset", h.HandleResetPassword)
+	e.POST("/email/verify", h.HandleVerifyEmail)
+	e.POST("/email/resend", middleware.UnverifiedAuthMiddleware(AllowedAuthenticatedOnly, h.HandleResendEmailVerification))
+	e.POST("/2fa/verify", h.HandleVerifyTwoFactor)
+	// operators impersonating a user can not change their credentials or open sessions on their behalf.
+	e.POST("/2fa/setup", middleware.UnverifiedAuthMiddleware(AllowedAuthenticatedOnly, middleware.NoImpersonationMiddleware(h.HandleSetupTwoFactor)))
+	e.POST("/2fa/enable", middleware.UnverifiedAuthMiddleware(AllowedAuthenticatedOnly, middleware.NoImpersonationMiddleware(h.HandleEnableTwoFactor)))
+	e.POST("/2fa/disable", middleware.AuthMiddleware(AllowedAuthenticatedOnly, middleware.NoImpersonationMiddleware(h.HandleDisableTwoFactor)))
+	e.POST("/2fa/recovery-codes", middleware.AuthMiddleware(AllowedAuthenticatedOnly, middleware.NoImpersonationMiddleware(h.HandleRegenerateRecoveryCodes)))
 	e.GET("/profile", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.HandleProfile))
-	e.GET("/companies", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleGetCompany))
+	e.PUT("/profile", middleware.AuthMiddleware(AllowedAuthenticatedOnly, middleware.NoImpersonationMiddleware(h.HandleUpdateProfile)))
+	e.POST("/password/change", middleware.AuthMiddleware(AllowedAuthenticatedOnly, middleware.NoImpersonationMiddleware(h.HandleChangePassword)))
+	// listing and switching companies is allowed before completing the requirements of the current company.
+	e.GET("/companies", middleware.UnverifiedAuthMiddleware(AllowedAuthenticatedOnly, h.handleGetCompanies))
+	e.POST("/companies/switch", middleware.UnverifiedAuthMiddleware(AllowedAuthenticatedOnly, middleware.NoImpersonationMiddleware(h.handleSwitchCompany)))
+	// users can sign out a stolen device before completing the requirements of their company.
+	e.GET("/sessions", middleware.UnverifiedAuthMiddleware(AllowedAuthenticatedOnly, h.handleGetSessions))
+	e.DELETE("/sessions", middleware.UnverifiedAuthMiddleware(AllowedAuthenticatedOnly, middleware.NoImpersonationMiddleware(h.handleRevokeOtherSessions)))
+	e.DELETE("/sessions/:id", middleware.UnverifiedAuthMiddleware(AllowedAuthenticatedOnly, middleware.NoImpersonationMiddleware(h.handleRevokeSession)))
 }
 
 // HandleProfile godoc
@@ -64,6 +102,89 @@ func (h *AuthHandler) HandleProfile(c echo.Context) error {
 	})
 }
 
+// HandleUpdateProfile godoc
+//
+//	@Summary		Update logged user's profile
+//	@Description	Replaces the name, phone number and job title of the logged user. Empty optional fields are cleared.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		UpdateProfileRequest	true	"New profile"
+//	@Success		200		{object}	Response{data=ProfileResponse}
+//	@Failure		400		{object}	Response	"Invalid name or phone number"
+//	@Failure		500		{object}	Response	"Internal server error"
+//	@Router			/api/v1/auth/profile [put]
+func (h *AuthHandler) HandleUpdateProfile(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleUpdateProfile"
+	var requestBody UpdateProfileRequest
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	user, err := h.authService.UpdateProfile(ctx, c.Get("user_id").(int), entity.User{
+		FirstName:   requestBody.FirstName,
+		LastName:    requestBody.LastName,
+		PhoneNumber: requestBody.PhoneNumber,
+		JobTitle:    requestBody.JobTitle,
+	})
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "profile updated",
+		Data:       ProfileResponseFromEntity(user),
+		Error:      nil,
+	})
+}
+
+// HandleChangePassword godoc
+//
+//	@Summary		Change logged user's password
+//	@Description	Replaces the password of the logged user, who has to give their current password.
+//	@Description	Every other session is signed out, the returned tokens replace the ones of the caller.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		ChangePasswordRequest	true	"Current and new password"
+//	@Success		200		{object}	Response{data=RefreshTokenResponse}
+//	@Failure		400		{object}	Response	"Invalid current or new password"
+//	@Failure		500		{object}	Response	"Internal server error"
+//	@Router			/api/v1/auth/password/change [post]
+func (h *AuthHandler) HandleChangePassword(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleChangePassword"
+	var requestBody ChangePasswordRequest
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	authResponse, err := h.authService.ChangePassword(ctx, c.Get("user_id").(int), requestBody.CurrentPassword, requestBody.NewPassword)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "password updated",
+		Data: RefreshTokenResponse{
+			AccessToken:           authResponse.AccessToken,
+			ExpiresAt:             authResponse.ExpiresAt,
+			RefreshToken:          authResponse.RefreshToken,
+			RefreshTokenExpiresAt: authResponse.RefreshTokenExpiresAt,
+		},
+		Error: nil,
+	})
+}
+
 // HandleSignUp godoc
 //
 //	@Summary	Register a new user
@@ -98,6 +219,10 @@ func (h *AuthHandler) HandleSignUp(c echo.Context) error {
 		Role:      entity.UserRole(requestBody.Role),
 	}
 
+	if requestBody.PhoneNumber != "" {
+		toCreateUser.PhoneNumber = &requestBody.PhoneNumber
+	}
+
 	newlyCreatedUser, company, serviceErr := h.authService.RegisterNewUser(ctx, toCreateUser, requestBody.CompanyName)
 	if serviceErr != nil {
 		switch err := serviceErr.(type) {
@@ -120,7 +245,7 @@ func (h *AuthHandler) HandleSignUp(c echo.Context) error {
 		companyResponse = CompanyResponseFromEntity(pointer.Get(company))
 	}
 
-	authResponse, err := h.authService.GenerateAccessToken(ctx, newlyCreatedUser.ID, pointer.GetInt(newlyCreatedUser.CompanyID), newlyCreatedUser.Email, newlyCreatedUser.Role)
+	authResponse, err := h.authService.GenerateAccessToken(ctx, newlyCreatedUser.ID, pointer.GetInt(newlyCreatedUser.CompanyID), newlyCreatedUser.Email, newlyCreatedUser.Role, false)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
 	}
@@ -129,7 +254,10 @@ func (h *AuthHandler) HandleSignUp(c echo.Context) error {
 		StatusCode: http.StatusCreated,
 		Message:    fmt.Sprintf("user %s created", requestBody.Email),
 		Data: AccessTokenResponse{
-			AccessToken: authResponse.AccessToken,
+			AccessToken:           authResponse.AccessToken,
+			ExpiresAt:             authResponse.ExpiresAt,
+			RefreshToken:          authResponse.RefreshToken,
+			RefreshTokenExpiresAt: authResponse.RefreshTokenExpiresAt,
 			User: UserResponse{
 				ID:       newlyCreatedUser.ID,
 				Name:     newlyCreatedUser.GetName(),
@@ -152,6 +280,7 @@ func (h *AuthHandler) HandleSignUp(c echo.Context) error {
 //	@Param		request	body		SignInRequest						true	"User sign-in credentials"
 //	@Success	200		{object}	Response{data=AccessTokenResponse}	"User successfully authenticated"
 //	@Failure	400		{object}	Response							"Invalid credentials or bad request"
+//	@Failure	429		{object}	Response							"Too many failed attempts"
 //	@Failure	500		{object}	Response							"Internal server error"
 //	@Router		/api/v1/auth [post]
 func (h *AuthHandler) HandleSignIn(c echo.Context) error {
@@ -169,11 +298,20 @@ func (h *AuthHandler) HandleSignIn(c echo.Context) error {
 		})
 	}
 
-	user, company, accessToken, serviceErr := h.authService.UserSignIn(ctx, requestBody.Email, requestBody.Password, requestBody.Remember)
+	user, company, authResponse, serviceErr := h.authService.UserSignIn(ctx, requestBody.Email, requestBody.Password, c.RealIP(), requestBody.Remember)
 	if serviceErr != nil {
 		logger.Errorf(ctx, ops, "user sign in fails: %v", serviceErr)
 		switch err := serviceErr.(type) {
 		case entity.GosmError:
+			if err.Type == entity.GosmErrorTypeTooMany {
+				return c.JSON(http.StatusTooManyRequests, Response{
+					StatusCode: http.StatusTooManyRequests,
+					Message:    err.Message,
+					Data:       err.Code,
+					Error:      err.Source,
+				})
+			}
+
 			if err.Type == entity.GosmErrorTypeBadRequest {
 				return c.JSON(http.StatusBadRequest, Response{
 					StatusCode: http.StatusBadRequest,
@@ -196,7 +334,13 @@ func (h *AuthHandler) HandleSignIn(c echo.Context) error {
 		StatusCode: http.StatusOK,
 		Message:    "success",
 		Data: AccessTokenResponse{
-			AccessToken: accessToken,
+			AccessToken:           authResponse.AccessToken,
+			ExpiresAt:             authResponse.ExpiresAt,
+			RefreshToken:          authResponse.RefreshToken,
+			RefreshTokenExpiresAt: authResponse.RefreshTokenExpiresAt,
+			TwoFactorRequired:     authResponse.TwoFactorRequired,
+			ChallengeToken:        authResponse.ChallengeToken,
+			ChallengeExpiresAt:    authResponse.ChallengeExpiresAt,
 			User: UserResponse{
 				ID:       user.ID,
 				Name:     user.GetName(),
@@ -210,21 +354,422 @@ func (h *AuthHandler) HandleSignIn(c echo.Context) error {
 	})
 }
 
-func (h *AuthHandler) handleGetCompany(c echo.Context) error {
+// HandleRefreshToken godoc
+//
+//	@Summary	Exchange a refresh token for a new access token
+//	@Tags		auth
+//	@Accept		json
+//	@Produce	json
+//	@Param		request	body		RefreshTokenRequest					true	"Refresh token"
+//	@Success	200		{object}	Response{data=RefreshTokenResponse}	"Access token refreshed"
+//	@Failure	401		{object}	Response							"Invalid or expired refresh token"
+//	@Failure	500		{object}	Response							"Internal server error"
+//	@Router		/api/v1/auth/refresh [post]
+func (h *AuthHandler) HandleRefreshToken(c echo.Context) error {
 	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleRefreshToken"
+	var requestBody RefreshTokenRequest
 
-	companyID := c.Get("company_id").(int)
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
 
-	company, err := h.authService.GetCompanyByID(ctx, companyID)
-	if err != nil {
+	authResponse, serviceErr := h.authService.RefreshAccessToken(ctx, requestBody.RefreshToken)
+	if serviceErr != nil {
+		switch err := serviceErr.(type) {
+		case entity.GosmError:
+			if err.Type == entity.GosmErrorTypeBadRequest {
+				return c.JSON(http.StatusUnauthorized, Response{
+					StatusCode: http.StatusUnauthorized,
+					Message:    err.Message,
+					Data:       err.Code,
+					Error:      err.Source,
+				})
+			}
+		}
+
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(serviceErr))
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "success",
+		Data: RefreshTokenResponse{
+			AccessToken:           authResponse.AccessToken,
+			ExpiresAt:             authResponse.ExpiresAt,
+			RefreshToken:          authResponse.RefreshToken,
+			RefreshTokenExpiresAt: authResponse.RefreshTokenExpiresAt,
+		},
+		Error: nil,
+	})
+}
+
+// HandleSignOut godoc
+//
+//	@Summary	Revoke the refresh token family of the current session
+//	@Tags		auth
+//	@Accept		json
+//	@Produce	json
+//	@Param		request	body		RefreshTokenRequest	true	"Refresh token"
+//	@Success	200		{object}	Response			"Signed out"
+//	@Failure	400		{object}	Response			"Invalid refresh token"
+//	@Failure	500		{object}	Response			"Internal server error"
+//	@Router		/api/v1/auth/logout [post]
+func (h *AuthHandler) HandleSignOut(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleSignOut"
+	var requestBody RefreshTokenRequest
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	if serviceErr := h.authService.SignOut(ctx, requestBody.RefreshToken); serviceErr != nil {
+		switch err := serviceErr.(type) {
+		case entity.GosmError:
+			if err.Type == entity.GosmErrorTypeBadRequest {
+				return c.JSON(http.StatusBadRequest, Response{
+					StatusCode: http.StatusBadRequest,
+					Message:    err.Message,
+					Data:       nil,
+					Error:      err.Source,
+				})
+			}
+		}
+
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(serviceErr))
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "signed out"})
+}
+
+// HandleForgotPassword godoc
+//
+//	@Summary		Request a password reset link
+//	@Description	Sends a single-use password reset link to the given email when it belongs to a registered user.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		ForgotPasswordRequest	true	"Account email"
+//	@Success		200		{object}	Response				"Reset link sent if the account exists"
+//	@Failure		400		{object}	Response				"Invalid email"
+//	@Failure		500		{object}	Response				"Internal server error"
+//	@Router			/api/v1/auth/password/forgot [post]
+func (h *AuthHandler) HandleForgotPassword(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleForgotPassword"
+	var requestBody ForgotPasswordRequest
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
 		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
 	}
 
+	if serviceErr := h.authService.RequestPasswordReset(ctx, requestBody.Email); serviceErr != nil {
+		switch err := serviceErr.(type) {
+		case entity.GosmError:
+			if err.Type == entity.GosmErrorTypeBadRequest {
+				return c.JSON(http.StatusBadRequest, Response{
+					StatusCode: http.StatusBadRequest,
+					Message:    err.Message,
+					Data:       nil,
+					Error:      err.Source,
+				})
+			}
+		}
+
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(serviceErr))
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "if the email is registered, a password reset link has been sent",
+	})
+}
+
+// HandleResetPassword godoc
+//
+//	@Summary		Reset password using a reset token
+//	@Description	Sets a new password using a token from the reset link and signs out every existing session.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		ResetPasswordRequest	true	"Reset token and new password"
+//	@Success		200		{object}	Response				"Password updated"
+//	@Failure		400		{object}	Response				"Invalid or expired token"
+//	@Failure		500		{object}	Response				"Internal server error"
+//	@Router			/api/v1/auth/password/reset [post]
+func (h *AuthHandler) HandleResetPassword(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleResetPassword"
+	var requestBody ResetPasswordRequest
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	if serviceErr := h.authService.ResetPassword(ctx, requestBody.Token, requestBody.Password); serviceErr != nil {
+		switch err := serviceErr.(type) {
+		case entity.GosmError:
+			if err.Type == entity.GosmErrorTypeBadRequest {
+				return c.JSON(http.StatusBadRequest, Response{
+					StatusCode: http.StatusBadRequest,
+					Message:    err.Message,
+					Data:       err.Code,
+					Error:      err.Source,
+				})
+			}
+		}
+
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(serviceErr))
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "password updated"})
+}
+
+// HandleVerifyEmail godoc
+//
+//	@Summary		Confirm an email address
+//	@Description	Marks the email address as verified using the token from the verification link.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		VerifyEmailRequest	true	"Verification token"
+//	@Success		200		{object}	Response			"Email verified"
+//	@Failure		400		{object}	Response			"Invalid or expired token"
+//	@Failure		500		{object}	Response			"Internal server error"
+//	@Router			/api/v1/auth/email/verify [post]
+func (h *AuthHandler) HandleVerifyEmail(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleVerifyEmail"
+	var requestBody VerifyEmailRequest
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	if serviceErr := h.authService.VerifyEmail(ctx, requestBody.Token); serviceErr != nil {
+		switch err := serviceErr.(type) {
+		case entity.GosmError:
+			if err.Type == entity.GosmErrorTypeBadRequest {
+				return c.JSON(http.StatusBadRequest, Response{
+					StatusCode: http.StatusBadRequest,
+					Message:    err.Message,
+					Data:       err.Code,
+					Error:      err.Source,
+				})
+			}
+		}
+
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(serviceErr))
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "email verified"})
+}
+
+// HandleResendEmailVerification godoc
+//
+//	@Summary	Resend the email verification link to the logged user
+//	@Tags		auth
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{object}	Response	"Verification link sent"
+//	@Failure	400	{object}	Response	"Email already verified"
+//	@Failure	500	{object}	Response	"Internal server error"
+//	@Router		/api/v1/auth/email/resend [post]
+func (h *AuthHandler) HandleResendEmailVerification(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("user_id").(int)
+
+	if serviceErr := h.authService.SendEmailVerification(ctx, userID); serviceErr != nil {
+		switch err := serviceErr.(type) {
+		case entity.GosmError:
+			if err.Type == entity.GosmErrorTypeBadRequest {
+				return c.JSON(http.StatusBadRequest, Response{
+					StatusCode: http.StatusBadRequest,
+					Message:    err.Message,
+					Data:       err.Code,
+					Error:      err.Source,
+				})
+			}
+		}
+
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(serviceErr))
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "verification link sent"})
+}
+
+// handleGetCompanies lists the companies the logged user belongs to.
+//
+//	@Summary	List the companies of the logged user
+//	@Tags		auth
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{object}	Response{data=[]CompanyMembershipResponse}
+//	@Failure	500	{object}	Response	"Internal server error"
+//	@Router		/api/v1/auth/companies [get]
+func (h *AuthHandler) handleGetCompanies(c echo.Context) error {
+	ctx := c.Request().Context()
+	currentCompanyID := c.Get("company_id").(int)
+
+	memberships, err := h.authService.ListCompanies(ctx, c.Get("user_id").(int))
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	response := make([]CompanyMembershipResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		response = append(response, CompanyMembershipResponseFromEntity(membership, currentCompanyID))
+	}
+
 	return c.JSON(http.StatusOK, Response{
 		StatusCode: http.StatusOK,
 		Message:    "ok",
-		Data:       CompanyResponseFromEntity(pointer.Get(company)),
+		Data:       response,
 		Error:      nil,
 	})
+}
+
+// handleSwitchCompany issues tokens for another company of the logged user.
+//
+//	@Summary		Switch to another company
+//	@Description	Issues new tokens for a company the logged user belongs to, with their role in that company.
+//	@Description	The company is also the one the user is signed in to next time.
+//	@Description	Sessions signed in with the single sign-on of a company can not switch to another company.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		SwitchCompanyRequest	true	"Company to switch to"
+//	@Success		200		{object}	Response{data=AccessTokenResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		404		{object}	Response	"Not a member of the company"
+//	@Failure		500		{object}	Response	"Internal server error"
+//	@Router			/api/v1/auth/companies/switch [post]
+func (h *AuthHandler) handleSwitchCompany(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.handleSwitchCompany"
+	var requestBody SwitchCompanyRequest
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	sessionID, _ := c.Get("session_id").(int)
+	user, company, authResponse, err := h.authService.SwitchCompany(ctx, c.Get("user_id").(int), sessionID, requestBody.CompanyID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	companyResponse := CompanyResponseFromEntity(pointer.Get(company))
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("switched to %s", company.Name),
+		Data: AccessTokenResponse{
+			AccessToken:           authResponse.AccessToken,
+			ExpiresAt:             authResponse.ExpiresAt,
+			RefreshToken:          authResponse.RefreshToken,
+			RefreshTokenExpiresAt: authResponse.RefreshTokenExpiresAt,
+			User: UserResponse{
+				ID:       user.ID,
+				Name:     user.GetName(),
+				Email:    user.Email,
+				Phone:    user.PhoneNumber,
+				JobTitle: user.JobTitle,
+				Role:     user.Role,
+			},
+			Company: &companyResponse,
+		},
+	})
+}
 
+// handleGetSessions lists the active sessions of the logged user.
+//
+//	@Summary		List the sessions of the logged user
+//	@Description	Lists the devices the logged user is signed in on, most recently seen first.
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	Response{data=[]SessionResponse}
+//	@Failure		500	{object}	Response	"Internal server error"
+//	@Router			/api/v1/auth/sessions [get]
+func (h *AuthHandler) handleGetSessions(c echo.Context) error {
+	ctx := c.Request().Context()
+	currentSessionID, _ := c.Get("session_id").(int)
+
+	sessions, err := h.authService.ListSessions(ctx, c.Get("user_id").(int))
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, SessionResponseFromEntity(session, currentSessionID))
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       response,
+		Error:      nil,
+	})
+}
+
+// handleRevokeSession signs out a session of the logged user.
+//
+//	@Summary		Revoke a session of the logged user
+//	@Description	Signs out a session of the logged user, its access and refresh tokens are refused right away.
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"Session ID"
+//	@Success		200	{object}	Response
+//	@Failure		400	{object}	Response	"Bad Request"
+//	@Failure		404	{object}	Response	"Session Not Found"
+//	@Failure		500	{object}	Response	"Internal server error"
+//	@Router			/api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) handleRevokeSession(c echo.Context) error {
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid session id"})
+	}
+
+	if err := h.authService.RevokeSession(c.Request().Context(), c.Get("user_id").(int), sessionID); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "session revoked"})
+}
+
+// handleRevokeOtherSessions signs out every session of the logged user but the current one.
+//
+//	@Summary		Revoke the other sessions of the logged user
+//	@Description	Signs out every session of the logged user but the one of the access token used by the request.
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	Response{data=RevokeSessionsResponse}
+//	@Failure		500	{object}	Response	"Internal server error"
+//	@Router			/api/v1/auth/sessions [delete]
+func (h *AuthHandler) handleRevokeOtherSessions(c echo.Context) error {
+	currentSessionID, _ := c.Get("session_id").(int)
+
+	revoked, err := h.authService.RevokeOtherSessions(c.Request().Context(), c.Get("user_id").(int), currentSessionID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("%d sessions revoked", revoked),
+		Data:       RevokeSessionsResponse{RevokedSessions: revoked},
+		Error:      nil,
+	})
 }
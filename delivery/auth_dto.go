@@ -1,6 +1,8 @@
 package delivery
 
 import (
+	"time"
+
 	"github.com/AlekSi/pointer"
 	"github.com/mhdiiilham/gosm/entity"
 )
@@ -23,11 +25,90 @@ type SignInRequest struct {
 	Remember bool   `json:"remember"`
 }
 
+// RefreshTokenRequest represents the payload carrying a refresh token, used to refresh or revoke a session.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ForgotPasswordRequest represents the payload required to request a password reset link.
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest represents the payload required to set a new password using a reset token.
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// UpdateProfileRequest represents the payload required to update the profile of the logged user.
+// Empty optional fields are cleared, the phone number has to start with its country code.
+type UpdateProfileRequest struct {
+	FirstName   string  `json:"first_name"`
+	LastName    *string `json:"last_name"`
+	PhoneNumber *string `json:"phone_number"`
+	JobTitle    *string `json:"job_title"`
+}
+
+// ChangePasswordRequest represents the payload required to change the password of the logged user.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// SwitchCompanyRequest represents the payload required to switch to another company of the logged user.
+type SwitchCompanyRequest struct {
+	CompanyID int `json:"company_id"`
+}
+
+// VerifyEmailRequest represents the payload required to confirm an email address.
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// TwoFactorVerifyRequest represents the payload required to complete a sign in with a two-factor code.
+// Code is either a code from the authenticator app or one of the recovery codes.
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// TwoFactorCodeRequest represents the payload carrying a two-factor code, used to confirm two-factor operations.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
 // AccessTokenResponse represents the response returned after successful authentication.
+// When TwoFactorRequired is true the tokens are empty and ChallengeToken has to be verified with a two-factor code.
 type AccessTokenResponse struct {
-	AccessToken string           `json:"access_token"`
-	User        UserResponse     `json:"user"`
-	Company     *CompanyResponse `json:"company"`
+	AccessToken           string           `json:"access_token"`
+	ExpiresAt             string           `json:"expires_at"`
+	RefreshToken          string           `json:"refresh_token"`
+	RefreshTokenExpiresAt string           `json:"refresh_token_expires_at"`
+	TwoFactorRequired     bool             `json:"two_factor_required"`
+	ChallengeToken        string           `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt    string           `json:"challenge_expires_at,omitempty"`
+	User                  UserResponse     `json:"user"`
+	Company               *CompanyResponse `json:"company"`
+}
+
+// TwoFactorSetupResponse represents what a user needs to register their account in an authenticator app.
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse represents the recovery codes of a user, only shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RefreshTokenResponse represents the response returned after refreshing an access token.
+type RefreshTokenResponse struct {
+	AccessToken           string `json:"access_token"`
+	ExpiresAt             string `json:"expires_at"`
+	RefreshToken          string `json:"refresh_token"`
+	RefreshTokenExpiresAt string `json:"refresh_token_expires_at"`
 }
 
 // UserResponse ...
@@ -41,6 +122,27 @@ type UserResponse struct {
 	Role     entity.UserRole `json:"role"`
 }
 
+// CompanyMembershipResponse represents a company the logged user belongs to.
+// Current is true for the company of the access token used by the request.
+type CompanyMembershipResponse struct {
+	ID      int             `json:"id"`
+	Name    string          `json:"name"`
+	LogoURL string          `json:"logo_url"`
+	Role    entity.UserRole `json:"role"`
+	Current bool            `json:"current"`
+}
+
+// CompanyMembershipResponseFromEntity converts a membership into its response, flagging the current company.
+func CompanyMembershipResponseFromEntity(membership entity.CompanyMembership, currentCompanyID int) CompanyMembershipResponse {
+	return CompanyMembershipResponse{
+		ID:      membership.CompanyID,
+		Name:    membership.CompanyName,
+		LogoURL: pointer.Get(membership.CompanyLogoURL),
+		Role:    membership.Role,
+		Current: membership.CompanyID == currentCompanyID,
+	}
+}
+
 // CompanyResponse ...
 type CompanyResponse struct {
 	ID          int    `json:"id"`
@@ -50,6 +152,7 @@ type CompanyResponse struct {
 	Website     string `json:"website"`
 	Address     string `json:"address"`
 	Description string `json:"description"`
+	LogoURL     string `json:"logo_url"`
 }
 
 func CompanyResponseFromEntity(company entity.Company) CompanyResponse {
@@ -61,6 +164,7 @@ func CompanyResponseFromEntity(company entity.Company) CompanyResponse {
 		Website:     pointer.Get(company.Website),
 		Address:     pointer.Get(company.Address),
 		Description: pointer.Get(company.Description),
+		LogoURL:     pointer.Get(company.LogoURL),
 	}
 }
 
@@ -73,12 +177,14 @@ func CompanyResponseFromEntity(company entity.Company) CompanyResponse {
 // });
 
 type ProfileResponse struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	JobTitle string `json:"jobTitle"`
-	Role     string `json:"role"`
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	Email            string `json:"email"`
+	EmailVerified    bool   `json:"emailVerified"`
+	TwoFactorEnabled bool   `json:"twoFactorEnabled"`
+	Phone            string `json:"phone"`
+	JobTitle         string `json:"jobTitle"`
+	Role             string `json:"role"`
 }
 
 func ProfileResponseFromEntity(user *entity.User) ProfileResponse {
@@ -87,11 +193,43 @@ func ProfileResponseFromEntity(user *entity.User) ProfileResponse {
 	}
 
 	return ProfileResponse{
-		ID:       user.ID,
-		Name:     user.GetName(),
-		Email:    user.Email,
-		Phone:    pointer.Get(user.PhoneNumber),
-		JobTitle: pointer.Get(user.JobTitle),
-		Role:     string(user.Role),
+		ID:               user.ID,
+		Name:             user.GetName(),
+		Email:            user.Email,
+		EmailVerified:    user.IsEmailVerified(),
+		TwoFactorEnabled: user.IsTwoFactorEnabled(),
+		Phone:            pointer.Get(user.PhoneNumber),
+		JobTitle:         pointer.Get(user.JobTitle),
+		Role:             string(user.Role),
 	}
 }
+
+// SessionResponse represents a device the logged user is signed in on.
+// Current is true for the session of the access token used by the request.
+type SessionResponse struct {
+	ID         int       `json:"id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+// SessionResponseFromEntity converts a session into its response, flagging the current session.
+func SessionResponseFromEntity(session entity.Session, currentSessionID int) SessionResponse {
+	return SessionResponse{
+		ID:         session.ID,
+		Device:     session.Device,
+		IP:         session.IP,
+		UserAgent:  session.UserAgent,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		Current:    session.ID == currentSessionID,
+	}
+}
+
+// RevokeSessionsResponse represents the number of sessions signed out at once.
+type RevokeSessionsResponse struct {
+	RevokedSessions int `json:"revoked_sessions"`
+}
@@ -0,0 +1,306 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+const (
+	// defaultBackOfficePerPage is the page size used when the request does not give one.
+	defaultBackOfficePerPage = 50
+
+	// maxBackOfficePerPage is the largest page size a request may ask for.
+	maxBackOfficePerPage = 200
+)
+
+// BackOfficeService defines the service interface used by the platform operators to oversee every company.
+type BackOfficeService interface {
+	ListCompanies(ctx context.Context, search, status string, request entity.PaginationRequest) (entity.PaginationResponse, error)
+	SuspendCompany(ctx context.Context, operatorCompanyID, companyID int, reason string) (*entity.Company, error)
+	ReactivateCompany(ctx context.Context, companyID int) (*entity.Company, error)
+	ChangeCompanyPlan(ctx context.Context, companyID int, planCode string) (*entity.Company, error)
+	Impersonate(ctx context.Context, operatorID, companyID, userID int) (*entity.User, *entity.Company, *entity.AuthResponse, error)
+	ListEvents(ctx context.Context, companyID int, request entity.PaginationRequest) (entity.PaginationResponse, error)
+}
+
+// BackOfficeHandler handles HTTP requests of the back office, restricted to super admins.
+type BackOfficeHandler struct {
+	backOfficeService BackOfficeService
+}
+
+// NewBackOfficeHandler creates a new instance of BackOfficeHandler.
+func NewBackOfficeHandler(backOfficeService BackOfficeService) *BackOfficeHandler {
+	return &BackOfficeHandler{backOfficeService: backOfficeService}
+}
+
+// RegisterBackOfficeRoutes registers the back office related routes within the Echo router group.
+func (h *BackOfficeHandler) RegisterBackOfficeRoutes(e *echo.Group, middleware *Middleware) {
+	superAdmins := []entity.UserRole{entity.UserRoleSuperAdmin}
+
+	e.GET("/companies", middleware.AuthMiddleware(superAdmins, h.handleListCompanies))
+	e.POST("/companies/:id/suspend", middleware.AuthMiddleware(superAdmins, h.handleSuspendCompany))
+	e.POST("/companies/:id/reactivate", middleware.AuthMiddleware(superAdmins, h.handleReactivateCompany))
+	e.PUT("/companies/:id/plan", middleware.AuthMiddleware(superAdmins, h.handleChangeCompanyPlan))
+	e.POST("/companies/:id/impersonate", middleware.AuthMiddleware(superAdmins, middleware.NoImpersonationMiddleware(h.handleImpersonate)))
+	e.GET("/events", middleware.AuthMiddleware(superAdmins, h.handleListEvents))
+}
+
+// handleListCompanies lists every company with its usage statistics.
+//
+//	@Summary		List companies
+//	@Description	Lists every company with its number of members, events and guests, newest first.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			q		query		string	false	"Only companies whose name contains this text"
+//	@Param			status	query		string	false	"Only active or suspended companies"	Enums(active, suspended)
+//	@Param			page	query		int		false	"Page number, starts at 1"
+//	@Param			perPage	query		int		false	"Number of companies per page, at most 200"
+//	@Success		200		{object}	Response{data=entity.PaginationResponse{records=[]CompanyUsageResponse}}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		401		{object}	Response	"Unauthorized"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/admin/companies [get]
+func (h *BackOfficeHandler) handleListCompanies(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status != "" && status != "active" && status != "suspended" {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid query parameter 'status' value, expected active or suspended."})
+	}
+
+	paginationRequest, err := parsePaginationQueryParams(c, "perPage", defaultBackOfficePerPage, maxBackOfficePerPage)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: err.Error()})
+	}
+
+	companies, err := h.backOfficeService.ListCompanies(c.Request().Context(), c.QueryParam("q"), status, paginationRequest)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	records, _ := companies.Records.([]entity.CompanyUsage)
+	companyResponses := make([]CompanyUsageResponse, 0, len(records))
+	for _, record := range records {
+		companyResponses = append(companyResponses, CompanyUsageResponseFromEntity(record))
+	}
+	companies.Records = companyResponses
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       companies,
+		Error:      nil,
+	})
+}
+
+// handleSuspendCompany suspends a company.
+//
+//	@Summary		Suspend a company
+//	@Description	Suspends a company: the requests of its users and API keys are refused until it is reactivated.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int						true	"Company ID"
+//	@Param			request	body		SuspendCompanyRequest	false	"Suspension payload"
+//	@Success		200		{object}	Response{data=BackOfficeCompanyResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		401		{object}	Response	"Unauthorized"
+//	@Failure		404		{object}	Response	"Company Not Found"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/admin/companies/{id}/suspend [post]
+func (h *BackOfficeHandler) handleSuspendCompany(c echo.Context) error {
+	companyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid company id"})
+	}
+
+	var request SuspendCompanyRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	company, err := h.backOfficeService.SuspendCompany(c.Request().Context(), c.Get("company_id").(int), companyID, request.Reason)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("%s suspended", company.Name),
+		Data:       BackOfficeCompanyResponseFromEntity(*company),
+		Error:      nil,
+	})
+}
+
+// handleReactivateCompany lifts the suspension of a company.
+//
+//	@Summary		Reactivate a company
+//	@Description	Lifts the suspension of a company.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		int	true	"Company ID"
+//	@Success		200	{object}	Response{data=BackOfficeCompanyResponse}
+//	@Failure		400	{object}	Response	"Bad Request"
+//	@Failure		401	{object}	Response	"Unauthorized"
+//	@Failure		404	{object}	Response	"Company Not Found"
+//	@Failure		500	{object}	Response	"Internal Server Error"
+//	@Router			/admin/companies/{id}/reactivate [post]
+func (h *BackOfficeHandler) handleReactivateCompany(c echo.Context) error {
+	companyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid company id"})
+	}
+
+	company, err := h.backOfficeService.ReactivateCompany(c.Request().Context(), companyID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("%s reactivated", company.Name),
+		Data:       BackOfficeCompanyResponseFromEntity(*company),
+		Error:      nil,
+	})
+}
+
+// handleChangeCompanyPlan subscribes a company to another plan.
+//
+//	@Summary		Change the plan of a company
+//	@Description	Subscribes a company to another plan. Usage above the new limits is kept, only new events, guests and messages are refused.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int							true	"Company ID"
+//	@Param			request	body		ChangeCompanyPlanRequest	true	"Plan payload"
+//	@Success		200		{object}	Response{data=BackOfficeCompanyResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		401		{object}	Response	"Unauthorized"
+//	@Failure		404		{object}	Response	"Company or Plan Not Found"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/admin/companies/{id}/plan [put]
+func (h *BackOfficeHandler) handleChangeCompanyPlan(c echo.Context) error {
+	companyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid company id"})
+	}
+
+	var request ChangeCompanyPlanRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	company, err := h.backOfficeService.ChangeCompanyPlan(c.Request().Context(), companyID, request.Plan)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("%s subscribed to %s", company.Name, company.PlanCode),
+		Data:       BackOfficeCompanyResponseFromEntity(*company),
+		Error:      nil,
+	})
+}
+
+// handleImpersonate issues an access token to act as an admin of a company.
+//
+//	@Summary		Impersonate a company admin
+//	@Description	Issues a short-lived access token to act as an admin of the company, without refresh token.
+//	@Description	Every action performed with it is recorded in the audit log of the company with the operator as impersonator.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		int					true	"Company ID"
+//	@Param			request	body		ImpersonateRequest	false	"Impersonation payload, the oldest admin is impersonated when user_id is not given"
+//	@Success		200		{object}	Response{data=ImpersonationResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		401		{object}	Response	"Unauthorized"
+//	@Failure		404		{object}	Response	"Company or Admin Not Found"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/admin/companies/{id}/impersonate [post]
+func (h *BackOfficeHandler) handleImpersonate(c echo.Context) error {
+	companyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid company id"})
+	}
+
+	var request ImpersonateRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	user, company, authResponse, err := h.backOfficeService.Impersonate(c.Request().Context(), c.Get("user_id").(int), companyID, request.UserID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("impersonating %s of %s", user.Email, company.Name),
+		Data: ImpersonationResponse{
+			AccessToken: authResponse.AccessToken,
+			ExpiresAt:   authResponse.ExpiresAt,
+			User: UserResponse{
+				ID:       user.ID,
+				Name:     user.GetName(),
+				Email:    user.Email,
+				Phone:    user.PhoneNumber,
+				JobTitle: user.JobTitle,
+				Role:     user.Role,
+			},
+			Company: CompanyResponseFromEntity(*company),
+		},
+		Error: nil,
+	})
+}
+
+// handleListEvents lists the events of every company.
+//
+//	@Summary		List events of every company
+//	@Description	Lists the events of every company, or of a single company, newest first.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			companyId	query		int	false	"Only the events of this company"
+//	@Param			page		query		int	false	"Page number, starts at 1"
+//	@Param			perPage		query		int	false	"Number of events per page, at most 200"
+//	@Success		200			{object}	Response{data=entity.PaginationResponse{records=[]entity.Event}}
+//	@Failure		400			{object}	Response	"Bad Request"
+//	@Failure		401			{object}	Response	"Unauthorized"
+//	@Failure		500			{object}	Response	"Internal Server Error"
+//	@Router			/admin/events [get]
+func (h *BackOfficeHandler) handleListEvents(c echo.Context) error {
+	var companyID int
+	if value := c.QueryParam("companyId"); value != "" {
+		var err error
+		if companyID, err = strconv.Atoi(value); err != nil || companyID < 1 {
+			return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid query parameter 'companyId' value."})
+		}
+	}
+
+	paginationRequest, err := parsePaginationQueryParams(c, "perPage", defaultBackOfficePerPage, maxBackOfficePerPage)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: err.Error()})
+	}
+
+	events, err := h.backOfficeService.ListEvents(c.Request().Context(), companyID, paginationRequest)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       events,
+		Error:      nil,
+	})
+}
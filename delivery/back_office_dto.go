@@ -0,0 +1,83 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+// SuspendCompanyRequest represents the payload required to suspend a company.
+type SuspendCompanyRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ImpersonateRequest represents the payload required to impersonate an admin of a company.
+// The oldest admin of the company is impersonated when UserID is not given.
+type ImpersonateRequest struct {
+	UserID int `json:"user_id"`
+}
+
+// ChangeCompanyPlanRequest represents the payload required to change the plan of a company.
+type ChangeCompanyPlanRequest struct {
+	Plan string `json:"plan"`
+}
+
+// BackOfficeCompanyResponse represents a company as shown to the platform operators.
+type BackOfficeCompanyResponse struct {
+	ID               int        `json:"id"`
+	Name             string     `json:"name"`
+	Email            string     `json:"email"`
+	Phone            string     `json:"phone"`
+	LogoURL          string     `json:"logo_url"`
+	Suspended        bool       `json:"suspended"`
+	SuspendedAt      *time.Time `json:"suspended_at"`
+	SuspensionReason string     `json:"suspension_reason"`
+	Plan             string     `json:"plan"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// CompanyUsageResponse represents a company with its usage statistics.
+type CompanyUsageResponse struct {
+	BackOfficeCompanyResponse
+	MemberCount int        `json:"member_count"`
+	EventCount  int        `json:"event_count"`
+	GuestCount  int        `json:"guest_count"`
+	LastEventAt *time.Time `json:"last_event_at"`
+}
+
+// ImpersonationResponse represents the access token issued to impersonate an admin of a company.
+// No refresh token is issued, the impersonation ends when the access token expires.
+type ImpersonationResponse struct {
+	AccessToken string          `json:"access_token"`
+	ExpiresAt   string          `json:"expires_at"`
+	User        UserResponse    `json:"user"`
+	Company     CompanyResponse `json:"company"`
+}
+
+// BackOfficeCompanyResponseFromEntity maps a company to its back office representation.
+func BackOfficeCompanyResponseFromEntity(company entity.Company) BackOfficeCompanyResponse {
+	return BackOfficeCompanyResponse{
+		ID:               company.ID,
+		Name:             company.Name,
+		Email:            company.Email,
+		Phone:            company.Phone,
+		LogoURL:          pointer.Get(company.LogoURL),
+		Suspended:        company.IsSuspended(),
+		SuspendedAt:      company.SuspendedAt,
+		SuspensionReason: pointer.Get(company.SuspensionReason),
+		Plan:             company.PlanCode,
+		CreatedAt:        company.CreatedAt,
+	}
+}
+
+// CompanyUsageResponseFromEntity maps a company and its usage statistics to their back office representation.
+func CompanyUsageResponseFromEntity(usage entity.CompanyUsage) CompanyUsageResponse {
+	return CompanyUsageResponse{
+		BackOfficeCompanyResponse: BackOfficeCompanyResponseFromEntity(usage.Company),
+		MemberCount:               usage.MemberCount,
+		EventCount:                usage.EventCount,
+		GuestCount:                usage.GuestCount,
+		LastEventAt:               usage.LastEventAt,
+	}
+}
@@ -0,0 +1,197 @@
+package delivery
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+// CompanyService defines the service interface for managing the profile of a company.
+type CompanyService interface {
+	GetCompany(ctx context.Context, companyID int) (*entity.Company, error)
+	UpdateCompanyProfile(ctx context.Context, companyID int, profile entity.Company) (*entity.Company, error)
+	UpdateCompanyLogo(ctx context.Context, companyID int, logo io.Reader) (*entity.Company, error)
+	DeleteCompanyLogo(ctx context.Context, companyID int) error
+}
+
+// PlanService defines the service interface for reporting the usage of a company against its plan.
+type PlanService interface {
+	GetUsage(ctx context.Context, companyID int) (*entity.PlanUsage, error)
+}
+
+// CompanyHandler handles HTTP requests related to the profile of a company.
+type CompanyHandler struct {
+	companyService CompanyService
+	planService    PlanService
+}
+
+// NewCompanyHandler creates a new instance of CompanyHandler.
+func NewCompanyHandler(companyService CompanyService, planService PlanService) *CompanyHandler {
+	return &CompanyHandler{companyService: companyService, planService: planService}
+}
+
+// RegisterCompanyRoutes registers the company profile related routes within the Echo router group.
+func (h *CompanyHandler) RegisterCompanyRoutes(e *echo.Group, middleware *Middleware) {
+	companyManagers := []entity.Permission{entity.PermissionTeamManage}
+
+	e.GET("", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleGetCompany))
+	e.GET("/usage", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleGetCompanyUsage))
+	e.PUT("", middleware.PermissionMiddleware(companyManagers, h.handleUpdateCompany))
+	e.PUT("/logo", middleware.PermissionMiddleware(companyManagers, h.handleUpdateCompanyLogo))
+	e.DELETE("/logo", middleware.PermissionMiddleware(companyManagers, h.handleDeleteCompanyLogo))
+}
+
+// handleGetCompany returns the profile of the caller's company.
+//
+//	@Summary		Get the company profile
+//	@Description	Returns the profile of the caller's company.
+//	@Tags			company
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	Response{data=CompanyResponse}
+//	@Failure		404	{object}	Response	"Company Not Found"
+//	@Failure		500	{object}	Response	"Internal Server Error"
+//	@Router			/company [get]
+func (h *CompanyHandler) handleGetCompany(c echo.Context) error {
+	company, err := h.companyService.GetCompany(c.Request().Context(), c.Get("company_id").(int))
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       CompanyResponseFromEntity(*company),
+		Error:      nil,
+	})
+}
+
+// handleGetCompanyUsage returns the plan of the caller's company and its usage.
+//
+//	@Summary		Get the company usage
+//	@Description	Returns the plan of the caller's company, its limits and the current usage. A null limit is unlimited.
+//	@Tags			company
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	Response{data=PlanUsageResponse}
+//	@Failure		404	{object}	Response	"Company Not Found"
+//	@Failure		500	{object}	Response	"Internal Server Error"
+//	@Router			/company/usage [get]
+func (h *CompanyHandler) handleGetCompanyUsage(c echo.Context) error {
+	usage, err := h.planService.GetUsage(c.Request().Context(), c.Get("company_id").(int))
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       PlanUsageResponseFromEntity(*usage),
+		Error:      nil,
+	})
+}
+
+// handleUpdateCompany updates the profile of the caller's company.
+//
+//	@Summary		Update the company profile
+//	@Description	Replaces the name, contact details and description of the caller's company. Empty optional fields are cleared.
+//	@Tags			company
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		UpdateCompanyRequest	true	"Company profile payload"
+//	@Success		200		{object}	Response{data=CompanyResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		403		{object}	Response	"Forbidden"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/company [put]
+func (h *CompanyHandler) handleUpdateCompany(c echo.Context) error {
+	var request UpdateCompanyRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	company, err := h.companyService.UpdateCompanyProfile(c.Request().Context(), c.Get("company_id").(int), entity.Company{
+		Name:        request.Name,
+		Email:       request.Email,
+		Phone:       request.Phone,
+		Address:     &request.Address,
+		Website:     &request.Website,
+		Description: &request.Description,
+	})
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "company profile updated",
+		Data:       CompanyResponseFromEntity(*company),
+		Error:      nil,
+	})
+}
+
+// handleUpdateCompanyLogo uploads the logo of the caller's company.
+//
+//	@Summary		Upload the company logo
+//	@Description	Replaces the logo of the caller's company with a png, jpeg or webp image of at most 2 MB.
+//	@Tags			company
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			logo	formData	file	true	"Logo image"
+//	@Success		200		{object}	Response{data=CompanyResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		403		{object}	Response	"Forbidden"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/company/logo [put]
+func (h *CompanyHandler) handleUpdateCompanyLogo(c echo.Context) error {
+	file, err := c.FormFile("logo")
+	if err != nil {
+		return throwServiceError(c, entity.ErrCompanyLogoMissing)
+	}
+
+	if file.Size > entity.MaxCompanyLogoSize {
+		return throwServiceError(c, entity.ErrCompanyLogoTooLarge)
+	}
+
+	logo, err := file.Open()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+	defer logo.Close()
+
+	company, err := h.companyService.UpdateCompanyLogo(c.Request().Context(), c.Get("company_id").(int), logo)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "company logo updated",
+		Data:       CompanyResponseFromEntity(*company),
+		Error:      nil,
+	})
+}
+
+// handleDeleteCompanyLogo removes the logo of the caller's company.
+//
+//	@Summary		Remove the company logo
+//	@Description	Removes the logo of the caller's company.
+//	@Tags			company
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	Response	"Logo removed"
+//	@Failure		403	{object}	Response	"Forbidden"
+//	@Failure		500	{object}	Response	"Internal Server Error"
+//	@Router			/company/logo [delete]
+func (h *CompanyHandler) handleDeleteCompanyLogo(c echo.Context) error {
+	if err := h.companyService.DeleteCompanyLogo(c.Request().Context(), c.Get("company_id").(int)); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "company logo removed"})
+}
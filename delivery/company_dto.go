@@ -0,0 +1,56 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+// UpdateCompanyRequest represents the payload required to update the profile of the caller's company.
+// Empty optional fields are cleared.
+type UpdateCompanyRequest struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	Address     string `json:"address"`
+	Website     string `json:"website"`
+	Description string `json:"description"`
+}
+
+// PlanResponse represents a plan and its limits, a null limit is unlimited.
+type PlanResponse struct {
+	Code                string `json:"code"`
+	Name                string `json:"name"`
+	MaxActiveEvents     *int   `json:"max_active_events"`
+	MaxGuestsPerEvent   *int   `json:"max_guests_per_event"`
+	MaxMessagesPerMonth *int   `json:"max_messages_per_month"`
+}
+
+// PlanUsageResponse represents the usage of a company against the limits of its plan.
+// Messages are counted from period_start to period_end.
+type PlanUsageResponse struct {
+	Plan               PlanResponse `json:"plan"`
+	ActiveEvents       int          `json:"active_events"`
+	LargestEventGuests int          `json:"largest_event_guests"`
+	MessagesThisPeriod int          `json:"messages_this_period"`
+	PeriodStart        time.Time    `json:"period_start"`
+	PeriodEnd          time.Time    `json:"period_end"`
+}
+
+// PlanUsageResponseFromEntity maps the usage of a company to its response.
+func PlanUsageResponseFromEntity(usage entity.PlanUsage) PlanUsageResponse {
+	return PlanUsageResponse{
+		Plan: PlanResponse{
+			Code:                usage.Plan.Code,
+			Name:                usage.Plan.Name,
+			MaxActiveEvents:     usage.Plan.MaxActiveEvents,
+			MaxGuestsPerEvent:   usage.Plan.MaxGuestsPerEvent,
+			MaxMessagesPerMonth: usage.Plan.MaxMessagesPerMonth,
+		},
+		ActiveEvents:       usage.ActiveEvents,
+		LargestEventGuests: usage.LargestEventGuests,
+		MessagesThisPeriod: usage.MessagesThisPeriod,
+		PeriodStart:        usage.PeriodStart,
+		PeriodEnd:          usage.PeriodEnd,
+	}
+}
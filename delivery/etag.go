@@ -0,0 +1,76 @@
+package delivery
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// headerETag is the response header holding the version of the returned resource.
+	headerETag = "ETag"
+
+	// headerIfMatch is the request header holding the version of the resource the client expects to change.
+	headerIfMatch = "If-Match"
+
+	// ifMatchAny is the If-Match header value matching any version of a resource.
+	ifMatchAny = "*"
+)
+
+// setETag sets the ETag header of the response to the strong entity tag of the given version of a resource.
+func setETag(c echo.Context, version string) {
+	c.Response().Header().Set(headerETag, `"`+version+`"`)
+}
+
+// ifMatchTag returns the entity tag given in the If-Match header of the request, without its quotes, or ifMatchAny.
+// present is false when the header is missing. Weak and malformed entity tags are returned as is,
+// so they never match a version, as If-Match only matches strong entity tags.
+func ifMatchTag(c echo.Context) (tag string, present bool) {
+	header := strings.TrimSpace(c.Request().Header.Get(headerIfMatch))
+	if header == "" {
+		return "", false
+	}
+
+	if len(header) > 2 && strings.HasPrefix(header, `"`) && strings.HasSuffix(header, `"`) {
+		return header[1 : len(header)-1], true
+	}
+
+	return header, true
+}
+
+// eventVersionFromTag returns the version of an event an If-Match entity tag expects:
+// 0 for any version, -1 when the tag can not match any version.
+func eventVersionFromTag(tag string) int {
+	if tag == ifMatchAny {
+		return 0
+	}
+
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return -1
+	}
+
+	return version
+}
+
+// guestListVersionFromTag returns the version of a guest list an If-Match entity tag expects, empty for any version.
+func guestListVersionFromTag(tag string) string {
+	if tag == ifMatchAny {
+		return ""
+	}
+
+	return tag
+}
+
+// preconditionRequiredResponse refuses a change made without the If-Match header,
+// which would overwrite the changes made by someone else in the meantime.
+func preconditionRequiredResponse(c echo.Context) error {
+	return c.JSON(http.StatusPreconditionRequired, Response{
+		StatusCode: http.StatusPreconditionRequired,
+		Message:    "please provide the ETag you retrieved in the If-Match header",
+		Data:       "PRECONDITION_REQUIRED",
+		Error:      nil,
+	})
+}
@@ -1,8 +1,13 @@
 package delivery
 
-import "time"
+import (
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+)
 
 // CreateEventRequest represents the payload for creating a new event.
+// Lifecycle is either "draft", the default, or "published".
 type CreateEventRequest struct {
 	Title       string    `json:"name"`
 	Type        string    `json:"type"`
@@ -11,6 +16,49 @@ type CreateEventRequest struct {
 	EndDate     time.Time `json:"endDate"`
 	Description string    `json:"description"`
 	GuestCount  int       `json:"guestCount"`
+	Lifecycle   string    `json:"lifecycle"`
+}
+
+// UpdateEventRequest represents the payload for updating an event, omitted fields are left unchanged.
+type UpdateEventRequest struct {
+	Title       *string    `json:"name"`
+	Type        *string    `json:"type"`
+	Location    *string    `json:"location"`
+	StartDate   *time.Time `json:"startDate"`
+	EndDate     *time.Time `json:"endDate"`
+	Description *string    `json:"description"`
+	GuestCount  *int       `json:"guestCount"`
+}
+
+// ToEventUpdate converts the payload into the fields of the event to change.
+func (r UpdateEventRequest) ToEventUpdate() entity.EventUpdate {
+	update := entity.EventUpdate{
+		Title:       r.Title,
+		Description: r.Description,
+		Location:    r.Location,
+		StartDate:   r.StartDate,
+		EndDate:     r.EndDate,
+		GuestCount:  r.GuestCount,
+	}
+
+	if r.Type != nil {
+		eventType := entity.ParseEventType(*r.Type)
+		update.Type = &eventType
+	}
+
+	return update
+}
+
+// ChangeEventStatusRequest represents the payload for moving an event to another stage of its lifecycle.
+// Reason is added to the message telling the guests about the cancellation of the event.
+type ChangeEventStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// AssignEventStaffRequest represents the payload for assigning a user to an event.
+type AssignEventStaffRequest struct {
+	Permissions []string `json:"permissions"`
 }
 
 // AddGuestRequest represents a request to add multiple guests to an event.
@@ -27,12 +75,6 @@ type GuestDetail struct {
 	IsVIP       bool   `json:"vip"`
 }
 
-// UpdateGuestVIPStatusRequest represents a request to update single guest's vip status.
-type UpdateGuestVIPStatusRequest struct {
-	GuestUUID string `json:"guest_uuid"`
-	IsVIP     bool   `json:"is_vip"`
-}
-
 // UpdateGuestAttendingAndMessage represents a request to update single guest's attending status.
 type UpdateGuestAttendingAndMessage struct {
 	ShortID     string `json:"short_id"`
@@ -52,6 +94,50 @@ type EventResponse struct {
 	GuestCount     int    `json:"guestCount"`
 	CheckedInCount int    `json:"checkedInCount"`
 	Status         string `json:"status"`
+	Lifecycle      string `json:"lifecycle"`
+}
+
+// EventResponseFromEntity converts an event into its response.
+// The status of the event is "Upcoming" until it starts, "Ongoing" until its end date is over and "Past" afterwards.
+// The lifecycle is the stage the event is in, such as draft, published or cancelled.
+func EventResponseFromEntity(event entity.Event) EventResponse {
+	var status string
+	switch event.TimingAt(time.Now()) {
+	case entity.EventTimingUpcoming:
+		status = "Upcoming"
+	case entity.EventTimingOngoing:
+		status = "Ongoing"
+	default:
+		status = "Past"
+	}
+
+	return EventResponse{
+		ID:          event.ID,
+		Name:        event.Title,
+		Type:        string(event.Type),
+		StartDate:   event.StartDate.Format(time.RFC3339),
+		EndDate:     event.EndDate.Format(time.RFC3339),
+		Location:    event.Location,
+		Description: event.Description,
+		GuestCount:  event.GuestCount,
+		Status:      status,
+		Lifecycle:   string(event.Status),
+	}
+}
+
+// GuestPageResponse represents a page of the guest list of an event.
+// NextCursor is given as cursor to retrieve the following page, it is empty on the last page.
+type GuestPageResponse struct {
+	Records    []entity.Guest `json:"records"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+// GuestPageResponseFromEntity converts a page of a guest list into its response.
+func GuestPageResponseFromEntity(page entity.GuestPage) GuestPageResponse {
+	return GuestPageResponse{
+		Records:    page.Guests,
+		NextCursor: page.NextCursor,
+	}
 }
 
 type PublicAddGuestRequest struct {
@@ -2,15 +2,14 @@ package delivery
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/xuri/excelize/v2"
 
@@ -20,21 +19,42 @@ import (
 	"github.com/mhdiiilham/gosm/pkg"
 )
 
+const (
+	// defaultEventsPerPage is the page size of the event list used when the request does not give one.
+	defaultEventsPerPage = 10
+
+	// maxEventsPerPage is the largest page size of the event list a request may ask for.
+	maxEventsPerPage = 100
+
+	// defaultGuestsPerPage is the page size of the guest list used when the request does not give one.
+	defaultGuestsPerPage = 100
+
+	// maxGuestsPerPage is the largest page size of the guest list a request may ask for.
+	maxGuestsPerPage = 500
+)
+
 // EventService defines the service interface for event-related operations.
 type EventService interface {
 	CreateEvent(ctx context.Context, eventRequest entity.Event) (createdEvent *entity.Event, err error)
-	GetEvent(ctx context.Context, userID, EventID int) (event *entity.Event, err error)
-	GetEvents(ctx context.Context, userID int, request entity.PaginationRequest) (response entity.PaginationResponse, err error)
-	AddGuests(ctx context.Context, eventID int, guestList []entity.Guest) (numberOfSuccess int, err error)
-	DeleteGuests(ctx context.Context, userID int, guestIDs []int) (err error)
-	UpdateGuestVIPStatus(ctx context.Context, guestID int, vipStatus bool) (err error)
-	UpdateEvent(ctx context.Context, event entity.Event) (err error)
-	DeleteEvent(ctx context.Context, eventID int) (success bool, err error)
-	SetGuestIsArrived(ctx context.Context, barcodeID string, isArrived bool) (err error)
-	GetGuests(ctx context.Context, eventID int) (guests []entity.Guest, err error)
+	GetEvent(ctx context.Context, companyID, EventID int) (event *entity.Event, err error)
+	GetEvents(ctx context.Context, companyID int, request entity.PaginationRequest) (response entity.PaginationResponse, err error)
+	AddGuests(ctx context.Context, companyID, eventID int, guestList []entity.Guest) (numberOfSuccess int, err error)
+	CheckGuestQuota(ctx context.Context, companyID, eventID, numberOfGuests int) error
+	RegisterGuest(ctx context.Context, eventID int, guest entity.Guest) (err error)
+	DeleteGuests(ctx context.Context, companyID, eventID int, guestIDs []int, expectedVersion string) (err error)
+	UpdateEvent(ctx context.Context, companyID, eventID, expectedVersion int, update entity.EventUpdate) (*entity.Event, error)
+	ChangeEventStatus(ctx context.Context, companyID, eventID, expectedVersion int, status entity.EventStatus, reason string) (*entity.Event, error)
+	DeleteEvent(ctx context.Context, companyID, eventID, expectedVersion int) (success bool, err error)
+	SetGuestIsArrived(ctx context.Context, companyID, eventID int, barcodeID string, isArrived bool) (err error)
+	GetGuests(ctx context.Context, companyID, eventID int, filter entity.GuestListFilter, cursor string, limit int) (entity.GuestPage, error)
+	GetGuestListVersion(ctx context.Context, companyID, eventID int) (string, error)
 	GetGuest(ctx context.Context, barcodeID string) (guest *entity.Guest, err error)
 	UpdateGuest(ctx context.Context, guestID, name, phone, message string, isAttending bool) error
 	GetGuestMessages(ctx context.Context, eventID string) ([]entity.GuestMessages, error)
+	GetStaffEvents(ctx context.Context, companyID, userID int, request entity.PaginationRequest) (response entity.PaginationResponse, err error)
+	GetEventStaff(ctx context.Context, companyID, eventID int) ([]entity.EventStaff, error)
+	AssignEventStaff(ctx context.Context, companyID, eventID, userID int, permissions []entity.Permission) error
+	RemoveEventStaff(ctx context.Context, companyID, eventID, userID int) error
 }
 
 // EventHandler handles HTTP requests related to event operations.
@@ -49,20 +69,26 @@ func NewEventHandler(service EventService) *EventHandler {
 
 // RegisterEventRoutes registers the event-related routes within the Echo router group.
 func (h *EventHandler) RegisterEventRoutes(e *echo.Group, middleware *Middleware) {
-	e.GET("", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleGetEvents))
-	e.POST("", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleCreateEvent))
+	e.GET("", middleware.AuthMiddleware(AllowedAuthenticatedOrAPIKey, h.handleGetEvents))
+	e.POST("", middleware.PermissionMiddleware([]entity.Permission{entity.PermissionEventCreate}, h.handleCreateEvent))
 
 	eventDetailGrouped := e.Group("/:id")
-	eventDetailGrouped.GET("", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleGetEvent))
-	eventDetailGrouped.PATCH("", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleUpdateEvent))
-	eventDetailGrouped.DELETE("", middleware.AuthMiddleware(AllowedSuperAdminOnly, h.handleDeleteEvent))
+	eventDetailGrouped.GET("", middleware.EventPermissionMiddleware(entity.PermissionEventView, h.handleGetEvent))
+	eventDetailGrouped.PATCH("", middleware.EventPermissionMiddleware(entity.PermissionEventUpdate, h.handleUpdateEvent))
+	eventDetailGrouped.DELETE("", middleware.EventPermissionMiddleware(entity.PermissionEventDelete, h.handleDeleteEvent))
+	eventDetailGrouped.POST("/status", middleware.EventPermissionMiddleware(entity.PermissionEventUpdate, h.handleChangeEventStatus))
 
 	eventDetailedGuestGrouped := eventDetailGrouped.Group("/guests")
-	eventDetailedGuestGrouped.GET("", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleGetGuests))
-	eventDetailedGuestGrouped.POST("", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleAddGuestToEvent))
-	eventDetailedGuestGrouped.POST("/csv", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleAddGuestCSV))
-	eventDetailedGuestGrouped.POST("/arrived", h.handleUpdateGuestArrived)
-	eventDetailedGuestGrouped.DELETE("", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleDeleteGuests))
+	eventDetailedGuestGrouped.GET("", middleware.EventPermissionMiddleware(entity.PermissionGuestView, h.handleGetGuests))
+	eventDetailedGuestGrouped.POST("", middleware.EventPermissionMiddleware(entity.PermissionGuestEdit, h.handleAddGuestToEvent))
+	eventDetailedGuestGrouped.POST("/csv", middleware.EventPermissionMiddleware(entity.PermissionGuestEdit, h.handleAddGuestCSV))
+	eventDetailedGuestGrouped.POST("/arrived", middleware.EventPermissionMiddleware(entity.PermissionGuestCheckIn, h.handleUpdateGuestArrived))
+	eventDetailedGuestGrouped.DELETE("", middleware.EventPermissionMiddleware(entity.PermissionGuestEdit, h.handleDeleteGuests))
+
+	eventDetailedStaffGrouped := eventDetailGrouped.Group("/staff")
+	eventDetailedStaffGrouped.GET("", middleware.EventPermissionMiddleware(entity.PermissionEventStaff, h.handleGetEventStaff))
+	eventDetailedStaffGrouped.PUT("/:userId", middleware.EventPermissionMiddleware(entity.PermissionEventStaff, h.handleAssignEventStaff))
+	eventDetailedStaffGrouped.DELETE("/:userId", middleware.EventPermissionMiddleware(entity.PermissionEventStaff, h.handleRemoveEventStaff))
 }
 
 // @Summary		Create an event
@@ -109,22 +135,11 @@ func (h *EventHandler) handleCreateEvent(c echo.Context) error {
 			ID: companyID,
 		},
 		GuestCount: request.GuestCount,
+		Status:     entity.EventStatus(request.Lifecycle),
 	})
 
 	if serviceErr != nil {
-		switch err := serviceErr.(type) {
-		case entity.GosmError:
-			if err.Type == entity.GosmErrorTypeBadRequest {
-				return c.JSON(http.StatusBadRequest, Response{
-					StatusCode: http.StatusBadRequest,
-					Message:    err.Message,
-					Data:       nil,
-					Error:      err.Source,
-				})
-			}
-		}
-
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(serviceErr))
+		return throwServiceError(c, serviceErr)
 	}
 
 	return c.JSON(http.StatusCreated, Response{
@@ -139,6 +154,7 @@ func (h *EventHandler) handleCreateEvent(c echo.Context) error {
 //
 //	@Summary		Get an event
 //	@Description	Fetches event details for the authenticated user.
+//	@Description	The ETag header holds the version of the event, to be given in the If-Match header to change it.
 //	@Tags			events
 //	@Accept			json
 //	@Produce		json
@@ -146,6 +162,7 @@ func (h *EventHandler) handleCreateEvent(c echo.Context) error {
 //	@Param			Authorization	header		string	true	"Bearer Token"
 //	@Param			id				path		string	true	"Event UUID"
 //	@Success		200				{object}	Response{data=entity.Event}
+//	@Header			200				{string}	ETag	"Version of the event"
 //	@Failure		400				{object}	Response	"Bad Request"
 //	@Failure		404				{object}	Response	"Event Not Found"
 //	@Failure		500				{object}	Response	"Internal Server Error"
@@ -157,23 +174,11 @@ func (h *EventHandler) handleGetEvent(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
 	}
 
-	userID := c.Get("user_id").(int)
+	companyID := c.Get("company_id").(int)
 
-	event, serviceErr := h.eventService.GetEvent(ctx, userID, eventID)
+	event, serviceErr := h.eventService.GetEvent(ctx, companyID, eventID)
 	if serviceErr != nil {
-		switch err := serviceErr.(type) {
-		case entity.GosmError:
-			if err.Type == entity.GosmErrorTypeBadRequest {
-				return c.JSON(http.StatusBadRequest, Response{
-					StatusCode: http.StatusBadRequest,
-					Message:    err.Message,
-					Data:       nil,
-					Error:      err.Source,
-				})
-			}
-		}
-
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(serviceErr))
+		return throwServiceError(c, serviceErr)
 	}
 
 	if event == nil {
@@ -185,101 +190,99 @@ func (h *EventHandler) handleGetEvent(c echo.Context) error {
 		})
 	}
 
-	status := "Upcoming"
-	if time.Now().After(event.EndDate) {
-		status = "Past"
-	}
+	setETag(c, strconv.Itoa(event.Version))
 
 	return c.JSON(http.StatusOK, Response{
 		StatusCode: http.StatusOK,
 		Message:    fmt.Sprintf("success get event: %s", event.Title),
-		Data: EventResponse{
-			ID:             event.ID,
-			Name:           event.Title,
-			Type:           string(event.Type),
-			StartDate:      event.StartDate.Format(time.RFC3339),
-			EndDate:        event.EndDate.Format(time.RFC3339),
-			Location:       event.Location,
-			Description:    event.Description,
-			GuestCount:     event.GuestCount,
-			CheckedInCount: 0,
-			Status:         status,
-		},
-		Error: nil,
+		Data:       EventResponseFromEntity(*event),
+		Error:      nil,
 	})
 }
 
 // handleGetEvents retrieves a paginated list of events for the authenticated user.
 //
 //	@Summary		Get list of events
-//	@Description	Fetches a paginated list of events for the authenticated user.
+//	@Description	Fetches a paginated list of events for the authenticated user, staff members only see the events they are assigned to.
 //	@Tags			events
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			Authorization	header		string	true	"Bearer Token"
-//	@Param			name			query		string	false	"Event name"
-//	@Param			host			query		string	false	"Event host"
+//	@Param			search			query		string	false	"Only events whose title contains this text"
+//	@Param			type			query		string	false	"Only events of this type, such as wedding"
+//	@Param			from			query		string	false	"Only events ending at or after this time (RFC3339)"
+//	@Param			to				query		string	false	"Only events starting before this time (RFC3339)"
+//	@Param			status			query		string	false	"Only upcoming, ongoing or past events"
+//	@Param			lifecycle		query		string	false	"Only events in this stage of their lifecycle, archived events are left out unless asked for"
+//	@Param			created_by		query		int		false	"Only events created by this user"
+//	@Param			sort			query		string	false	"Sort on title, start_date, end_date, created_at or guest_count (default: created_at)"
+//	@Param			order			query		string	false	"Sort in asc or desc order (default: desc)"
 //	@Param			page			query		int		false	"Page number (default: 1)"
-//	@Param			per_page		query		int		false	"Items per page (default: 10)"
-//	@Success		200				{object}	Response{data=entity.PaginationResponse{data=[]entity.Event}}
+//	@Param			per_page		query		int		false	"Items per page (default: 10, at most 100)"
+//	@Success		200				{object}	Response{data=entity.PaginationResponse{records=[]EventResponse}}
 //	@Failure		400				{object}	Response	"Bad Request"
 //	@Failure		500				{object}	Response	"Internal Server Error"
 //	@Router			/events [get]
 func (h *EventHandler) handleGetEvents(c echo.Context) error {
 	ctx := c.Request().Context()
 	companyID := c.Get("company_id").(int)
-	page := 1
 
-	var err error
-	if c.QueryParam("page") != "" {
-		page, err = strconv.Atoi(c.QueryParam("page"))
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, Response{
-				StatusCode: http.StatusBadRequest,
-				Message:    "invalid query parameter 'page' value.",
-				Data:       nil,
-				Error:      nil,
-			})
-		}
+	paginationRequest, err := parsePaginationQueryParams(c, "per_page", defaultEventsPerPage, maxEventsPerPage)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: err.Error()})
 	}
 
-	eventPaginatedResponse, err := h.eventService.GetEvents(ctx, companyID, entity.PaginationRequest{Page: page, PerPage: math.MaxInt})
-	if err != nil {
-		switch parsedErr := err.(type) {
-		case entity.GosmError:
-			if parsedErr.Type == entity.GosmErrorTypeBadRequest {
-				return c.JSON(http.StatusBadRequest, Response{
-					StatusCode: http.StatusBadRequest,
-					Message:    parsedErr.Message,
-					Data:       nil,
-					Error:      parsedErr.Source,
-				})
-			}
+	paginationRequest.Field = map[string]any{
+		entity.EventFieldSearch:    c.QueryParam("search"),
+		entity.EventFieldType:      c.QueryParam("type"),
+		entity.EventFieldTiming:    c.QueryParam("status"),
+		entity.EventFieldLifecycle: c.QueryParam("lifecycle"),
+		entity.EventFieldSort:      c.QueryParam("sort"),
+		entity.EventFieldOrder:     c.QueryParam("order"),
+	}
+
+	for _, param := range []string{entity.EventFieldFrom, entity.EventFieldTo} {
+		value, err := parseTimeQueryParam(c, param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: fmt.Sprintf("invalid query parameter '%s' value, expected RFC3339.", param)})
 		}
 
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		if value != nil {
+			paginationRequest.Field[param] = *value
+		}
 	}
 
-	var events []EventResponse
-	for _, event := range eventPaginatedResponse.Records.([]entity.Event) {
-		status := "Upcoming"
-		if time.Now().After(event.EndDate) {
-			status = "Past"
+	if createdBy := c.QueryParam("created_by"); createdBy != "" {
+		userID, err := strconv.Atoi(createdBy)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid query parameter 'created_by' value."})
 		}
 
-		events = append(events, EventResponse{
-			ID:          event.ID,
-			Name:        event.Title,
-			Type:        string(event.Type),
-			Description: event.Description,
-			Location:    event.Location,
-			StartDate:   event.StartDate.Format(time.RFC3339),
-			EndDate:     event.EndDate.Format(time.RFC3339),
-			GuestCount:  event.GuestCount,
-			Status:      status,
+		paginationRequest.Field[entity.EventFieldCreatedBy] = userID
+	}
+
+	var eventPaginatedResponse entity.PaginationResponse
+	if hasPermission(c, entity.PermissionEventView) {
+		eventPaginatedResponse, err = h.eventService.GetEvents(ctx, companyID, paginationRequest)
+	} else if c.Get("user_role") == entity.UserRoleAPIKey {
+		return c.JSON(http.StatusForbidden, Response{
+			StatusCode: http.StatusForbidden,
+			Message:    "you are not allowed to perform this action",
+			Data:       "PERMISSION_DENIED",
+			Error:      nil,
 		})
+	} else {
+		eventPaginatedResponse, err = h.eventService.GetStaffEvents(ctx, companyID, c.Get("user_id").(int), paginationRequest)
+	}
+
+	if err != nil {
+		return throwServiceError(c, err)
+	}
 
+	events := []EventResponse{}
+	for _, event := range eventPaginatedResponse.Records.([]entity.Event) {
+		events = append(events, EventResponseFromEntity(event))
 	}
 
 	eventPaginatedResponse.Records = events
@@ -331,21 +334,9 @@ func (h *EventHandler) handleAddGuestToEvent(c echo.Context) error {
 		guestList = append(guestList, toBeAddedGuest)
 	}
 
-	numberOfSuccess, err := h.eventService.AddGuests(ctx, eventID, guestList)
+	numberOfSuccess, err := h.eventService.AddGuests(ctx, c.Get("company_id").(int), eventID, guestList)
 	if err != nil {
-		switch parsedErr := err.(type) {
-		case entity.GosmError:
-			if parsedErr.Type == entity.GosmErrorTypeBadRequest {
-				return c.JSON(http.StatusBadRequest, Response{
-					StatusCode: http.StatusBadRequest,
-					Message:    parsedErr.Message,
-					Data:       nil,
-					Error:      parsedErr.Source,
-				})
-			}
-		}
-
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		return throwServiceError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -360,20 +351,35 @@ func (h *EventHandler) handleAddGuestToEvent(c echo.Context) error {
 //
 //	@Summary		Delete guests from an event
 //	@Description	Allows authenticated users to remove multiple guests from a specific event.
+//	@Description	The If-Match header must hold the ETag of the guest list, the guests are not removed when it changed since.
 //	@Tags			events
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			Authorization	header		string			true	"Bearer Token"
-//	@Param			request			body		AddGuestRequest	true	"List of guests to be deleted (UUIDs required)"
+//	@Param			If-Match		header		string			true	"ETag of the guest list"
+//	@Param			id				path		int				true	"Event ID"
+//	@Param			request			body		AddGuestRequest	true	"List of guests to be deleted (IDs required)"
 //	@Success		200				{object}	Response		"Guests successfully removed"
 //	@Failure		400				{object}	Response		"Bad Request"
+//	@Failure		404				{object}	Response		"Event Not Found"
+//	@Failure		412				{object}	Response{data=[]entity.Guest}	"Guest list changed, current guest list"
+//	@Failure		428				{object}	Response		"If-Match header missing"
 //	@Failure		500				{object}	Response		"Internal Server Error"
-//	@Router			/events/guests [delete]
+//	@Router			/events/{id}/guests [delete]
 func (h *EventHandler) handleDeleteGuests(c echo.Context) error {
 	var request AddGuestRequest
 	ctx := c.Request().Context()
-	userID := c.Get("user_id").(int)
+	companyID := c.Get("company_id").(int)
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	tag, present := ifMatchTag(c)
+	if !present {
+		return preconditionRequiredResponse(c)
+	}
 
 	if err := c.Bind(&request); err != nil {
 		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
@@ -384,126 +390,194 @@ func (h *EventHandler) handleDeleteGuests(c echo.Context) error {
 		targetDeleteUUIDs = append(targetDeleteUUIDs, guest.ID)
 	}
 
-	if err := h.eventService.DeleteGuests(ctx, userID, targetDeleteUUIDs); err != nil {
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	if err := h.eventService.DeleteGuests(ctx, companyID, eventID, targetDeleteUUIDs, guestListVersionFromTag(tag)); err != nil {
+		if errors.Is(err, entity.ErrGuestListVersionMismatch) {
+			return h.guestListPreconditionFailed(c, companyID, eventID, err)
+		}
+		return throwServiceError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "ok"})
 }
 
-// handleUpdateGuestVIPStatus updates the VIP status of a guest.
+// handleUpdateEvent updates an existing event.
 //
-//	@Summary		Update guest VIP status
-//	@Description	Allows authenticated users to change a guest's VIP status.
-//	@Tags			guests
+//	@Summary		Update an event
+//	@Description	Changes the fields of an event given in the payload, the other fields are left unchanged.
+//	@Description	The end date of the event must be after its start date.
+//	@Description	The If-Match header must hold the ETag of the event, the event is not changed when someone else changed it since.
+//	@Tags			events
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			Authorization	header		string		true	"Bearer Token"
-//	@Param			guest_id		path		string		true	"Guest UUID"
-//	@Param			is_vip			query		boolean		true	"VIP status (true/false)"
-//	@Success		200				{object}	Response	"Guest VIP status updated successfully"
+//	@Param			Authorization	header		string				true	"Bearer Token"
+//	@Param			If-Match		header		string				true	"ETag of the event"
+//	@Param			id				path		int					true	"Event ID"
+//	@Param			body			body		UpdateEventRequest	true	"Event fields to change"
+//	@Success		200				{object}	Response{data=EventResponse}
+//	@Header			200				{string}	ETag	"New version of the event"
 //	@Failure		400				{object}	Response	"Bad Request"
+//	@Failure		404				{object}	Response	"Event Not Found"
+//	@Failure		412				{object}	Response{data=EventResponse}	"Event changed, current event"
+//	@Failure		428				{object}	Response	"If-Match header missing"
 //	@Failure		500				{object}	Response	"Internal Server Error"
-//	@Router			/guests/{guest_id} [patch]
-func (h *EventHandler) handleUpdateGuestVIPStatus(c echo.Context) error {
-	ctx := c.Request().Context()
-	isVIPQueryParam := c.QueryParam("is_vip")
-	guestID, err := strconv.Atoi(c.Param("guest_id"))
+//	@Router			/events/{id} [patch]
+func (h *EventHandler) handleUpdateEvent(c echo.Context) error {
+	eventID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid event id"})
 	}
 
-	isVIP, _ := strconv.ParseBool(isVIPQueryParam)
+	ctx := c.Request().Context()
+	const ops = "EventHandler.handleUpdateEvent"
+	companyID := c.Get("company_id").(int)
+	var request UpdateEventRequest
 
-	if err := h.eventService.UpdateGuestVIPStatus(ctx, guestID, isVIP); err != nil {
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	tag, present := ifMatchTag(c)
+	if !present {
+		return preconditionRequiredResponse(c)
 	}
 
-	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "ok"})
+	if err := c.Bind(&request); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	event, err := h.eventService.UpdateEvent(ctx, companyID, eventID, eventVersionFromTag(tag), request.ToEventUpdate())
+	if err != nil {
+		if errors.Is(err, entity.ErrEventVersionMismatch) {
+			return h.eventPreconditionFailed(c, companyID, eventID, err)
+		}
+		return throwServiceError(c, err)
+	}
+
+	setETag(c, strconv.Itoa(event.Version))
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("success update event: %s", event.Title),
+		Data:       EventResponseFromEntity(*event),
+		Error:      nil,
+	})
 }
 
-// handleUpdateEvent updates an existing event.
+// handleChangeEventStatus moves an event to another stage of its lifecycle.
 //
-//	@Summary		Update an event
-//	@Description	Allows authenticated users to update event details.
+//	@Summary		Change the status of an event
+//	@Description	Moves an event along its lifecycle: draft to published, published to ongoing, ongoing to completed.
+//	@Description	Events which are not over can be cancelled, their guests are told by WhatsApp. Drafts, completed and cancelled events can be archived.
+//	@Description	Cancelling an event also requires the message:send permission, as its guests are sent a message.
+//	@Description	The If-Match header must hold the ETag of the event, the event is not changed when someone else changed it since.
 //	@Tags			events
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Param			Authorization	header		string				true	"Bearer Token"
-//	@Param			id				path		string				true	"Event UUID"
-//	@Param			body			body		CreateEventRequest	true	"Event update payload"
-//	@Success		200				{object}	Response			"Event updated successfully"
-//	@Failure		400				{object}	Response			"Bad Request"
-//	@Failure		500				{object}	Response			"Internal Server Error"
-//	@Router			/events/{id} [patch]
-func (h *EventHandler) handleUpdateEvent(c echo.Context) error {
+//	@Param			Authorization	header		string						true	"Bearer Token"
+//	@Param			If-Match		header		string						true	"ETag of the event"
+//	@Param			id				path		int							true	"Event ID"
+//	@Param			body			body		ChangeEventStatusRequest	true	"New status of the event"
+//	@Success		200				{object}	Response{data=EventResponse}
+//	@Header			200				{string}	ETag	"New version of the event"
+//	@Failure		400				{object}	Response	"Bad Request"
+//	@Failure		403				{object}	Response	"Cancelling without the message:send permission"
+//	@Failure		404				{object}	Response	"Event Not Found"
+//	@Failure		412				{object}	Response{data=EventResponse}	"Event changed, current event"
+//	@Failure		428				{object}	Response	"If-Match header missing"
+//	@Failure		500				{object}	Response	"Internal Server Error"
+//	@Router			/events/{id}/status [post]
+func (h *EventHandler) handleChangeEventStatus(c echo.Context) error {
 	eventID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid event id"})
 	}
 
 	ctx := c.Request().Context()
-	var request CreateEventRequest
+	companyID := c.Get("company_id").(int)
+	var request ChangeEventStatusRequest
+
+	tag, present := ifMatchTag(c)
+	if !present {
+		return preconditionRequiredResponse(c)
+	}
 
 	if err := c.Bind(&request); err != nil {
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		logger.Warn(ctx, "EventHandler.handleChangeEventStatus", "failed to parse request body")
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
 	}
 
-	if err := h.eventService.UpdateEvent(ctx, entity.Event{
-		ID:          eventID,
-		Title:       request.Title,
-		Type:        entity.ParseEventType(request.Type),
-		Description: request.Description,
-		Location:    request.Location,
-		StartDate:   request.StartDate,
-		EndDate:     request.EndDate,
-	}); err != nil {
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	status, ok := entity.ParseEventStatus(request.Status)
+	if !ok {
+		return throwServiceError(c, entity.ErrEventInvalidStatus)
+	}
+
+	// the guests of a cancelled event are sent a WhatsApp message.
+	if status == entity.EventStatusCancelled && !hasPermission(c, entity.PermissionMessageSend) {
+		return c.JSON(http.StatusForbidden, Response{
+			StatusCode: http.StatusForbidden,
+			Message:    "you are not allowed to perform this action",
+			Data:       "PERMISSION_DENIED",
+			Error:      nil,
+		})
 	}
 
-	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "updated!"})
+	event, err := h.eventService.ChangeEventStatus(ctx, companyID, eventID, eventVersionFromTag(tag), status, request.Reason)
+	if err != nil {
+		if errors.Is(err, entity.ErrEventVersionMismatch) {
+			return h.eventPreconditionFailed(c, companyID, eventID, err)
+		}
+		return throwServiceError(c, err)
+	}
+
+	setETag(c, strconv.Itoa(event.Version))
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("event %s is now %s", event.Title, event.Status),
+		Data:       EventResponseFromEntity(*event),
+		Error:      nil,
+	})
 }
 
 // handleDeleteEvent deletes an event.
 //
 //	@Summary		Delete an event
-//	@Description	Allows only super admins to delete an event.
+//	@Description	Allows users granted the event:delete permission to delete an event.
+//	@Description	The If-Match header must hold the ETag of the event, the event is not deleted when someone else changed it since.
 //	@Tags			events
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			Authorization	header		string		true	"Bearer Token"
-//	@Param			uuid			path		string		true	"Event UUID"
+//	@Param			If-Match		header		string		true	"ETag of the event"
+//	@Param			id				path		int			true	"Event ID"
 //	@Success		200				{object}	Response	"Event deleted successfully"
 //	@Failure		400				{object}	Response	"Bad Request"
-//	@Failure		403				{object}	Response	"Forbidden - Only super admins allowed"
+//	@Failure		403				{object}	Response	"Forbidden - Missing event:delete permission"
+//	@Failure		404				{object}	Response	"Event Not Found"
+//	@Failure		412				{object}	Response{data=EventResponse}	"Event changed, current event"
+//	@Failure		428				{object}	Response	"If-Match header missing"
 //	@Failure		500				{object}	Response	"Internal Server Error"
-//	@Router			/events/{uuid} [delete]
+//	@Router			/events/{id} [delete]
 func (h *EventHandler) handleDeleteEvent(c echo.Context) error {
-	eventID, err := strconv.Atoi(c.Param("uuid"))
+	eventID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
 	}
 
 	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(int)
+
+	tag, present := ifMatchTag(c)
+	if !present {
+		return preconditionRequiredResponse(c)
+	}
 
-	success, err := h.eventService.DeleteEvent(ctx, eventID)
+	success, err := h.eventService.DeleteEvent(ctx, companyID, eventID, eventVersionFromTag(tag))
 	if err != nil {
-		switch parsedErr := err.(type) {
-		case entity.GosmError:
-			if parsedErr.Type == entity.GosmErrorTypeBadRequest {
-				return c.JSON(http.StatusBadRequest, Response{
-					StatusCode: http.StatusBadRequest,
-					Message:    parsedErr.Message,
-					Data:       nil,
-					Error:      parsedErr.Source,
-				})
-			}
+		if errors.Is(err, entity.ErrEventVersionMismatch) {
+			return h.eventPreconditionFailed(c, companyID, eventID, err)
 		}
-
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		return throwServiceError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -525,27 +599,21 @@ func (h *EventHandler) handleDeleteEvent(c echo.Context) error {
 //	@Param			is_arrived	query		bool		true	"Arrival status (true/false)"
 //	@Success		200			{object}	Response	"Guest arrival status updated successfully"
 //	@Failure		400			{object}	Response	"Bad request (invalid guest ID or parameters)"
+//	@Failure		404			{object}	Response	"Event or guest not found"
 //	@Failure		500			{object}	Response	"Internal server error"
-//	@Router			/events/{uuid}/guests/arrived [post]
+//	@Router			/events/{id}/guests/arrived [post]
 func (h *EventHandler) handleUpdateGuestArrived(c echo.Context) error {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
 	barcodeID := c.QueryParam("barcode_id")
 	isArrived, _ := strconv.ParseBool(c.QueryParam("is_arrived"))
 	ctx := c.Request().Context()
 
-	if err := h.eventService.SetGuestIsArrived(ctx, barcodeID, isArrived); err != nil {
-		switch parsedErr := err.(type) {
-		case entity.GosmError:
-			if parsedErr.Type == entity.GosmErrorTypeBadRequest {
-				return c.JSON(http.StatusBadRequest, Response{
-					StatusCode: http.StatusBadRequest,
-					Message:    parsedErr.Message,
-					Data:       nil,
-					Error:      parsedErr.Source,
-				})
-			}
-		}
-
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	if err := h.eventService.SetGuestIsArrived(ctx, c.Get("company_id").(int), eventID, barcodeID, isArrived); err != nil {
+		return throwServiceError(c, err)
 	}
 
 	return c.JSON(http.StatusOK, Response{
@@ -556,6 +624,26 @@ func (h *EventHandler) handleUpdateGuestArrived(c echo.Context) error {
 	})
 }
 
+// handleGetGuests retrieves a page of the guests of an event, VIP guests first.
+// The ETag header holds the version of the whole guest list, to be given in the If-Match header to delete guests.
+//
+//	@Summary		List the guests of an event
+//	@Description	Lists the guests of an event page by page, the next_cursor of a page is given as cursor to retrieve the following one.
+//	@Tags			events
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		int		true	"Event ID"
+//	@Param			search		query		string	false	"Only guests whose name or phone number contains this text, or with this barcode"
+//	@Param			vip			query		bool	false	"Only VIP or non-VIP guests"
+//	@Param			attending	query		bool	false	"Only guests attending or not attending the event"
+//	@Param			checked_in	query		bool	false	"Only guests checked in or not checked in"
+//	@Param			cursor		query		string	false	"Cursor of the page, the first page when omitted"
+//	@Param			limit		query		int		false	"Number of guests per page (default: 100, at most 500)"
+//	@Success		200			{object}	Response{data=GuestPageResponse}
+//	@Failure		400			{object}	Response	"Bad Request"
+//	@Failure		404			{object}	Response	"Event not found"
+//	@Failure		500			{object}	Response	"Internal Server Error"
+//	@Router			/events/{id}/guests [get]
 func (h *EventHandler) handleGetGuests(c echo.Context) error {
 	eventID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -563,23 +651,66 @@ func (h *EventHandler) handleGetGuests(c echo.Context) error {
 	}
 
 	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(int)
+
+	limit := defaultGuestsPerPage
+	if value := c.QueryParam("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > maxGuestsPerPage {
+			return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid query parameter 'limit' value."})
+		}
+	}
+
+	filter := entity.GuestListFilter{Search: c.QueryParam("search")}
+	for param, target := range map[string]**bool{
+		"vip":        &filter.IsVIP,
+		"attending":  &filter.IsAttending,
+		"checked_in": &filter.CheckedIn,
+	} {
+		value := c.QueryParam(param)
+		if value == "" {
+			continue
+		}
 
-	guests, err := h.eventService.GetGuests(ctx, eventID)
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: fmt.Sprintf("invalid query parameter '%s' value.", param)})
+		}
+
+		*target = &parsed
+	}
+
+	// the version is read first, so a change made in between makes the ETag outdated rather than hiding the change.
+	version, err := h.eventService.GetGuestListVersion(ctx, companyID, eventID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		return throwServiceError(c, err)
+	}
+
+	page, err := h.eventService.GetGuests(ctx, companyID, eventID, filter, c.QueryParam("cursor"), limit)
+	if err != nil {
+		return throwServiceError(c, err)
 	}
 
+	setETag(c, version)
+
 	return c.JSON(http.StatusOK, Response{
 		StatusCode: http.StatusOK,
 		Message:    "ok",
-		Data:       guests,
+		Data:       GuestPageResponseFromEntity(page),
 		Error:      nil,
 	})
 }
 
 func (h *EventHandler) handleAddGuestCSV(c echo.Context) error {
 	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(int)
 	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	if _, err := h.eventService.GetEvent(ctx, companyID, eventID); err != nil {
+		return throwServiceError(c, err)
+	}
 
 	f, err := c.FormFile("guest_file")
 	if err != nil {
@@ -594,73 +725,26 @@ func (h *EventHandler) handleAddGuestCSV(c echo.Context) error {
 	}
 	defer src.Close()
 
-	// Read the data asynchronously
+	guests, err := parseGuestFile(src, fileExt, eventID)
+	if err != nil {
+		logger.Warn(ctx, "EventHandler.handleAddGuestCSV", "failed to parse guest file: %v", err)
+		return throwServiceError(c, entity.ErrGuestFileInvalid)
+	}
+
+	// imports over the quota are refused before responding, only the insert runs in the background.
+	if err := h.eventService.CheckGuestQuota(ctx, companyID, eventID, len(guests)); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	// the import outlives the request but keeps its actor, IP address and request ID for the audit log.
 	go func() {
-		ctx := context.Background()
-		var guests []entity.Guest
-
-		switch fileExt {
-		case ".csv":
-			guestRaw, err := io.ReadAll(src)
-			if err != nil {
-				logger.Errorf(ctx, "error", "err: %v", err)
-				return
-			}
-			for _, row := range strings.Split(string(guestRaw), "\r\n")[1:] {
-				if row == "" {
-					continue
-				}
-				cols := strings.Split(row, ",")
-				vipStatus, _ := strconv.ParseBool(cols[3])
-				guests = append(guests, entity.Guest{
-					EventID: eventID,
-					Name:    cols[0],
-					Phone:   pkg.FormatPhoneToWaMe(cols[1]),
-					Email:   cols[2],
-					IsVIP:   vipStatus,
-				})
-			}
-		case ".xlsx":
-			tmpFile, err := os.CreateTemp("", "upload-*.xlsx")
-			if err != nil {
-				logger.Errorf(ctx, "error", "err: %v", err)
-				return
-			}
-			defer os.Remove(tmpFile.Name())
-			io.Copy(tmpFile, src)
-			tmpFile.Close()
-
-			xlFile, err := excelize.OpenFile(tmpFile.Name())
-			if err != nil {
-				logger.Errorf(ctx, "error", "err: %v", err)
-				return
-			}
-			rows, err := xlFile.GetRows("Sheet1")
-			if err != nil {
-				logger.Errorf(ctx, "error", "err: %v", err)
-				return
-			}
-			for _, row := range rows[1:] {
-				vipStatus := false
-				if len(row) == 4 {
-					vipStatus, _ = strconv.ParseBool(row[3])
-				}
-
-				guests = append(guests, entity.Guest{
-					EventID: eventID,
-					Name:    row[0],
-					Phone:   pkg.FormatPhoneToWaMe(row[1]),
-					Email:   row[2],
-					IsVIP:   vipStatus,
-				})
-			}
-		default:
-			logger.Errorf(ctx, "unsupported file type: %s", fileExt)
-			return
-		}
+		ctx := context.WithoutCancel(ctx)
 
 		logger.Infof(ctx, "EventHandler.handleAddGuestCSV", "processing guest list")
-		h.eventService.AddGuests(ctx, eventID, guests)
+		if _, err := h.eventService.AddGuests(ctx, companyID, eventID, guests); err != nil {
+			logger.Errorf(ctx, "EventHandler.handleAddGuestCSV", "failed to add guest list: %v", err)
+			return
+		}
 		logger.Infof(ctx, "EventHandler.handleAddGuestCSV", "done processing guest list")
 	}()
 
@@ -670,3 +754,220 @@ func (h *EventHandler) handleAddGuestCSV(c echo.Context) error {
 		Error:      nil,
 	})
 }
+
+// parseGuestFile reads the guests of an event from an uploaded .csv or .xlsx file, whose first row is a header
+// followed by rows of name, phone, email and, optionally, VIP status.
+func parseGuestFile(src io.Reader, fileExt string, eventID int) ([]entity.Guest, error) {
+	var rows [][]string
+
+	switch fileExt {
+	case ".csv":
+		guestRaw, err := io.ReadAll(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range strings.Split(string(guestRaw), "\r\n")[1:] {
+			if row == "" {
+				continue
+			}
+			rows = append(rows, strings.Split(row, ","))
+		}
+	case ".xlsx":
+		tmpFile, err := os.CreateTemp("", "upload-*.xlsx")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(tmpFile.Name())
+		io.Copy(tmpFile, src)
+		tmpFile.Close()
+
+		xlFile, err := excelize.OpenFile(tmpFile.Name())
+		if err != nil {
+			return nil, err
+		}
+		defer xlFile.Close()
+
+		sheetRows, err := xlFile.GetRows("Sheet1")
+		if err != nil {
+			return nil, err
+		}
+		if len(sheetRows) > 0 {
+			rows = sheetRows[1:]
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file type: %s", fileExt)
+	}
+
+	guests := make([]entity.Guest, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 3 {
+			return nil, fmt.Errorf("guest row has %d columns, expected at least 3", len(row))
+		}
+
+		vipStatus := false
+		if len(row) >= 4 {
+			vipStatus, _ = strconv.ParseBool(row[3])
+		}
+
+		guests = append(guests, entity.Guest{
+			EventID: eventID,
+			Name:    row[0],
+			Phone:   pkg.FormatPhoneToWaMe(row[1]),
+			Email:   row[2],
+			IsVIP:   vipStatus,
+		})
+	}
+
+	return guests, nil
+}
+
+// handleGetEventStaff lists the staff assigned to an event.
+//
+//	@Summary		List event staff
+//	@Description	Lists the users assigned to an event together with their permissions.
+//	@Tags			events
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Authorization	header		string	true	"Bearer Token"
+//	@Param			id				path		int		true	"Event ID"
+//	@Success		200				{object}	Response{data=[]entity.EventStaff}
+//	@Failure		500				{object}	Response	"Internal Server Error"
+//	@Router			/events/{id}/staff [get]
+func (h *EventHandler) handleGetEventStaff(c echo.Context) error {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid event id"})
+	}
+
+	staffList, err := h.eventService.GetEventStaff(c.Request().Context(), c.Get("company_id").(int), eventID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       staffList,
+		Error:      nil,
+	})
+}
+
+// handleAssignEventStaff assigns a company member to an event.
+//
+//	@Summary		Assign event staff
+//	@Description	Assigns a member of the company to an event, replacing their permissions when already assigned.
+//	@Tags			events
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Authorization	header		string					true	"Bearer Token"
+//	@Param			id				path		int						true	"Event ID"
+//	@Param			userId			path		int						true	"User ID"
+//	@Param			request			body		AssignEventStaffRequest	true	"Granted permissions"
+//	@Success		200				{object}	Response
+//	@Failure		400				{object}	Response	"Bad Request"
+//	@Failure		404				{object}	Response	"Member Not Found"
+//	@Failure		500				{object}	Response	"Internal Server Error"
+//	@Router			/events/{id}/staff/{userId} [put]
+func (h *EventHandler) handleAssignEventStaff(c echo.Context) error {
+	var request AssignEventStaffRequest
+
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid event id"})
+	}
+
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid user id"})
+	}
+
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	permissions := []entity.Permission{}
+	for _, permission := range request.Permissions {
+		permissions = append(permissions, entity.Permission(permission))
+	}
+
+	if err := h.eventService.AssignEventStaff(c.Request().Context(), c.Get("company_id").(int), eventID, userID, permissions); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "ok"})
+}
+
+// handleRemoveEventStaff unassigns a staff member from an event.
+//
+//	@Summary		Remove event staff
+//	@Description	Unassigns a staff member from an event.
+//	@Tags			events
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			Authorization	header		string	true	"Bearer Token"
+//	@Param			id				path		int		true	"Event ID"
+//	@Param			userId			path		int		true	"User ID"
+//	@Success		200				{object}	Response
+//	@Failure		404				{object}	Response	"Staff Not Found"
+//	@Failure		500				{object}	Response	"Internal Server Error"
+//	@Router			/events/{id}/staff/{userId} [delete]
+func (h *EventHandler) handleRemoveEventStaff(c echo.Context) error {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid event id"})
+	}
+
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid user id"})
+	}
+
+	if err := h.eventService.RemoveEventStaff(c.Request().Context(), c.Get("company_id").(int), eventID, userID); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "ok"})
+}
+
+// eventPreconditionFailed answers a change made to an outdated version of an event with the current event and its ETag.
+func (h *EventHandler) eventPreconditionFailed(c echo.Context, companyID, eventID int, cause error) error {
+	event, err := h.eventService.GetEvent(c.Request().Context(), companyID, eventID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	setETag(c, strconv.Itoa(event.Version))
+
+	return c.JSON(http.StatusPreconditionFailed, Response{
+		StatusCode: http.StatusPreconditionFailed,
+		Message:    "this event was changed in the meantime, please review it and try again",
+		Data:       EventResponseFromEntity(*event),
+		Error:      cause,
+	})
+}
+
+// guestListPreconditionFailed answers a change made to an outdated version of the guest list of an event
+// with the first page of the current guest list and its ETag.
+func (h *EventHandler) guestListPreconditionFailed(c echo.Context, companyID, eventID int, cause error) error {
+	ctx := c.Request().Context()
+
+	version, err := h.eventService.GetGuestListVersion(ctx, companyID, eventID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	page, err := h.eventService.GetGuests(ctx, companyID, eventID, entity.GuestListFilter{}, "", defaultGuestsPerPage)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	setETag(c, version)
+
+	return c.JSON(http.StatusPreconditionFailed, Response{
+		StatusCode: http.StatusPreconditionFailed,
+		Message:    "the guests of this event were changed in the meantime, please review them and try again",
+		Data:       GuestPageResponseFromEntity(page),
+		Error:      cause,
+	})
+}
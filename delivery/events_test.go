@@ -0,0 +1,130 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+const (
+	testCompanyID    = 1
+	testOwnEventID   = 10
+	testOtherEventID = 20
+)
+
+// fakeJwtGenerator accepts any access token as one of an organizer of testCompanyID.
+type fakeJwtGenerator struct{}
+
+func (fakeJwtGenerator) ParseToken(string) (*pkg.TokenClaims, error) {
+	return &pkg.TokenClaims{ID: 5, CompanyID: testCompanyID, Email: "organizer@example.com", Role: entity.UserRoleEOOrganizer}, nil
+}
+
+type fakeUserRepository struct{}
+
+func (fakeUserRepository) FindByEmail(_ context.Context, email string) (*entity.User, error) {
+	verifiedAt := time.Now()
+	return &entity.User{ID: 5, Email: email, EmailVerifiedAt: &verifiedAt}, nil
+}
+
+func (fakeUserRepository) FindCompanyMembership(_ context.Context, userID, companyID int) (*entity.CompanyMembership, error) {
+	return &entity.CompanyMembership{UserID: userID, CompanyID: companyID, Role: entity.UserRoleEOOrganizer}, nil
+}
+
+type fakeCompanyRepository struct{}
+
+func (fakeCompanyRepository) FindByID(_ context.Context, id int) (*entity.Company, error) {
+	return &entity.Company{ID: id}, nil
+}
+
+type fakePermissionChecker struct{}
+
+func (fakePermissionChecker) GetRolePermissions(_ context.Context, _ int, role entity.UserRole) ([]entity.Permission, error) {
+	return entity.DefaultRolePermissions[role], nil
+}
+
+// fakeEventService only knows testOwnEventID, of testCompanyID, and reports any other event as not found,
+// like the service does for the events of other companies. The methods not used by the tests are left to
+// the embedded interface and panic when called.
+type fakeEventService struct {
+	EventService
+}
+
+func (fakeEventService) GetEvent(_ context.Context, companyID, eventID int) (*entity.Event, error) {
+	if companyID != testCompanyID || eventID != testOwnEventID {
+		return nil, entity.ErrEventNotFound
+	}
+
+	return &entity.Event{ID: eventID, Title: "own event", Company: entity.IDName{ID: companyID}, Version: 1}, nil
+}
+
+func (s fakeEventService) GetGuestListVersion(ctx context.Context, companyID, eventID int) (string, error) {
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return "", err
+	}
+
+	return "1", nil
+}
+
+func (s fakeEventService) GetGuests(ctx context.Context, companyID, eventID int, _ entity.GuestListFilter, _ string, _ int) (entity.GuestPage, error) {
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return entity.GuestPage{}, err
+	}
+
+	return entity.GuestPage{}, nil
+}
+
+func newTestEventServer() *echo.Echo {
+	middleware := NewMiddleware(fakeJwtGenerator{}, fakeUserRepository{}, fakeCompanyRepository{}, nil, nil, fakePermissionChecker{}, nil, false)
+
+	e := echo.New()
+	NewEventHandler(fakeEventService{}).RegisterEventRoutes(e.Group("api/v1/events"), middleware)
+
+	return e
+}
+
+func TestEventRoutesRefuseEventsOfOtherCompanies(t *testing.T) {
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "own event", path: "/api/v1/events/10", expectedStatus: http.StatusOK},
+		{name: "event of another company", path: "/api/v1/events/20", expectedStatus: http.StatusNotFound},
+		{name: "guests of an event of another company", path: "/api/v1/events/20/guests", expectedStatus: http.StatusNotFound},
+	}
+
+	e := newTestEventServer()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer access-token")
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.expectedStatus, rec.Code, rec.Body.String())
+			}
+
+			if tc.expectedStatus != http.StatusNotFound {
+				return
+			}
+
+			var response Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if response.Data != "EVENT_NOT_FOUND" {
+				t.Errorf("expected code EVENT_NOT_FOUND, got %v", response.Data)
+			}
+		})
+	}
+}
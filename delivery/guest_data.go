@@ -0,0 +1,177 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/xuri/excelize/v2"
+)
+
+// guestDataSheet is the name of the sheet of the XLSX export.
+const guestDataSheet = "Guests"
+
+// GuestDataService defines the service interface for exporting and erasing the personal data of guests.
+type GuestDataService interface {
+	ExportGuestData(ctx context.Context, companyID int, contact entity.GuestContact) ([]entity.GuestPersonalData, error)
+	EraseGuestData(ctx context.Context, companyID int, contact entity.GuestContact) (int, error)
+}
+
+// GuestDataHandler handles HTTP requests related to the personal data of guests.
+type GuestDataHandler struct {
+	guestDataService GuestDataService
+}
+
+// NewGuestDataHandler creates a new instance of GuestDataHandler.
+func NewGuestDataHandler(guestDataService GuestDataService) *GuestDataHandler {
+	return &GuestDataHandler{guestDataService: guestDataService}
+}
+
+// RegisterGuestDataRoutes registers the guest personal data related routes within the Echo router group.
+// The contact is given in the body rather than the url, so it does not end up in access logs.
+func (h *GuestDataHandler) RegisterGuestDataRoutes(e *echo.Group, middleware *Middleware) {
+	privacyManagers := []entity.Permission{entity.PermissionGuestPrivacy}
+
+	e.POST("/export", middleware.PermissionMiddleware(privacyManagers, h.handleExportGuestData))
+	e.POST("/erase", middleware.PermissionMiddleware(privacyManagers, h.handleEraseGuestData))
+}
+
+// handleExportGuestData exports everything stored about a guest across the events of the caller's company.
+//
+//	@Summary		Export the personal data of a guest
+//	@Description	Exports every guest record of the caller's company matching the email address or the phone number, as JSON or as an XLSX file.
+//	@Tags			guest-data
+//	@Accept			json
+//	@Produce		json
+//	@Produce		application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+//	@Security		BearerAuth
+//	@Param			request	body		GuestDataRequest	true	"Guest contact payload"
+//	@Success		200		{object}	Response{data=[]entity.GuestPersonalData}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		403		{object}	Response	"Forbidden"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/guest-data/export [post]
+func (h *GuestDataHandler) handleExportGuestData(c echo.Context) error {
+	var request GuestDataRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	if request.Format != "" && request.Format != "json" && request.Format != "xlsx" {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid format, expected json or xlsx"})
+	}
+
+	records, err := h.guestDataService.ExportGuestData(c.Request().Context(), c.Get("company_id").(int), entity.GuestContact{
+		Email: request.Email,
+		Phone: request.Phone,
+	})
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	if request.Format == "xlsx" {
+		return writeGuestDataXLSX(c, records)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("%d guest records found", len(records)),
+		Data:       records,
+		Error:      nil,
+	})
+}
+
+// handleEraseGuestData anonymises a guest across the events of the caller's company.
+//
+//	@Summary		Erase the personal data of a guest
+//	@Description	Anonymises every guest record of the caller's company matching the email address or the phone number.
+//	@Description	The name, email address, phone number and message are erased, check-in and attendance are kept.
+//	@Tags			guest-data
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		GuestDataRequest	true	"Guest contact payload"
+//	@Success		200		{object}	Response{data=GuestDataErasureResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		403		{object}	Response	"Forbidden"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/guest-data/erase [post]
+func (h *GuestDataHandler) handleEraseGuestData(c echo.Context) error {
+	var request GuestDataRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	anonymisedGuests, err := h.guestDataService.EraseGuestData(c.Request().Context(), c.Get("company_id").(int), entity.GuestContact{
+		Email: request.Email,
+		Phone: request.Phone,
+	})
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("%d guest records anonymised", anonymisedGuests),
+		Data:       GuestDataErasureResponse{AnonymisedGuests: anonymisedGuests},
+		Error:      nil,
+	})
+}
+
+// writeGuestDataXLSX answers with the guest records as an XLSX file, one row per record.
+func writeGuestDataXLSX(c echo.Context, records []entity.GuestPersonalData) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	if err := file.SetSheetName("Sheet1", guestDataSheet); err != nil {
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	header := []any{"Guest ID", "Event ID", "Event", "Event Start", "Name", "Email", "Phone", "VIP", "Attending", "Message", "Checked In", "Checked In At", "Barcode"}
+	if err := file.SetSheetRow(guestDataSheet, "A1", &header); err != nil {
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	for i, record := range records {
+		checkedInAt := ""
+		if record.CheckedInAt != nil {
+			checkedInAt = record.CheckedInAt.Format(time.RFC3339)
+		}
+
+		row := []any{
+			record.GuestID,
+			record.EventID,
+			record.EventTitle,
+			record.EventStartDate.Format(time.RFC3339),
+			record.Name,
+			record.Email,
+			record.Phone,
+			record.IsVIP,
+			record.IsAttending,
+			record.Message,
+			record.CheckedIn,
+			checkedInAt,
+			record.BarcodeID,
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		}
+
+		if err := file.SetSheetRow(guestDataSheet, cell, &row); err != nil {
+			return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		}
+	}
+
+	content, err := file.WriteToBuffer()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="guest-data.xlsx"`)
+	return c.Blob(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content.Bytes())
+}
@@ -0,0 +1,14 @@
+package delivery
+
+// GuestDataRequest represents the email address or phone number identifying a person among the guests of the company.
+// Format is either "json", the default, or "xlsx" and is only used by the export.
+type GuestDataRequest struct {
+	Email  string `json:"email"`
+	Phone  string `json:"phone"`
+	Format string `json:"format"`
+}
+
+// GuestDataErasureResponse represents the result of the erasure of the personal data of a guest.
+type GuestDataErasureResponse struct {
+	AnonymisedGuests int `json:"anonymised_guests"`
+}
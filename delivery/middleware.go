@@ -2,12 +2,18 @@ package delivery
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
 	"github.com/mhdiiilham/gosm/pkg"
 )
 
@@ -19,39 +25,215 @@ type JwtGenerator interface {
 // UserRepository defines an interface for user-related database operations.
 type UserRepository interface {
 	FindByEmail(ct context.Context, email string) (existingUser *entity.User, err error)
+	FindCompanyMembership(ctx context.Context, userID, companyID int) (*entity.CompanyMembership, error)
+}
+
+// CompanyRepository defines an interface for looking up the company of an authenticated user.
+type CompanyRepository interface {
+	FindByID(ctx context.Context, ID int) (*entity.Company, error)
+}
+
+// SessionTracker defines an interface for checking the session of an access token was not revoked.
+type SessionTracker interface {
+	TouchSession(ctx context.Context, sessionID int) (bool, error)
+}
+
+// EventStaffRepository defines an interface for looking up the staff assignment of an event.
+type EventStaffRepository interface {
+	GetEventStaff(ctx context.Context, eventID, userID int) (*entity.EventStaff, error)
+}
+
+// PermissionChecker defines an interface for resolving the permissions granted to a role of a company.
+type PermissionChecker interface {
+	GetRolePermissions(ctx context.Context, companyID int, role entity.UserRole) ([]entity.Permission, error)
+}
+
+// APIKeyAuthenticator defines an interface for authenticating requests made with an API key.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*entity.APIKey, error)
 }
 
 // Middleware provides authentication-related middleware functions.
 type Middleware struct {
-	jwtService     JwtGenerator
-	userRepository UserRepository
+	jwtService               JwtGenerator
+	userRepository           UserRepository
+	companyRepository        CompanyRepository
+	sessionTracker           SessionTracker
+	eventStaffRepository     EventStaffRepository
+	permissionChecker        PermissionChecker
+	apiKeyAuthenticator      APIKeyAuthenticator
+	requireEmailVerification bool
 }
 
 var (
-	// AllowedSuperAdminOnly only allow super admin to access this endpoint.
-	AllowedSuperAdminOnly = []entity.UserRole{entity.UserRoleSuperAdmin}
-
 	// AllowedAuthenticatedOnly only allow requet with valid access token.
 	AllowedAuthenticatedOnly = []entity.UserRole{
 		entity.UserRoleSuperAdmin,
 		entity.UserRoleEOOrganizer,
+		entity.UserRoleCrew,
 		entity.UserRoleHost,
 		entity.UserRoleGuest,
 	}
+
+	// AllowedAuthenticatedOrAPIKey allow request with valid access token or a valid API key.
+	AllowedAuthenticatedOrAPIKey = append(slices.Clone(AllowedAuthenticatedOnly), entity.UserRoleAPIKey)
 )
 
-// NewMiddleware initializes a new Middleware instance with the provided JWT service and user repository.
-func NewMiddleware(jwtService JwtGenerator, userRepository UserRepository) *Middleware {
-	return &Middleware{jwtService: jwtService, userRepository: userRepository}
+// NewMiddleware initializes a new Middleware instance with the provided JWT service and repositories.
+// When requireEmailVerification is true, users who have not verified their email address are refused.
+// Admins without two-factor authentication are refused when their company requires it,
+// and access tokens are refused as soon as their session is revoked.
+func NewMiddleware(
+	jwtService JwtGenerator,
+	userRepository UserRepository,
+	companyRepository CompanyRepository,
+	sessionTracker SessionTracker,
+	eventStaffRepository EventStaffRepository,
+	permissionChecker PermissionChecker,
+	apiKeyAuthenticator APIKeyAuthenticator,
+	requireEmailVerification bool,
+) *Middleware {
+	return &Middleware{
+		jwtService:               jwtService,
+		userRepository:           userRepository,
+		companyRepository:        companyRepository,
+		sessionTracker:           sessionTracker,
+		eventStaffRepository:     eventStaffRepository,
+		permissionChecker:        permissionChecker,
+		apiKeyAuthenticator:      apiKeyAuthenticator,
+		requireEmailVerification: requireEmailVerification,
+	}
+}
+
+// NewIPExtractor returns how echo finds the IP address of the client, used by c.RealIP() to throttle and audit requests.
+// Without trustedProxies the address of the connection is used and the X-Forwarded-For header, which any client can
+// set, is ignored. Otherwise the header is only read from the proxies of the given CIDR ranges.
+func NewIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, trustedProxy := range trustedProxies {
+		_, ipRange, err := net.ParseCIDR(trustedProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy range %q: %w", trustedProxy, err)
+		}
+
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// RequestContextMiddleware attaches the request ID, the IP address and the user agent of the client to the request context,
+// so logs and audit logs can be traced back to the request. The request ID is the one set by echo's RequestID
+// middleware, it has to run before this one.
+func RequestContextMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+
+		ctx := context.WithValue(c.Request().Context(), logger.RequestIDKey, requestID)
+		ctx = pkg.WithRequestMetadata(ctx, pkg.RequestMetadata{IP: c.RealIP(), UserAgent: c.Request().UserAgent(), RequestID: requestID})
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
 }
 
 // AuthMiddleware is a middleware function that handles authentication and authorization.
 // It verifies the JWT token from the Authorization header and checks if the user has the required role.
+// An API key, given in the X-API-Key header or as the Bearer token, is accepted instead of a JWT
+// when allowedRoles contains entity.UserRoleAPIKey.
 func (m *Middleware) AuthMiddleware(allowedRoles []entity.UserRole, next echo.HandlerFunc) echo.HandlerFunc {
+	return m.authenticate(allowedRoles, false, next)
+}
+
+// UnverifiedAuthMiddleware behaves like AuthMiddleware but lets users with an unverified email address,
+// or admins who still have to enrol in two-factor authentication, through.
+// It is meant for the endpoints those users need to complete their account, such as resending the verification link.
+func (m *Middleware) UnverifiedAuthMiddleware(allowedRoles []entity.UserRole, next echo.HandlerFunc) echo.HandlerFunc {
+	return m.authenticate(allowedRoles, true, next)
+}
+
+// PermissionMiddleware authenticates the request and checks the role of the user, or the scopes of the API key,
+// grant every required permission within their company.
+func (m *Middleware) PermissionMiddleware(requiredPermissions []entity.Permission, next echo.HandlerFunc) echo.HandlerFunc {
+	return m.AuthMiddleware(AllowedAuthenticatedOrAPIKey, func(c echo.Context) error {
+		for _, permission := range requiredPermissions {
+			if !hasPermission(c, permission) {
+				return c.JSON(http.StatusForbidden, Response{
+					StatusCode: http.StatusForbidden,
+					Message:    "you are not allowed to perform this action",
+					Data:       "PERMISSION_DENIED",
+					Error:      nil,
+				})
+			}
+		}
+
+		return next(c)
+	})
+}
+
+// EventPermissionMiddleware authenticates the request and checks the user is allowed to act on the event
+// identified by the "id" path parameter. Users granted the permission company-wide are allowed on every event,
+// other users need to be assigned to the event with the given permission.
+// Any assignment is enough for entity.PermissionEventView. API keys are never assigned to events.
+func (m *Middleware) EventPermissionMiddleware(permission entity.Permission, next echo.HandlerFunc) echo.HandlerFunc {
+	return m.AuthMiddleware(AllowedAuthenticatedOrAPIKey, func(c echo.Context) error {
+		if hasPermission(c, permission) {
+			return next(c)
+		}
+
+		if c.Get("user_role") == entity.UserRoleAPIKey {
+			return c.JSON(http.StatusForbidden, Response{
+				StatusCode: http.StatusForbidden,
+				Message:    "you are not allowed to perform this action on this event",
+				Data:       "EVENT_PERMISSION_DENIED",
+				Error:      nil,
+			})
+		}
+
+		eventID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid event id"})
+		}
+
+		staff, err := m.eventStaffRepository.GetEventStaff(c.Request().Context(), eventID, c.Get("user_id").(int))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return c.JSON(http.StatusNotFound, Response{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("event %d not found", eventID)})
+			}
+
+			return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		}
+
+		if permission != entity.PermissionEventView && !staff.Can(permission) {
+			return c.JSON(http.StatusForbidden, Response{
+				StatusCode: http.StatusForbidden,
+				Message:    "you are not allowed to perform this action on this event",
+				Data:       "EVENT_PERMISSION_DENIED",
+				Error:      nil,
+			})
+		}
+
+		return next(c)
+	})
+}
+
+func (m *Middleware) authenticate(allowedRoles []entity.UserRole, allowIncomplete bool, next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		authHeader := c.Request().Header.Get("Authorization")
 		authHeader = strings.ReplaceAll(authHeader, "Bearer ", "")
 
+		if apiKey := c.Request().Header.Get("X-API-Key"); apiKey != "" {
+			return m.authenticateAPIKey(c, apiKey, allowedRoles, next)
+		}
+
+		if strings.HasPrefix(authHeader, pkg.APIKeyPrefix) {
+			return m.authenticateAPIKey(c, authHeader, allowedRoles, next)
+		}
+
 		if authHeader == "" {
 			return c.JSON(http.StatusUnauthorized, Response{StatusCode: http.StatusUnauthorized, Message: "Request could not be authorised"})
 		}
@@ -60,7 +242,7 @@ func (m *Middleware) AuthMiddleware(allowedRoles []entity.UserRole, next echo.Ha
 		if err != nil {
 			switch parsedErr := err.(type) {
 			case entity.GosmError:
-				if parsedErr.Code == "AUTH_TOKEN_EXPIRED" {
+				if parsedErr.Type == entity.GosmErrorTypeBadRequest {
 					return c.JSON(http.StatusUnauthorized, Response{
 						StatusCode: http.StatusUnauthorized,
 						Message:    parsedErr.Message,
@@ -75,11 +257,80 @@ func (m *Middleware) AuthMiddleware(allowedRoles []entity.UserRole, next echo.Ha
 		ctx := c.Request().Context()
 		email := claims.Email
 		role := claims.Role
+		var company *entity.Company
 
-		if _, err := m.userRepository.FindByEmail(ctx, email); err != nil {
+		user, err := m.userRepository.FindByEmail(ctx, email)
+		if err != nil {
 			return c.JSON(http.StatusUnauthorized, Response{StatusCode: http.StatusUnauthorized, Message: "Request could not be authorised"})
 		}
 
+		// tokens issued before the latest password change are no longer valid.
+		if user.PasswordChangedAt != nil && claims.IssuedAt < user.PasswordChangedAt.Unix() {
+			return c.JSON(http.StatusUnauthorized, Response{
+				StatusCode: http.StatusUnauthorized,
+				Message:    "provided access token is expired",
+				Data:       "AUTH_TOKEN_EXPIRED",
+				Error:      nil,
+			})
+		}
+
+		// tokens issued before sessions were tracked, and impersonation tokens, have no session.
+		if claims.SessionID != 0 {
+			active, err := m.sessionTracker.TouchSession(ctx, claims.SessionID)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+			}
+
+			if !active {
+				return c.JSON(http.StatusUnauthorized, Response{
+					StatusCode: http.StatusUnauthorized,
+					Message:    "this session was signed out, please sign in again",
+					Data:       "AUTH_SESSION_REVOKED",
+					Error:      nil,
+				})
+			}
+		}
+
+		// the role comes from the membership, so role changes apply without waiting for a new token.
+		if claims.CompanyID != 0 {
+			membership, err := m.userRepository.FindCompanyMembership(ctx, user.ID, claims.CompanyID)
+			if err != nil {
+				// the user was removed from the company the token was issued for.
+				if errors.Is(err, sql.ErrNoRows) {
+					return c.JSON(http.StatusUnauthorized, Response{StatusCode: http.StatusUnauthorized, Message: "Request could not be authorised"})
+				}
+				return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+			}
+			role = membership.Role
+
+			if company, err = m.companyRepository.FindByID(ctx, claims.CompanyID); err != nil {
+				return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+			}
+
+			// operators impersonating an admin are let through, to investigate the suspended company.
+			if company.IsSuspended() && claims.ImpersonatorID == 0 {
+				return companySuspendedResponse(c)
+			}
+		}
+
+		if m.requireEmailVerification && !allowIncomplete && !user.IsEmailVerified() {
+			return c.JSON(http.StatusForbidden, Response{
+				StatusCode: http.StatusForbidden,
+				Message:    "please verify your email address first",
+				Data:       "AUTH_EMAIL_NOT_VERIFIED",
+				Error:      nil,
+			})
+		}
+
+		if !allowIncomplete && slices.Contains(entity.AdminRoles, role) && !user.IsTwoFactorEnabled() && company != nil && company.RequireAdminTwoFactor {
+			return c.JSON(http.StatusForbidden, Response{
+				StatusCode: http.StatusForbidden,
+				Message:    "your company requires admins to use two-factor authentication, please enable it first",
+				Data:       "AUTH_2FA_ENROLMENT_REQUIRED",
+				Error:      nil,
+			})
+		}
+
 		if !slices.Contains(allowedRoles, role) {
 			return c.JSON(http.StatusUnauthorized, Response{StatusCode: http.StatusUnauthorized, Message: "Request could not be authorised"})
 		}
@@ -87,7 +338,112 @@ func (m *Middleware) AuthMiddleware(allowedRoles []entity.UserRole, next echo.Ha
 		c.Set("user_id", claims.ID)
 		c.Set("user_email", email)
 		c.Set("company_id", claims.CompanyID)
+		c.Set("user_role", role)
+
+		permissions, err := m.permissionChecker.GetRolePermissions(ctx, claims.CompanyID, role)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+		}
+		c.Set("user_permissions", permissions)
+		if claims.ImpersonatorID != 0 {
+			c.Set("impersonator_id", claims.ImpersonatorID)
+		}
+		if claims.SessionID != 0 {
+			c.Set("session_id", claims.SessionID)
+		}
+		setRequestActor(c, claims.ID, claims.CompanyID, 0, claims.ImpersonatorID)
 
 		return next(c)
 	}
 }
+
+func (m *Middleware) authenticateAPIKey(c echo.Context, key string, allowedRoles []entity.UserRole, next echo.HandlerFunc) error {
+	if !slices.Contains(allowedRoles, entity.UserRoleAPIKey) {
+		return c.JSON(http.StatusUnauthorized, Response{StatusCode: http.StatusUnauthorized, Message: "Request could not be authorised"})
+	}
+
+	apiKey, err := m.apiKeyAuthenticator.AuthenticateAPIKey(c.Request().Context(), key)
+	if err != nil {
+		switch parsedErr := err.(type) {
+		case entity.GosmError:
+			if parsedErr.Type == entity.GosmErrorTypeBadRequest {
+				return c.JSON(http.StatusUnauthorized, Response{
+					StatusCode: http.StatusUnauthorized,
+					Message:    parsedErr.Message,
+					Data:       parsedErr.Code,
+					Error:      nil,
+				})
+			}
+		}
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	company, err := m.companyRepository.FindByID(c.Request().Context(), apiKey.CompanyID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	if company.IsSuspended() {
+		return companySuspendedResponse(c)
+	}
+
+	// requests made with an API key are attributed to the user who created the key.
+	c.Set("user_id", apiKey.CreatedBy)
+	c.Set("company_id", apiKey.CompanyID)
+	c.Set("user_role", entity.UserRoleAPIKey)
+	c.Set("user_permissions", apiKey.Scopes)
+	c.Set("api_key_id", apiKey.ID)
+	setRequestActor(c, apiKey.CreatedBy, apiKey.CompanyID, apiKey.ID, 0)
+
+	return next(c)
+}
+
+// NoImpersonationMiddleware refuses requests made by a platform operator impersonating a user.
+// It is meant for the endpoints managing the credentials and sessions of the user, and has to run after authentication.
+func (m *Middleware) NoImpersonationMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Get("impersonator_id") != nil {
+			return c.JSON(http.StatusForbidden, Response{
+				StatusCode: http.StatusForbidden,
+				Message:    "this action is not allowed while impersonating a user",
+				Data:       "IMPERSONATION_NOT_ALLOWED",
+				Error:      nil,
+			})
+		}
+
+		return next(c)
+	}
+}
+
+// companySuspendedResponse refuses a request made on behalf of a suspended company.
+func companySuspendedResponse(c echo.Context) error {
+	return c.JSON(http.StatusForbidden, Response{
+		StatusCode: http.StatusForbidden,
+		Message:    "this company is suspended, please contact support",
+		Data:       "COMPANY_SUSPENDED",
+		Error:      nil,
+	})
+}
+
+// setRequestActor adds the authenticated user, their company, the API key used and the impersonating operator,
+// if any, to the request metadata.
+func setRequestActor(c echo.Context, userID, companyID, apiKeyID, impersonatorID int) {
+	ctx := c.Request().Context()
+
+	metadata := pkg.RequestMetadataFromContext(ctx)
+	metadata.ActorID = userID
+	metadata.CompanyID = companyID
+	metadata.APIKeyID = apiKeyID
+	metadata.ImpersonatorID = impersonatorID
+	if metadata.IP == "" {
+		metadata.IP = c.RealIP()
+	}
+
+	c.SetRequest(c.Request().WithContext(pkg.WithRequestMetadata(ctx, metadata)))
+}
+
+// hasPermission returns true when the authenticated user was granted the permission company-wide.
+func hasPermission(c echo.Context, permission entity.Permission) bool {
+	permissions, _ := c.Get("user_permissions").([]entity.Permission)
+	return slices.Contains(permissions, permission)
+}
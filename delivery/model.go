@@ -1,6 +1,12 @@
 package delivery
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+)
 
 // Response represents a standard API response structure.
 // It includes the status code, message, optional data, and an error message (if any).
@@ -21,3 +27,35 @@ func throwInternalServerError(err error) Response {
 		Error:      err,
 	}
 }
+
+// throwServiceError writes the Response matching the given service error.
+// Client-side GosmError are returned with their status and code, anything else is an internal server error.
+func throwServiceError(c echo.Context, serviceErr error) error {
+	var gosmErr entity.GosmError
+	if errors.As(serviceErr, &gosmErr) {
+		statusCode := 0
+		switch gosmErr.Type {
+		case entity.GosmErrorTypeBadRequest:
+			statusCode = http.StatusBadRequest
+		case entity.GosmErrorTypeNotFound:
+			statusCode = http.StatusNotFound
+		case entity.GosmErrorTypeTooMany:
+			statusCode = http.StatusTooManyRequests
+		case entity.GosmErrorTypeQuota:
+			statusCode = http.StatusPaymentRequired
+		case entity.GosmErrorTypePrecondition:
+			statusCode = http.StatusPreconditionFailed
+		}
+
+		if statusCode != 0 {
+			return c.JSON(statusCode, Response{
+				StatusCode: statusCode,
+				Message:    gosmErr.Message,
+				Data:       gosmErr.Code,
+				Error:      gosmErr.Source,
+			})
+		}
+	}
+
+	return c.JSON(http.StatusInternalServerError, throwInternalServerError(serviceErr))
+}
@@ -0,0 +1,126 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+// PermissionService defines the service interface for customising the permissions granted to each role of a company.
+type PermissionService interface {
+	GetCompanyRolePermissions(ctx context.Context, companyID int) (map[entity.UserRole][]entity.Permission, error)
+	UpdateRolePermissions(ctx context.Context, companyID int, actorRole, role entity.UserRole, permissions []entity.Permission) error
+	ResetRolePermissions(ctx context.Context, companyID int, actorRole, role entity.UserRole) error
+}
+
+// PermissionHandler handles HTTP requests related to role permissions.
+type PermissionHandler struct {
+	permissionService PermissionService
+}
+
+// NewPermissionHandler creates a new instance of PermissionHandler.
+func NewPermissionHandler(permissionService PermissionService) *PermissionHandler {
+	return &PermissionHandler{permissionService: permissionService}
+}
+
+// RegisterPermissionRoutes registers the permission-related routes within the Echo router group.
+func (h *PermissionHandler) RegisterPermissionRoutes(e *echo.Group, middleware *Middleware) {
+	roleManagers := []entity.Permission{entity.PermissionRoleManage}
+
+	e.GET("", middleware.PermissionMiddleware(roleManagers, h.handleGetRolePermissions))
+	e.PUT("/roles/:role", middleware.PermissionMiddleware(roleManagers, h.handleUpdateRolePermissions))
+	e.DELETE("/roles/:role", middleware.PermissionMiddleware(roleManagers, h.handleResetRolePermissions))
+}
+
+// handleGetRolePermissions lists the permission registry and the permissions granted to each role.
+//
+//	@Summary		List role permissions
+//	@Description	Lists every known permission and the permissions granted to each role of the caller's company.
+//	@Tags			permissions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	Response{data=RolePermissionsResponse}
+//	@Failure		403	{object}	Response	"Forbidden"
+//	@Failure		500	{object}	Response	"Internal Server Error"
+//	@Router			/permissions [get]
+func (h *PermissionHandler) handleGetRolePermissions(c echo.Context) error {
+	rolePermissions, err := h.permissionService.GetCompanyRolePermissions(c.Request().Context(), c.Get("company_id").(int))
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data: RolePermissionsResponse{
+			Permissions: entity.Permissions,
+			Roles:       rolePermissions,
+		},
+		Error: nil,
+	})
+}
+
+// handleUpdateRolePermissions customises the permissions granted to a role.
+//
+//	@Summary		Customise role permissions
+//	@Description	Replaces the permissions granted to a role of the caller's company.
+//	@Tags			permissions
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			role	path		string							true	"Role"
+//	@Param			request	body		UpdateRolePermissionsRequest	true	"Granted permissions"
+//	@Success		200		{object}	Response
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		403		{object}	Response	"Forbidden"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/permissions/roles/{role} [put]
+func (h *PermissionHandler) handleUpdateRolePermissions(c echo.Context) error {
+	var request UpdateRolePermissionsRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	role := entity.UserRole(c.Param("role"))
+	if err := h.permissionService.UpdateRolePermissions(
+		c.Request().Context(),
+		c.Get("company_id").(int),
+		c.Get("user_role").(entity.UserRole),
+		role,
+		request.Permissions,
+	); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: fmt.Sprintf("permissions of %s updated", role)})
+}
+
+// handleResetRolePermissions restores the default permissions of a role.
+//
+//	@Summary		Reset role permissions
+//	@Description	Restores the default permissions of a role of the caller's company.
+//	@Tags			permissions
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			role	path		string	true	"Role"
+//	@Success		200		{object}	Response
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		403		{object}	Response	"Forbidden"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/permissions/roles/{role} [delete]
+func (h *PermissionHandler) handleResetRolePermissions(c echo.Context) error {
+	role := entity.UserRole(c.Param("role"))
+	if err := h.permissionService.ResetRolePermissions(
+		c.Request().Context(),
+		c.Get("company_id").(int),
+		c.Get("user_role").(entity.UserRole),
+		role,
+	); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: fmt.Sprintf("permissions of %s reset", role)})
+}
@@ -0,0 +1,14 @@
+package delivery
+
+import "github.com/mhdiiilham/gosm/entity"
+
+// UpdateRolePermissionsRequest represents the payload required to customise the permissions of a role.
+type UpdateRolePermissionsRequest struct {
+	Permissions []entity.Permission `json:"permissions"`
+}
+
+// RolePermissionsResponse represents the permission registry and the permissions granted to each role of a company.
+type RolePermissionsResponse struct {
+	Permissions []entity.Permission                     `json:"permissions"`
+	Roles       map[entity.UserRole][]entity.Permission `json:"roles"`
+}
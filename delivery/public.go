@@ -94,7 +94,7 @@ func AddGuestToEvent(srv EventService) echo.HandlerFunc {
 
 		if request.ID != "" {
 			if err := srv.UpdateGuest(c.Request().Context(), request.ID, request.Name, pkg.FormatPhoneToWaMe(request.Phone), request.Message, request.IsAttending); err != nil {
-				return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+				return throwServiceError(c, err)
 			}
 
 			return c.JSON(http.StatusOK, Response{
@@ -106,19 +106,16 @@ func AddGuestToEvent(srv EventService) echo.HandlerFunc {
 		}
 
 		barcodeID, _ := pkg.GeneratePumBookID(strconv.Itoa(eventID))
-		_, err := srv.AddGuests(c.Request().Context(), eventID, []entity.Guest{
-			{
-
-				EventID:     eventID,
-				BarcodeID:   barcodeID,
-				Name:        request.Name,
-				Phone:       pkg.FormatPhoneToWaMe(request.Phone),
-				IsAttending: request.IsAttending,
-				Message:     request.Message,
-			},
+		err := srv.RegisterGuest(c.Request().Context(), eventID, entity.Guest{
+			EventID:     eventID,
+			BarcodeID:   barcodeID,
+			Name:        request.Name,
+			Phone:       pkg.FormatPhoneToWaMe(request.Phone),
+			IsAttending: request.IsAttending,
+			Message:     request.Message,
 		})
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+			return throwServiceError(c, err)
 		}
 
 		return c.JSON(http.StatusOK, Response{
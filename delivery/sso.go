@@ -0,0 +1,208 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/AlekSi/pointer"
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// SSOService defines the service interface for single sign-on.
+type SSOService interface {
+	GetConfig(ctx context.Context, companyID int) (*entity.CompanySSOConfig, error)
+	UpdateConfig(ctx context.Context, companyID int, config entity.CompanySSOConfig) (*entity.CompanySSOConfig, error)
+	DeleteConfig(ctx context.Context, companyID int) error
+	StartLogin(ctx context.Context, companyID int) (string, error)
+	CompleteLogin(ctx context.Context, state, code string) (user *entity.User, company *entity.Company, authResponse *entity.AuthResponse, err error)
+}
+
+// SSOHandler handles HTTP requests related to single sign-on.
+type SSOHandler struct {
+	ssoService SSOService
+}
+
+// NewSSOHandler creates a new instance of SSOHandler.
+func NewSSOHandler(ssoService SSOService) *SSOHandler {
+	return &SSOHandler{ssoService: ssoService}
+}
+
+// RegisterSSORoutes registers the single sign-on routes within the Echo router group.
+func (h *SSOHandler) RegisterSSORoutes(e *echo.Group, middleware *Middleware) {
+	ssoManagers := []entity.Permission{entity.PermissionTeamManage}
+
+	e.GET("/config", middleware.PermissionMiddleware(ssoManagers, h.handleGetConfig))
+	e.PUT("/config", middleware.PermissionMiddleware(ssoManagers, h.handleUpdateConfig))
+	e.DELETE("/config", middleware.PermissionMiddleware(ssoManagers, h.handleDeleteConfig))
+
+	// used by the members signing in, who are not authenticated yet.
+	e.GET("/companies/:companyId/authorize", h.handleAuthorize)
+	e.POST("/callback", h.handleCallback)
+}
+
+// handleGetConfig returns the single sign-on configuration of the caller's company.
+//
+//	@Summary	Get the single sign-on configuration
+//	@Tags		sso
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{object}	Response{data=SSOConfigResponse}
+//	@Failure	404	{object}	Response	"Not Configured"
+//	@Failure	500	{object}	Response	"Internal Server Error"
+//	@Router		/sso/config [get]
+func (h *SSOHandler) handleGetConfig(c echo.Context) error {
+	config, err := h.ssoService.GetConfig(c.Request().Context(), c.Get("company_id").(int))
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "success",
+		Data:       SSOConfigResponseFromEntity(pointer.Get(config)),
+	})
+}
+
+// handleUpdateConfig configures the single sign-on of the caller's company.
+//
+//	@Summary		Configure single sign-on
+//	@Description	Sets the OpenID Connect provider members of the caller's company sign in with. Members signing in for the first time are created with the default role.
+//	@Tags			sso
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		UpdateSSOConfigRequest	true	"Single sign-on configuration"
+//	@Success		200		{object}	Response{data=SSOConfigResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/sso/config [put]
+func (h *SSOHandler) handleUpdateConfig(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "SSOHandler.handleUpdateConfig"
+	var request UpdateSSOConfigRequest
+
+	if err := c.Bind(&request); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	config, err := h.ssoService.UpdateConfig(ctx, c.Get("company_id").(int), entity.CompanySSOConfig{
+		Issuer:       request.Issuer,
+		ClientID:     request.ClientID,
+		ClientSecret: request.ClientSecret,
+		DefaultRole:  request.DefaultRole,
+		Enabled:      request.Enabled,
+	})
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "single sign-on configured",
+		Data:       SSOConfigResponseFromEntity(pointer.Get(config)),
+	})
+}
+
+// handleDeleteConfig removes the single sign-on configuration of the caller's company.
+//
+//	@Summary	Remove single sign-on
+//	@Tags		sso
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{object}	Response	"Single sign-on removed"
+//	@Failure	404	{object}	Response	"Not Configured"
+//	@Failure	500	{object}	Response	"Internal Server Error"
+//	@Router		/sso/config [delete]
+func (h *SSOHandler) handleDeleteConfig(c echo.Context) error {
+	if err := h.ssoService.DeleteConfig(c.Request().Context(), c.Get("company_id").(int)); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "single sign-on removed"})
+}
+
+// handleAuthorize starts a single sign-on for a company.
+//
+//	@Summary		Start a single sign-on
+//	@Description	Returns the identity provider URL of the company the user has to be sent to. The provider sends the user back to the frontend with a code and a state.
+//	@Tags			sso
+//	@Produce		json
+//	@Param			companyId	path		int	true	"Company ID"
+//	@Success		200			{object}	Response{data=SSOAuthorizeResponse}
+//	@Failure		404			{object}	Response	"Not Configured"
+//	@Failure		500			{object}	Response	"Internal Server Error"
+//	@Router			/sso/companies/{companyId}/authorize [get]
+func (h *SSOHandler) handleAuthorize(c echo.Context) error {
+	companyID, err := strconv.Atoi(c.Param("companyId"))
+	if err != nil {
+		return throwServiceError(c, entity.ErrSSONotConfigured)
+	}
+
+	authorizationURL, err := h.ssoService.StartLogin(c.Request().Context(), companyID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "success",
+		Data:       SSOAuthorizeResponse{AuthorizationURL: authorizationURL},
+	})
+}
+
+// handleCallback completes a single sign-on.
+//
+//	@Summary		Complete a single sign-on
+//	@Description	Exchanges the code and state the identity provider sent back for an access token.
+//	@Description	Users with two-factor authentication enabled get a challenge token to complete with their code instead.
+//	@Tags			sso
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		SSOCallbackRequest					true	"Code and state"
+//	@Success		200		{object}	Response{data=AccessTokenResponse}	"User successfully authenticated"
+//	@Failure		400		{object}	Response							"Invalid state or code"
+//	@Failure		500		{object}	Response							"Internal Server Error"
+//	@Router			/sso/callback [post]
+func (h *SSOHandler) handleCallback(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "SSOHandler.handleCallback"
+	var request SSOCallbackRequest
+
+	if err := c.Bind(&request); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	user, company, authResponse, err := h.ssoService.CompleteLogin(ctx, request.State, request.Code)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	companyResponse := CompanyResponseFromEntity(pointer.Get(company))
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "success",
+		Data: AccessTokenResponse{
+			AccessToken:           authResponse.AccessToken,
+			ExpiresAt:             authResponse.ExpiresAt,
+			RefreshToken:          authResponse.RefreshToken,
+			RefreshTokenExpiresAt: authResponse.RefreshTokenExpiresAt,
+			TwoFactorRequired:     authResponse.TwoFactorRequired,
+			ChallengeToken:        authResponse.ChallengeToken,
+			ChallengeExpiresAt:    authResponse.ChallengeExpiresAt,
+			User: UserResponse{
+				ID:       user.ID,
+				Name:     user.GetName(),
+				Email:    user.Email,
+				Phone:    user.PhoneNumber,
+				JobTitle: user.JobTitle,
+				Role:     user.Role,
+			},
+			Company: &companyResponse,
+		},
+	})
+}
@@ -0,0 +1,35 @@
+package delivery
+
+import "github.com/mhdiiilham/gosm/entity"
+
+// UpdateSSOConfigRequest represents the payload required to configure the single sign-on of the caller's company.
+// ClientSecret may be left empty to keep the stored one, or for public clients.
+type UpdateSSOConfigRequest struct {
+	Issuer       string          `json:"issuer"`
+	ClientID     string          `json:"clientId"`
+	ClientSecret string          `json:"clientSecret"`
+	DefaultRole  entity.UserRole `json:"defaultRole"`
+	Enabled      bool            `json:"enabled"`
+}
+
+// SSOConfigResponse represents the single sign-on configuration of a company, without its client secret.
+type SSOConfigResponse struct {
+	entity.CompanySSOConfig
+	HasClientSecret bool `json:"hasClientSecret"`
+}
+
+// SSOConfigResponseFromEntity converts a single sign-on configuration into its response.
+func SSOConfigResponseFromEntity(config entity.CompanySSOConfig) SSOConfigResponse {
+	return SSOConfigResponse{CompanySSOConfig: config, HasClientSecret: config.ClientSecret != ""}
+}
+
+// SSOAuthorizeResponse represents the identity provider URL the user has to be sent to.
+type SSOAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// SSOCallbackRequest represents the payload the identity provider sent back to the frontend.
+type SSOCallbackRequest struct {
+	State string `json:"state"`
+	Code  string `json:"code"`
+}
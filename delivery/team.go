@@ -0,0 +1,412 @@
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/AlekSi/pointer"
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// TeamService defines the service interface for managing the members of a company.
+type TeamService interface {
+	InviteMember(ctx context.Context, companyID, invitedBy int, email, phone string, role entity.UserRole) (*entity.CompanyInvitation, error)
+	ListInvitations(ctx context.Context, companyID int) ([]entity.CompanyInvitation, error)
+	RevokeInvitation(ctx context.Context, companyID, invitationID int) error
+	GetInvitation(ctx context.Context, invitationToken string) (*entity.CompanyInvitation, *entity.Company, error)
+	AcceptInvitation(ctx context.Context, invitationToken string, user entity.User) (*entity.User, *entity.Company, error)
+	JoinCompany(ctx context.Context, invitationToken string, userID int) (*entity.Company, error)
+	ListMembers(ctx context.Context, companyID int) ([]entity.User, error)
+	ChangeMemberRole(ctx context.Context, companyID, actorID, memberID int, role entity.UserRole) error
+	RemoveMember(ctx context.Context, companyID, actorID, memberID int) error
+	SetRequireAdminTwoFactor(ctx context.Context, companyID int, required bool) error
+}
+
+// TeamHandler handles HTTP requests related to company team management.
+type TeamHandler struct {
+	teamService TeamService
+	authService AuthService
+}
+
+// NewTeamHandler creates a new instance of TeamHandler.
+func NewTeamHandler(teamService TeamService, authService AuthService) *TeamHandler {
+	return &TeamHandler{teamService: teamService, authService: authService}
+}
+
+// RegisterTeamRoutes registers the team-related routes within the Echo router group.
+func (h *TeamHandler) RegisterTeamRoutes(e *echo.Group, middleware *Middleware) {
+	teamManagers := []entity.Permission{entity.PermissionTeamManage}
+
+	e.GET("/members", middleware.PermissionMiddleware(teamManagers, h.handleGetMembers))
+	e.PATCH("/members/:id/role", middleware.PermissionMiddleware(teamManagers, h.handleChangeMemberRole))
+	e.DELETE("/members/:id", middleware.PermissionMiddleware(teamManagers, h.handleRemoveMember))
+
+	e.GET("/invitations", middleware.PermissionMiddleware(teamManagers, h.handleGetInvitations))
+	e.POST("/invitations", middleware.PermissionMiddleware(teamManagers, h.handleInviteMember))
+	e.DELETE("/invitations/:id", middleware.PermissionMiddleware(teamManagers, h.handleRevokeInvitation))
+
+	e.PUT("/settings/two-factor", middleware.PermissionMiddleware(teamManagers, h.handleSetRequireAdminTwoFactor))
+
+	// used by the invitee, who does not have an account yet.
+	e.GET("/invitations/lookup", h.handleGetInvitation)
+	e.POST("/invitations/accept", h.handleAcceptInvitation)
+
+	// used by an invitee who already has an account, such as crew working for several companies.
+	e.POST("/invitations/join", middleware.AuthMiddleware(AllowedAuthenticatedOnly, h.handleJoinCompany))
+}
+
+// handleInviteMember invites a person to the caller's company.
+//
+//	@Summary		Invite a company member
+//	@Description	Sends an invitation by email, or by WhatsApp when only a phone number is given, to join the caller's company as crew or eo_admin.
+//	@Tags			team
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		InviteMemberRequest	true	"Invitation payload"
+//	@Success		201		{object}	Response{data=InvitationResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/team/invitations [post]
+func (h *TeamHandler) handleInviteMember(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "TeamHandler.handleInviteMember"
+	var request InviteMemberRequest
+
+	userID := c.Get("user_id").(int)
+	companyID := c.Get("company_id").(int)
+
+	if err := c.Bind(&request); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	invitation, err := h.teamService.InviteMember(ctx, companyID, userID, request.Email, request.Phone, request.Role)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, Response{
+		StatusCode: http.StatusCreated,
+		Message:    "invitation sent",
+		Data:       InvitationResponseFromEntity(pointer.Get(invitation)),
+		Error:      nil,
+	})
+}
+
+// handleGetInvitations lists the pending invitations of the caller's company.
+//
+//	@Summary	List pending invitations
+//	@Tags		team
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{object}	Response{data=[]InvitationResponse}
+//	@Failure	500	{object}	Response	"Internal Server Error"
+//	@Router		/team/invitations [get]
+func (h *TeamHandler) handleGetInvitations(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(int)
+
+	invitations, err := h.teamService.ListInvitations(ctx, companyID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	response := []InvitationResponse{}
+	for _, invitation := range invitations {
+		response = append(response, InvitationResponseFromEntity(invitation))
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       response,
+		Error:      nil,
+	})
+}
+
+// handleRevokeInvitation revokes a pending invitation of the caller's company.
+//
+//	@Summary	Revoke an invitation
+//	@Tags		team
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		id	path		int			true	"Invitation ID"
+//	@Success	200	{object}	Response	"Invitation revoked"
+//	@Failure	404	{object}	Response	"Invitation Not Found"
+//	@Failure	500	{object}	Response	"Internal Server Error"
+//	@Router		/team/invitations/{id} [delete]
+func (h *TeamHandler) handleRevokeInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(int)
+
+	invitationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return throwServiceError(c, entity.ErrInvitationNotFound)
+	}
+
+	if err := h.teamService.RevokeInvitation(ctx, companyID, invitationID); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "invitation revoked"})
+}
+
+// handleGetInvitation retrieves the invitation behind an invitation token.
+//
+//	@Summary	Get an invitation by its token
+//	@Tags		team
+//	@Produce	json
+//	@Param		token	query		string	true	"Invitation token"
+//	@Success	200		{object}	Response{data=InvitationResponse}
+//	@Failure	400		{object}	Response	"Invalid or expired invitation"
+//	@Failure	500		{object}	Response	"Internal Server Error"
+//	@Router		/team/invitations/lookup [get]
+func (h *TeamHandler) handleGetInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	invitation, company, err := h.teamService.GetInvitation(ctx, c.QueryParam("token"))
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	response := InvitationResponseFromEntity(pointer.Get(invitation))
+	response.Company = company.Name
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       response,
+		Error:      nil,
+	})
+}
+
+// handleAcceptInvitation accepts an invitation and creates the invitee's account.
+//
+//	@Summary		Accept an invitation
+//	@Description	Creates the invited user as a member of the inviting company and returns an access token.
+//	@Tags			team
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		AcceptInvitationRequest	true	"Invitation token and account details"
+//	@Success		201		{object}	Response{data=AccessTokenResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/team/invitations/accept [post]
+func (h *TeamHandler) handleAcceptInvitation(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "TeamHandler.handleAcceptInvitation"
+	var request AcceptInvitationRequest
+
+	if err := c.Bind(&request); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	user, company, err := h.teamService.AcceptInvitation(ctx, request.Token, entity.User{
+		FirstName: request.FirstName,
+		LastName:  &request.LastName,
+		Email:     request.Email,
+		Password:  request.Password,
+	})
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	authResponse, err := h.authService.GenerateAccessToken(ctx, user.ID, user.GetCompanyID(), user.Email, user.Role, false)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	companyResponse := CompanyResponseFromEntity(pointer.Get(company))
+
+	return c.JSON(http.StatusCreated, Response{
+		StatusCode: http.StatusCreated,
+		Message:    fmt.Sprintf("welcome to %s", company.Name),
+		Data: AccessTokenResponse{
+			AccessToken:           authResponse.AccessToken,
+			ExpiresAt:             authResponse.ExpiresAt,
+			RefreshToken:          authResponse.RefreshToken,
+			RefreshTokenExpiresAt: authResponse.RefreshTokenExpiresAt,
+			User: UserResponse{
+				ID:       user.ID,
+				Name:     user.GetName(),
+				Email:    user.Email,
+				Phone:    user.PhoneNumber,
+				JobTitle: user.JobTitle,
+				Role:     user.Role,
+			},
+			Company: &companyResponse,
+		},
+	})
+}
+
+// handleJoinCompany accepts an invitation with the account of the caller.
+//
+//	@Summary		Join a company with an existing account
+//	@Description	Adds the caller to the inviting company with the invited role. The caller keeps their other companies and can switch to the new one.
+//	@Tags			team
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		JoinCompanyRequest	true	"Invitation token"
+//	@Success		200		{object}	Response{data=CompanyResponse}
+//	@Failure		400		{object}	Response	"Bad Request"
+//	@Failure		500		{object}	Response	"Internal Server Error"
+//	@Router			/team/invitations/join [post]
+func (h *TeamHandler) handleJoinCompany(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "TeamHandler.handleJoinCompany"
+	var request JoinCompanyRequest
+
+	if err := c.Bind(&request); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusBadRequest, Response{StatusCode: http.StatusBadRequest, Message: "invalid request body"})
+	}
+
+	company, err := h.teamService.JoinCompany(ctx, request.Token, c.Get("user_id").(int))
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    fmt.Sprintf("welcome to %s", company.Name),
+		Data:       CompanyResponseFromEntity(pointer.Get(company)),
+		Error:      nil,
+	})
+}
+
+// handleGetMembers lists the members of the caller's company.
+//
+//	@Summary	List company members
+//	@Tags		team
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Success	200	{object}	Response{data=[]MemberResponse}
+//	@Failure	500	{object}	Response	"Internal Server Error"
+//	@Router		/team/members [get]
+func (h *TeamHandler) handleGetMembers(c echo.Context) error {
+	ctx := c.Request().Context()
+	companyID := c.Get("company_id").(int)
+
+	members, err := h.teamService.ListMembers(ctx, companyID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	response := []MemberResponse{}
+	for _, member := range members {
+		response = append(response, MemberResponseFromEntity(member))
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "ok",
+		Data:       response,
+		Error:      nil,
+	})
+}
+
+// handleChangeMemberRole changes the role of a member of the caller's company.
+//
+//	@Summary	Change the role of a company member
+//	@Tags		team
+//	@Accept		json
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		id		path		int						true	"Member user ID"
+//	@Param		request	body		ChangeMemberRoleRequest	true	"New role"
+//	@Success	200		{object}	Response				"Role updated"
+//	@Failure	400		{object}	Response				"Bad Request"
+//	@Failure	404		{object}	Response				"Member Not Found"
+//	@Failure	500		{object}	Response				"Internal Server Error"
+//	@Router		/team/members/{id}/role [patch]
+func (h *TeamHandler) handleChangeMemberRole(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "TeamHandler.handleChangeMemberRole"
+	var request ChangeMemberRoleRequest
+
+	userID := c.Get("user_id").(int)
+	companyID := c.Get("company_id").(int)
+
+	memberID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return throwServiceError(c, entity.ErrCompanyMemberNotFound)
+	}
+
+	if err := c.Bind(&request); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	if err := h.teamService.ChangeMemberRole(ctx, companyID, userID, memberID, request.Role); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "role updated"})
+}
+
+// handleRemoveMember removes a member from the caller's company.
+//
+//	@Summary	Remove a company member
+//	@Tags		team
+//	@Produce	json
+//	@Security	BearerAuth
+//	@Param		id	path		int			true	"Member user ID"
+//	@Success	200	{object}	Response	"Member removed"
+//	@Failure	400	{object}	Response	"Bad Request"
+//	@Failure	404	{object}	Response	"Member Not Found"
+//	@Failure	500	{object}	Response	"Internal Server Error"
+//	@Router		/team/members/{id} [delete]
+func (h *TeamHandler) handleRemoveMember(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("user_id").(int)
+	companyID := c.Get("company_id").(int)
+
+	memberID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return throwServiceError(c, entity.ErrCompanyMemberNotFound)
+	}
+
+	if err := h.teamService.RemoveMember(ctx, companyID, userID, memberID); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "member removed"})
+}
+
+// handleSetRequireAdminTwoFactor sets whether the admins of the caller's company have to use two-factor authentication.
+//
+//	@Summary		Require two-factor authentication for admins
+//	@Description	When required, admins without two-factor authentication have to enrol before using the API again.
+//	@Tags			team
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		RequireAdminTwoFactorRequest	true	"Two-factor setting"
+//	@Success		200		{object}	Response						"Setting updated"
+//	@Failure		500		{object}	Response						"Internal Server Error"
+//	@Router			/team/settings/two-factor [put]
+func (h *TeamHandler) handleSetRequireAdminTwoFactor(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "TeamHandler.handleSetRequireAdminTwoFactor"
+	var request RequireAdminTwoFactorRequest
+
+	companyID := c.Get("company_id").(int)
+
+	if err := c.Bind(&request); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	if err := h.teamService.SetRequireAdminTwoFactor(ctx, companyID, request.Required); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "setting updated"})
+}
@@ -0,0 +1,88 @@
+package delivery
+
+import (
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+// InviteMemberRequest represents the payload required to invite a person to the caller's company.
+// Either Email or Phone must be provided.
+type InviteMemberRequest struct {
+	Email string          `json:"email"`
+	Phone string          `json:"phone"`
+	Role  entity.UserRole `json:"role"`
+}
+
+// ChangeMemberRoleRequest represents the payload required to change the role of a company member.
+type ChangeMemberRoleRequest struct {
+	Role entity.UserRole `json:"role"`
+}
+
+// RequireAdminTwoFactorRequest represents the payload required to change the two-factor requirement of a company's admins.
+type RequireAdminTwoFactorRequest struct {
+	Required bool `json:"required"`
+}
+
+// JoinCompanyRequest represents the payload required for a signed in user to accept an invitation.
+type JoinCompanyRequest struct {
+	Token string `json:"token"`
+}
+
+// AcceptInvitationRequest represents the payload required to accept an invitation and create an account.
+// Email is only required when the invitation was sent by phone.
+type AcceptInvitationRequest struct {
+	Token     string `json:"token"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+}
+
+// MemberResponse represents a member of a company.
+type MemberResponse struct {
+	ID       int             `json:"id"`
+	Name     string          `json:"name"`
+	Email    string          `json:"email"`
+	Phone    string          `json:"phone"`
+	JobTitle string          `json:"jobTitle"`
+	Role     entity.UserRole `json:"role"`
+	JoinedAt string          `json:"joinedAt"`
+}
+
+// MemberResponseFromEntity converts a user into a MemberResponse.
+func MemberResponseFromEntity(user entity.User) MemberResponse {
+	return MemberResponse{
+		ID:       user.ID,
+		Name:     user.GetName(),
+		Email:    user.Email,
+		Phone:    pointer.Get(user.PhoneNumber),
+		JobTitle: pointer.Get(user.JobTitle),
+		Role:     user.Role,
+		JoinedAt: user.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// InvitationResponse represents a pending invitation to join a company.
+type InvitationResponse struct {
+	ID        int             `json:"id"`
+	Company   string          `json:"company,omitempty"`
+	Email     string          `json:"email"`
+	Phone     string          `json:"phone"`
+	Role      entity.UserRole `json:"role"`
+	ExpiresAt string          `json:"expiresAt"`
+	CreatedAt string          `json:"createdAt"`
+}
+
+// InvitationResponseFromEntity converts an invitation into an InvitationResponse.
+func InvitationResponseFromEntity(invitation entity.CompanyInvitation) InvitationResponse {
+	return InvitationResponse{
+		ID:        invitation.ID,
+		Email:     pointer.Get(invitation.Email),
+		Phone:     pointer.Get(invitation.Phone),
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt.Format(time.RFC3339),
+		CreatedAt: invitation.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,199 @@
+package delivery
+
+import (
+	"net/http"
+
+	"github.com/AlekSi/pointer"
+	"github.com/labstack/echo/v4"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// HandleVerifyTwoFactor godoc
+//
+//	@Summary		Complete a sign in with a two-factor code
+//	@Description	Exchanges the challenge token returned by the sign in and a code from the authenticator app, or a recovery code, for an access token.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		TwoFactorVerifyRequest				true	"Challenge token and two-factor code"
+//	@Success		200		{object}	Response{data=AccessTokenResponse}	"User successfully authenticated"
+//	@Failure		400		{object}	Response							"Invalid code or challenge"
+//	@Failure		429		{object}	Response							"Too many failed attempts"
+//	@Failure		500		{object}	Response							"Internal server error"
+//	@Router			/api/v1/auth/2fa/verify [post]
+func (h *AuthHandler) HandleVerifyTwoFactor(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleVerifyTwoFactor"
+	var requestBody TwoFactorVerifyRequest
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	user, company, authResponse, err := h.authService.CompleteTwoFactorSignIn(ctx, requestBody.ChallengeToken, requestBody.Code, c.RealIP())
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	var companyResponse CompanyResponse
+	if company != nil {
+		companyResponse = CompanyResponseFromEntity(pointer.Get(company))
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "success",
+		Data: AccessTokenResponse{
+			AccessToken:           authResponse.AccessToken,
+			ExpiresAt:             authResponse.ExpiresAt,
+			RefreshToken:          authResponse.RefreshToken,
+			RefreshTokenExpiresAt: authResponse.RefreshTokenExpiresAt,
+			User: UserResponse{
+				ID:       user.ID,
+				Name:     user.GetName(),
+				Email:    user.Email,
+				Phone:    user.PhoneNumber,
+				JobTitle: user.JobTitle,
+				Role:     user.Role,
+			},
+			Company: &companyResponse,
+		},
+	})
+}
+
+// HandleSetupTwoFactor godoc
+//
+//	@Summary		Start two-factor enrolment
+//	@Description	Generates a new TOTP secret and its provisioning URI to be shown as a QR code. Two-factor is enabled once a code is confirmed.
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	Response{data=TwoFactorSetupResponse}
+//	@Failure		400	{object}	Response	"Two-factor already enabled"
+//	@Failure		500	{object}	Response	"Internal server error"
+//	@Router			/api/v1/auth/2fa/setup [post]
+func (h *AuthHandler) HandleSetupTwoFactor(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("user_id").(int)
+
+	setup, err := h.authService.SetupTwoFactor(ctx, userID)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "scan the provisioning uri with your authenticator app",
+		Data: TwoFactorSetupResponse{
+			Secret:          setup.Secret,
+			ProvisioningURI: setup.ProvisioningURI,
+		},
+	})
+}
+
+// HandleEnableTwoFactor godoc
+//
+//	@Summary		Enable two-factor authentication
+//	@Description	Confirms the enrolment with a code from the authenticator app and returns the recovery codes, which are only shown once.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		TwoFactorCodeRequest	true	"Code from the authenticator app"
+//	@Success		200		{object}	Response{data=RecoveryCodesResponse}
+//	@Failure		400		{object}	Response	"Invalid code"
+//	@Failure		500		{object}	Response	"Internal server error"
+//	@Router			/api/v1/auth/2fa/enable [post]
+func (h *AuthHandler) HandleEnableTwoFactor(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleEnableTwoFactor"
+	var requestBody TwoFactorCodeRequest
+
+	userID := c.Get("user_id").(int)
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	recoveryCodes, err := h.authService.EnableTwoFactor(ctx, userID, requestBody.Code)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "two-factor authentication enabled",
+		Data:       RecoveryCodesResponse{RecoveryCodes: recoveryCodes},
+	})
+}
+
+// HandleDisableTwoFactor godoc
+//
+//	@Summary		Disable two-factor authentication
+//	@Description	Disables two-factor authentication after checking a code from the authenticator app or a recovery code.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		TwoFactorCodeRequest	true	"Two-factor or recovery code"
+//	@Success		200		{object}	Response				"Two-factor disabled"
+//	@Failure		400		{object}	Response				"Invalid code or two-factor required by the company"
+//	@Failure		500		{object}	Response				"Internal server error"
+//	@Router			/api/v1/auth/2fa/disable [post]
+func (h *AuthHandler) HandleDisableTwoFactor(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleDisableTwoFactor"
+	var requestBody TwoFactorCodeRequest
+
+	userID := c.Get("user_id").(int)
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	if err := h.authService.DisableTwoFactor(ctx, userID, requestBody.Code); err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{StatusCode: http.StatusOK, Message: "two-factor authentication disabled"})
+}
+
+// HandleRegenerateRecoveryCodes godoc
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replaces every recovery code after checking a code from the authenticator app.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		TwoFactorCodeRequest	true	"Code from the authenticator app"
+//	@Success		200		{object}	Response{data=RecoveryCodesResponse}
+//	@Failure		400		{object}	Response	"Invalid code"
+//	@Failure		500		{object}	Response	"Internal server error"
+//	@Router			/api/v1/auth/2fa/recovery-codes [post]
+func (h *AuthHandler) HandleRegenerateRecoveryCodes(c echo.Context) error {
+	ctx := c.Request().Context()
+	const ops = "AuthHandler.HandleRegenerateRecoveryCodes"
+	var requestBody TwoFactorCodeRequest
+
+	userID := c.Get("user_id").(int)
+
+	if err := c.Bind(&requestBody); err != nil {
+		logger.Warn(ctx, ops, "failed to parse request body")
+		return c.JSON(http.StatusInternalServerError, throwInternalServerError(err))
+	}
+
+	recoveryCodes, err := h.authService.RegenerateRecoveryCodes(ctx, userID, requestBody.Code)
+	if err != nil {
+		return throwServiceError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		StatusCode: http.StatusOK,
+		Message:    "recovery codes regenerated",
+		Data:       RecoveryCodesResponse{RecoveryCodes: recoveryCodes},
+	})
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// APIKey represents a company-scoped key used by machines to call the API without a human login.
+// Only the hash of the key is stored, Prefix is kept in plain text so the key can be identified.
+type APIKey struct {
+	ID         int          `json:"id"`
+	CompanyID  int          `json:"companyId"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"-"`
+	Scopes     []Permission `json:"scopes"`
+	CreatedBy  int          `json:"createdBy"`
+	ExpiresAt  *time.Time   `json:"expiresAt"`
+	LastUsedAt *time.Time   `json:"lastUsedAt"`
+	RevokedAt  *time.Time   `json:"-"`
+	CreatedAt  time.Time    `json:"createdAt"`
+}
+
+// APIKeyScopes lists the permissions that can be granted to an API key.
+var APIKeyScopes = []Permission{
+	PermissionEventView,
+	PermissionEventCreate,
+	PermissionEventUpdate,
+	PermissionGuestView,
+	PermissionGuestEdit,
+	PermissionGuestCheckIn,
+	PermissionMessageSend,
+}
+
+// IsExpired returns true when the API key has an expiry time and is already past it.
+func (k APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+// IsRevoked returns true when the API key has been revoked.
+func (k APIKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction names a state-changing action recorded in the audit log.
+type AuditAction string
+
+var (
+	// AuditActionEventCreate is recorded when an event is created.
+	AuditActionEventCreate AuditAction = "event.create"
+
+	// AuditActionEventUpdate is recorded when an event is updated.
+	AuditActionEventUpdate AuditAction = "event.update"
+
+	// AuditActionEventDelete is recorded when an event is deleted.
+	AuditActionEventDelete AuditAction = "event.delete"
+
+	// AuditActionEventStatusChange is recorded when an event moves to another stage of its lifecycle.
+	AuditActionEventStatusChange AuditAction = "event.status_change"
+
+	// AuditActionEventStaffAssign is recorded when a member is assigned to an event, or their permissions change.
+	AuditActionEventStaffAssign AuditAction = "event.staff.assign"
+
+	// AuditActionEventStaffRemove is recorded when a member is unassigned from an event.
+	AuditActionEventStaffRemove AuditAction = "event.staff.remove"
+
+	// AuditActionGuestAdd is recorded when guests are added to an event.
+	AuditActionGuestAdd AuditAction = "guest.add"
+
+	// AuditActionGuestRegister is recorded when a guest answers the public invitation of an event.
+	AuditActionGuestRegister AuditAction = "guest.register"
+
+	// AuditActionGuestUpdate is recorded when a guest is updated.
+	AuditActionGuestUpdate AuditAction = "guest.update"
+
+	// AuditActionGuestDelete is recorded when guests are removed from an event.
+	AuditActionGuestDelete AuditAction = "guest.delete"
+
+	// AuditActionGuestCheckIn is recorded when the arrival of a guest is set or cleared.
+	AuditActionGuestCheckIn AuditAction = "guest.checkin"
+
+	// AuditActionGuestErase is recorded when the personal data of a guest is anonymised.
+	AuditActionGuestErase AuditAction = "guest.erase"
+
+	// AuditActionCompanyUpdate is recorded when the profile of a company is updated.
+	AuditActionCompanyUpdate AuditAction = "company.update"
+
+	// AuditActionCompanyLogoUpdate is recorded when the logo of a company is uploaded or removed.
+	AuditActionCompanyLogoUpdate AuditAction = "company.logo_update"
+
+	// AuditActionCompanySuspend is recorded when the platform operators suspend a company.
+	AuditActionCompanySuspend AuditAction = "company.suspend"
+
+	// AuditActionCompanyReactivate is recorded when the platform operators reactivate a suspended company.
+	AuditActionCompanyReactivate AuditAction = "company.reactivate"
+
+	// AuditActionCompanyPlanChange is recorded when the platform operators change the plan of a company.
+	AuditActionCompanyPlanChange AuditAction = "company.plan_change"
+
+	// AuditActionUserImpersonate is recorded when a platform operator impersonates a user.
+	AuditActionUserImpersonate AuditAction = "user.impersonate"
+
+	// AuditActionUserRegister is recorded when a user signs up with a new company.
+	AuditActionUserRegister AuditAction = "user.register"
+
+	// AuditActionUserSignIn is recorded when a user signs in.
+	AuditActionUserSignIn AuditAction = "user.sign_in"
+
+	// AuditActionUserSignOut is recorded when a user signs out.
+	AuditActionUserSignOut AuditAction = "user.sign_out"
+
+	// AuditActionUserSignInLocked is recorded when sign in is locked after too many failed attempts.
+	AuditActionUserSignInLocked AuditAction = "user.sign_in_locked"
+
+	// AuditActionUserPasswordReset is recorded when a user resets their password.
+	AuditActionUserPasswordReset AuditAction = "user.password_reset"
+
+	// AuditActionUserProfileUpdate is recorded when a user updates their profile.
+	AuditActionUserProfileUpdate AuditAction = "user.profile_update"
+
+	// AuditActionUserPasswordChange is recorded when a user changes their password.
+	AuditActionUserPasswordChange AuditAction = "user.password_change"
+
+	// AuditActionUserCompanySwitch is recorded when a user switches to another company they belong to.
+	AuditActionUserCompanySwitch AuditAction = "user.company_switch"
+
+	// AuditActionUserEmailVerify is recorded when a user verifies their email address.
+	AuditActionUserEmailVerify AuditAction = "user.email_verify"
+
+	// AuditActionUserTwoFactorEnable is recorded when a user enables two-factor authentication.
+	AuditActionUserTwoFactorEnable AuditAction = "user.2fa_enable"
+
+	// AuditActionUserTwoFactorDisable is recorded when a user disables two-factor authentication.
+	AuditActionUserTwoFactorDisable AuditAction = "user.2fa_disable"
+
+	// AuditActionUserRecoveryCodesRegenerate is recorded when a user replaces their recovery codes.
+	AuditActionUserRecoveryCodesRegenerate AuditAction = "user.recovery_codes_regenerate"
+
+	// AuditActionUserSessionRevoke is recorded when a user signs out one or several of their sessions.
+	AuditActionUserSessionRevoke AuditAction = "user.session_revoke"
+)
+
+// AuditTargetType names the kind of entity an audited action was performed on.
+type AuditTargetType string
+
+var (
+	// AuditTargetEvent targets an event, the target ID is the event ID.
+	AuditTargetEvent AuditTargetType = "event"
+
+	// AuditTargetGuest targets a guest, the target ID is the guest ID or its barcode.
+	AuditTargetGuest AuditTargetType = "guest"
+
+	// AuditTargetCompany targets a company, the target ID is the company ID.
+	AuditTargetCompany AuditTargetType = "company"
+
+	// AuditTargetUser targets a user, the target ID is the user ID.
+	AuditTargetUser AuditTargetType = "user"
+
+	// AuditTargetSignIn targets the sign in of an email or IP address, the target ID is the throttling key.
+	AuditTargetSignIn AuditTargetType = "sign_in"
+)
+
+// AuditLog represents a state-changing action recorded for accountability.
+// Before and After only hold the fields the action changed, either of them is empty when
+// the target was created or removed. ActorID is empty for anonymous actions,
+// APIKeyID is set when the action was performed with an API key and ImpersonatorID is set when
+// a platform operator performed the action on behalf of the actor.
+type AuditLog struct {
+	ID             int             `json:"id"`
+	CompanyID      *int            `json:"companyId"`
+	ActorID        *int            `json:"actorId"`
+	APIKeyID       *int            `json:"apiKeyId"`
+	ImpersonatorID *int            `json:"impersonatorId"`
+	Action         AuditAction     `json:"action"`
+	TargetType     AuditTargetType `json:"targetType"`
+	TargetID       string          `json:"targetId"`
+	Before         json.RawMessage `json:"before" swaggertype:"object"`
+	After          json.RawMessage `json:"after" swaggertype:"object"`
+	IP             string          `json:"ip"`
+	RequestID      string          `json:"requestId"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// AuditLogFilter narrows down the audit logs of a company. Zero values do not filter.
+type AuditLogFilter struct {
+	CompanyID  int
+	ActorID    int
+	Action     AuditAction
+	TargetType AuditTargetType
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
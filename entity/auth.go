@@ -1,9 +1,117 @@
 package entity
 
+import "time"
+
 // AuthResponse represents the response body for successful authentication.
+// When TwoFactorRequired is true no token is issued yet, ChallengeToken has to be exchanged with a two-factor code.
 type AuthResponse struct {
-	AccessToken string   `json:"access_token"`
-	ExpiresAt   string   `json:"expires_at"`
-	Email       string   `json:"email"`
-	Role        UserRole `json:"role"`
+	AccessToken           string   `json:"access_token"`
+	ExpiresAt             string   `json:"expires_at"`
+	RefreshToken          string   `json:"refresh_token"`
+	RefreshTokenExpiresAt string   `json:"refresh_token_expires_at"`
+	Email                 string   `json:"email"`
+	Role                  UserRole `json:"role"`
+	TwoFactorRequired     bool     `json:"two_factor_required"`
+	ChallengeToken        string   `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt    string   `json:"challenge_expires_at,omitempty"`
+}
+
+// RefreshToken represents a persisted refresh token.
+// Only the hash of the token is stored, tokens issued from the same sign in share the same FamilyID.
+// CompanyID is the company the access tokens are issued for, it is nil for users without a company.
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	CompanyID *int
+	FamilyID  string
+	TokenHash string
+	Remember  bool
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// IsExpired returns true when the refresh token is already past its expiry time.
+func (t RefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsRevoked returns true when the refresh token has been revoked or rotated.
+func (t RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// Session represents a sign in of a user on a device, it lasts as long as the refresh token family it started.
+// Access tokens carry the ID of their session, so revoking the session refuses them right away.
+type Session struct {
+	ID         int
+	UserID     int
+	CompanyID  *int
+	FamilyID   string
+	Device     string
+	IP         string
+	UserAgent  string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	RevokedAt  *time.Time
+	// CompanyLocked is set on sessions started with single sign-on, which can not switch to another company.
+	CompanyLocked bool
+}
+
+// PasswordResetToken represents a persisted, single-use password reset token.
+// Only the hash of the token is stored.
+type PasswordResetToken struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// IsExpired returns true when the password reset token is already past its expiry time.
+func (t PasswordResetToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// EmailVerificationToken represents a persisted, single-use email verification token.
+// Only the hash of the token is stored.
+type EmailVerificationToken struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// IsExpired returns true when the email verification token is already past its expiry time.
+func (t EmailVerificationToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// TwoFactorChallenge represents a pending sign in waiting for a two-factor code.
+// Only the hash of the challenge token is stored.
+// SSOCompanyID is set on challenges of a single sign-on, the sign in completes into that company.
+type TwoFactorChallenge struct {
+	ID           int
+	UserID       int
+	TokenHash    string
+	Remember     bool
+	Attempts     int
+	ExpiresAt    time.Time
+	UsedAt       *time.Time
+	CreatedAt    time.Time
+	SSOCompanyID *int
+}
+
+// IsExpired returns true when the challenge is already past its expiry time.
+func (t TwoFactorChallenge) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// TwoFactorSetup holds what a user needs to register the account in an authenticator app.
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
 }
@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// MaxCompanyLogoSize is the largest logo, in bytes, a company may upload.
+const MaxCompanyLogoSize = 2 << 20
+
 // Company represents a company entity.
 type Company struct {
 	ID          int
@@ -16,4 +19,39 @@ type Company struct {
 	LogoURL     *string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	// RequireAdminTwoFactor requires the admins of the company to enable two-factor authentication.
+	RequireAdminTwoFactor bool
+
+	// SuspendedAt is set while the company is suspended by the platform operators, its users and API keys are refused.
+	SuspendedAt      *time.Time
+	SuspensionReason *string
+
+	// PlanCode identifies the plan the company subscribed to.
+	PlanCode string
+}
+
+// IsSuspended returns true when the company is suspended by the platform operators.
+func (c Company) IsSuspended() bool {
+	return c.SuspendedAt != nil
+}
+
+// CompanyUsage represents a company with the usage statistics shown to the platform operators.
+type CompanyUsage struct {
+	Company
+	MemberCount int
+	EventCount  int
+	GuestCount  int
+	LastEventAt *time.Time
+}
+
+// CompanyMembership links a user to a company they belong to, with the role they have in that company.
+// A user may belong to several companies, the company of their access token is switched between them.
+type CompanyMembership struct {
+	UserID         int
+	CompanyID      int
+	CompanyName    string
+	CompanyLogoURL *string
+	Role           UserRole
+	CreatedAt      time.Time
 }
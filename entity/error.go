@@ -7,8 +7,12 @@ type GosmErrorType string
 
 // Predefined error types to categorize different error scenarios.
 var (
-	GosmErrorTypeBadRequest GosmErrorType = "4" // Represents client-side errors (e.g., validation failures)
-	GosmErrorTypeUnknown    GosmErrorType = "5" // Represents unexpected or internal server errors
+	GosmErrorTypeBadRequest   GosmErrorType = "4"   // Represents client-side errors (e.g., validation failures)
+	GosmErrorTypeNotFound     GosmErrorType = "404" // Represents a missing resource, or a resource the caller is not allowed to see
+	GosmErrorTypeTooMany      GosmErrorType = "429" // Represents a request refused because too many were made
+	GosmErrorTypeQuota        GosmErrorType = "402" // Represents a request refused because it exceeds the plan of the company
+	GosmErrorTypePrecondition GosmErrorType = "412" // Represents a change refused because the resource was changed in the meantime
+	GosmErrorTypeUnknown      GosmErrorType = "5"   // Represents unexpected or internal server errors
 )
 
 // GosmError represents a structured application error.
@@ -47,6 +51,50 @@ func NewBadRequestError(code string, message string) error {
 	}
 }
 
+// NewNotFoundError creates a new instance of GosmError representing a missing resource.
+// It is used when the requested resource does not exist or does not belong to the caller.
+func NewNotFoundError(code string, message string) error {
+	return GosmError{
+		Type:    GosmErrorTypeNotFound,
+		Code:    code,
+		Message: message,
+		Source:  nil,
+	}
+}
+
+// NewTooManyRequestsError creates a new instance of GosmError representing a throttled request.
+// It is used when the client made too many attempts and has to wait before trying again.
+func NewTooManyRequestsError(code string, message string) error {
+	return GosmError{
+		Type:    GosmErrorTypeTooMany,
+		Code:    code,
+		Message: message,
+		Source:  nil,
+	}
+}
+
+// NewQuotaExceededError creates a new instance of GosmError representing a request exceeding the plan of the company.
+// It is used when the company has to upgrade its plan, or wait for its usage to decrease, before trying again.
+func NewQuotaExceededError(code string, message string) error {
+	return GosmError{
+		Type:    GosmErrorTypeQuota,
+		Code:    code,
+		Message: message,
+		Source:  nil,
+	}
+}
+
+// NewPreconditionFailedError creates a new instance of GosmError representing a change made to an outdated version of a resource.
+// It is used when someone else changed the resource since the caller retrieved it.
+func NewPreconditionFailedError(code string, message string) error {
+	return GosmError{
+		Type:    GosmErrorTypePrecondition,
+		Code:    code,
+		Message: message,
+		Source:  nil,
+	}
+}
+
 var (
 	// ErrUserExisted is returned when a user provides an email existed in database.
 	ErrUserExisted error = NewBadRequestError("USER_EXISTED", "user is already existed")
@@ -71,4 +119,265 @@ var (
 
 	// ErrAuthTokenIsExpired represents an error when the provided access token is expired.
 	ErrAuthTokenIsExpired error = NewBadRequestError("AUTH_TOKEN_EXPIRED", "provided access token is expired")
+
+	// ErrInvalidRefreshToken represents an error when the provided refresh token is unknown or already revoked.
+	ErrInvalidRefreshToken error = NewBadRequestError("AUTH_INVALID_REFRESH_TOKEN", "provided refresh token is not valid")
+
+	// ErrRefreshTokenIsExpired represents an error when the provided refresh token is expired.
+	ErrRefreshTokenIsExpired error = NewBadRequestError("AUTH_REFRESH_TOKEN_EXPIRED", "provided refresh token is expired")
+
+	// ErrInvalidPasswordResetToken represents an error when the provided password reset token is unknown or already used.
+	ErrInvalidPasswordResetToken error = NewBadRequestError("AUTH_INVALID_RESET_TOKEN", "provided password reset token is not valid")
+
+	// ErrPasswordResetTokenIsExpired represents an error when the provided password reset token is expired.
+	ErrPasswordResetTokenIsExpired error = NewBadRequestError("AUTH_RESET_TOKEN_EXPIRED", "provided password reset token is expired")
+
+	// ErrInvalidEmailVerificationToken represents an error when the provided email verification token is unknown or already used.
+	ErrInvalidEmailVerificationToken error = NewBadRequestError("AUTH_INVALID_VERIFICATION_TOKEN", "provided email verification token is not valid")
+
+	// ErrEmailVerificationTokenIsExpired represents an error when the provided email verification token is expired.
+	ErrEmailVerificationTokenIsExpired error = NewBadRequestError("AUTH_VERIFICATION_TOKEN_EXPIRED", "provided email verification token is expired")
+
+	// ErrEmailAlreadyVerified represents an error when a verification is requested for an already verified email.
+	ErrEmailAlreadyVerified error = NewBadRequestError("AUTH_EMAIL_ALREADY_VERIFIED", "email address is already verified")
+
+	// ErrInvitationInvalidRole represents an error when an invitation is created for a role that cannot be granted.
+	ErrInvitationInvalidRole error = NewBadRequestError("INVITATION_INVALID_ROLE", "invited role must be either eo_admin or crew")
+
+	// ErrInvitationMissingContact represents an error when an invitation has neither an email nor a phone number.
+	ErrInvitationMissingContact error = NewBadRequestError("INVITATION_MISSING_CONTACT", "please provide an email address or a phone number")
+
+	// ErrInvalidInvitationToken represents an error when the provided invitation token is unknown, accepted or revoked.
+	ErrInvalidInvitationToken error = NewBadRequestError("INVITATION_INVALID_TOKEN", "provided invitation is not valid")
+
+	// ErrInvitationIsExpired represents an error when the provided invitation is expired.
+	ErrInvitationIsExpired error = NewBadRequestError("INVITATION_EXPIRED", "provided invitation is expired")
+
+	// ErrInvitationNotFound represents an error when an invitation does not exist in the caller's company.
+	ErrInvitationNotFound error = NewNotFoundError("INVITATION_NOT_FOUND", "invitation not found")
+
+	// ErrCompanyMemberNotFound represents an error when a user is not a member of the caller's company.
+	ErrCompanyMemberNotFound error = NewNotFoundError("COMPANY_MEMBER_NOT_FOUND", "company member not found")
+
+	// ErrCompanyMemberIsSelf represents an error when an admin tries to remove or demote themselves.
+	ErrCompanyMemberIsSelf error = NewBadRequestError("COMPANY_MEMBER_IS_SELF", "you can not change your own membership")
+
+	// ErrEventInvalidPermission represents an error when an unknown event permission is granted.
+	ErrEventInvalidPermission error = NewBadRequestError("EVENT_INVALID_PERMISSION", "please provide valid event permissions")
+
+	// ErrEventStaffNotFound represents an error when a user is not assigned to an event, or can not be assigned to it.
+	ErrEventStaffNotFound error = NewNotFoundError("EVENT_STAFF_NOT_FOUND", "event staff not found")
+
+	// ErrEventNotFound represents an error when an event does not exist in the caller's company.
+	ErrEventNotFound error = NewNotFoundError("EVENT_NOT_FOUND", "event not found")
+
+	// ErrGuestNotFound represents an error when a guest does not exist in the given event.
+	ErrGuestNotFound error = NewNotFoundError("GUEST_NOT_FOUND", "guest not found")
+
+	// ErrInvalidPermission represents an error when a permission outside of the registry is granted.
+	ErrInvalidPermission error = NewBadRequestError("PERMISSION_INVALID", "please provide valid permissions")
+
+	// ErrRoleNotCustomisable represents an error when the permissions of a role can not be customised.
+	ErrRoleNotCustomisable error = NewBadRequestError("PERMISSION_ROLE_NOT_CUSTOMISABLE", "permissions of this role can not be customised")
+
+	// ErrPermissionLockout represents an error when a user tries to revoke their own permission to manage roles.
+	ErrPermissionLockout error = NewBadRequestError("PERMISSION_LOCKOUT", "you can not revoke your own permission to manage roles")
+
+	// ErrInvalidAPIKey represents an error when the provided API key is unknown or revoked.
+	ErrInvalidAPIKey error = NewBadRequestError("AUTH_INVALID_API_KEY", "provided api key is not valid")
+
+	// ErrAPIKeyIsExpired represents an error when the provided API key is expired.
+	ErrAPIKeyIsExpired error = NewBadRequestError("AUTH_API_KEY_EXPIRED", "provided api key is expired")
+
+	// ErrAPIKeyCreatorPermissionRevoked represents an error when the creator of the provided API key
+	// left the company or no longer holds every scope of the key.
+	ErrAPIKeyCreatorPermissionRevoked error = NewBadRequestError("AUTH_API_KEY_CREATOR_PERMISSION_REVOKED", "the creator of the provided api key no longer holds its permissions")
+
+	// ErrAPIKeyInvalidScope represents an error when an API key is granted a scope its creator does not have,
+	// or a scope that can not be granted to API keys.
+	ErrAPIKeyInvalidScope error = NewBadRequestError("API_KEY_INVALID_SCOPE", "please provide scopes you are granted and that can be granted to api keys")
+
+	// ErrAPIKeyMissingName represents an error when an API key is created without a name.
+	ErrAPIKeyMissingName error = NewBadRequestError("API_KEY_MISSING_NAME", "please provide a name for the api key")
+
+	// ErrAPIKeyNotFound represents an error when an API key does not exist in the caller's company.
+	ErrAPIKeyNotFound error = NewNotFoundError("API_KEY_NOT_FOUND", "api key not found")
+
+	// ErrInvalidTwoFactorCode represents an error when the provided two-factor or recovery code is not valid.
+	ErrInvalidTwoFactorCode error = NewBadRequestError("AUTH_INVALID_2FA_CODE", "provided two-factor code is not valid")
+
+	// ErrInvalidTwoFactorChallenge represents an error when the provided challenge token is unknown, used or locked.
+	ErrInvalidTwoFactorChallenge error = NewBadRequestError("AUTH_INVALID_2FA_CHALLENGE", "provided sign in challenge is not valid, please sign in again")
+
+	// ErrTwoFactorChallengeIsExpired represents an error when the provided challenge token is expired.
+	ErrTwoFactorChallengeIsExpired error = NewBadRequestError("AUTH_2FA_CHALLENGE_EXPIRED", "provided sign in challenge is expired, please sign in again")
+
+	// ErrTwoFactorAlreadyEnabled represents an error when a user tries to enrol while two-factor is already enabled.
+	ErrTwoFactorAlreadyEnabled error = NewBadRequestError("AUTH_2FA_ALREADY_ENABLED", "two-factor authentication is already enabled")
+
+	// ErrTwoFactorNotSetUp represents an error when a user tries to enable two-factor without setting it up first.
+	ErrTwoFactorNotSetUp error = NewBadRequestError("AUTH_2FA_NOT_SET_UP", "please set up two-factor authentication first")
+
+	// ErrTwoFactorNotEnabled represents an error when a two-factor operation is requested while it is not enabled.
+	ErrTwoFactorNotEnabled error = NewBadRequestError("AUTH_2FA_NOT_ENABLED", "two-factor authentication is not enabled")
+
+	// ErrTwoFactorRequiredByCompany represents an error when an admin tries to disable two-factor required by their company.
+	ErrTwoFactorRequiredByCompany error = NewBadRequestError("AUTH_2FA_REQUIRED", "your company requires admins to use two-factor authentication")
+
+	// ErrSignInLocked represents an error when sign in is temporarily locked after too many failed attempts.
+	ErrSignInLocked error = NewTooManyRequestsError("AUTH_SIGN_IN_LOCKED", "too many failed sign in attempts, please try again later")
+
+	// ErrSSONotConfigured represents an error when the company has no enabled single sign-on.
+	ErrSSONotConfigured error = NewNotFoundError("SSO_NOT_CONFIGURED", "single sign-on is not configured for this company")
+
+	// ErrSSOInvalidConfig represents an error when the single sign-on configuration misses the issuer or the client ID,
+	// or its issuer is not an https URL.
+	ErrSSOInvalidConfig error = NewBadRequestError("SSO_INVALID_CONFIG", "please provide a valid https issuer url and client id")
+
+	// ErrSSOInvalidDefaultRole represents an error when the default role of single sign-on users can not be granted.
+	ErrSSOInvalidDefaultRole error = NewBadRequestError("SSO_INVALID_DEFAULT_ROLE", "please provide a valid default role")
+
+	// ErrInvalidSSOState represents an error when the provided login state is unknown or already used.
+	ErrInvalidSSOState error = NewBadRequestError("SSO_INVALID_STATE", "provided single sign-on state is not valid, please sign in again")
+
+	// ErrSSOStateIsExpired represents an error when the provided login state is expired.
+	ErrSSOStateIsExpired error = NewBadRequestError("SSO_STATE_EXPIRED", "provided single sign-on state is expired, please sign in again")
+
+	// ErrSSOLoginFailed represents an error when the identity provider refused the authorization code or its ID token is invalid.
+	ErrSSOLoginFailed error = NewBadRequestError("SSO_LOGIN_FAILED", "single sign-on failed, please sign in again")
+
+	// ErrSSOEmailNotVerified represents an error when the identity provider does not vouch for the email of a new user.
+	ErrSSOEmailNotVerified error = NewBadRequestError("SSO_EMAIL_NOT_VERIFIED", "your identity provider did not provide a verified email address")
+
+	// ErrSSOAccountConflict represents an error when the identity belongs to an account outside the company.
+	ErrSSOAccountConflict error = NewBadRequestError("SSO_ACCOUNT_CONFLICT", "this identity can not be linked to an account of this company")
+
+	// ErrSSOAccountLinkRefused represents an error when the email of the identity belongs to an account which is also a member of other companies.
+	ErrSSOAccountLinkRefused error = NewBadRequestError("SSO_ACCOUNT_LINK_REFUSED", "an account with this email belongs to other companies, sign in with your password instead")
+
+	// ErrCompanyNotFound represents an error when the company does not exist.
+	ErrCompanyNotFound error = NewNotFoundError("COMPANY_NOT_FOUND", "company not found")
+
+	// ErrCompanyNameEmpty represents an error when the company name is empty.
+	ErrCompanyNameEmpty error = NewBadRequestError("COMPANY_NAME_EMPTY", "please provide the company name")
+
+	// ErrCompanyInvalidEmail represents an error when the company email address is invalid.
+	ErrCompanyInvalidEmail error = NewBadRequestError("COMPANY_INVALID_EMAIL", "provided company email address is invalid")
+
+	// ErrCompanyInvalidPhone represents an error when the company phone number has no known country code.
+	ErrCompanyInvalidPhone error = NewBadRequestError("COMPANY_INVALID_PHONE", "provided company phone number is invalid, please include the country code")
+
+	// ErrCompanyInvalidWebsite represents an error when the company website is not an http or https url.
+	ErrCompanyInvalidWebsite error = NewBadRequestError("COMPANY_INVALID_WEBSITE", "provided company website is invalid, please provide an http or https url")
+
+	// ErrCompanyLogoMissing represents an error when no logo file was uploaded.
+	ErrCompanyLogoMissing error = NewBadRequestError("COMPANY_LOGO_MISSING", "please upload a logo file")
+
+	// ErrCompanyLogoTooLarge represents an error when the uploaded logo exceeds the maximum size.
+	ErrCompanyLogoTooLarge error = NewBadRequestError("COMPANY_LOGO_TOO_LARGE", "provided logo is too large, the maximum size is 2 MB")
+
+	// ErrCompanyLogoInvalidType represents an error when the uploaded logo is not a supported image.
+	ErrCompanyLogoInvalidType error = NewBadRequestError("COMPANY_LOGO_INVALID_TYPE", "provided logo has to be a png, jpeg or webp image")
+
+	// ErrUserNotFound represents an error when the user does not exist.
+	ErrUserNotFound error = NewNotFoundError("USER_NOT_FOUND", "user not found")
+
+	// ErrUserInvalidPhoneNumber represents an error when the user phone number has no known country code.
+	ErrUserInvalidPhoneNumber error = NewBadRequestError("USER_INVALID_PHONE", "provided phone number is invalid, please include the country code")
+
+	// ErrInvalidCurrentPassword represents an error when the current password given to change the password is wrong.
+	ErrInvalidCurrentPassword error = NewBadRequestError("AUTH_INVALID_CURRENT_PASSWORD", "provided current password is invalid")
+
+	// ErrNewPasswordSameAsCurrent represents an error when the new password is the current password.
+	ErrNewPasswordSameAsCurrent error = NewBadRequestError("AUTH_PASSWORD_UNCHANGED", "please provide a password different from your current password")
+
+	// ErrCompanyMembershipNotFound represents an error when the user does not belong to the company.
+	ErrCompanyMembershipNotFound error = NewNotFoundError("COMPANY_MEMBERSHIP_NOT_FOUND", "you are not a member of this company")
+
+	// ErrCompanySwitchLocked represents an error when a session signed in with single sign-on switches to another company.
+	ErrCompanySwitchLocked error = NewBadRequestError("COMPANY_SWITCH_LOCKED", "sessions signed in with single sign-on can not switch company, sign in with your password instead")
+
+	// ErrCompanyMemberExisted represents an error when the user already belongs to the company.
+	ErrCompanyMemberExisted error = NewBadRequestError("COMPANY_MEMBER_EXISTED", "you are already a member of this company")
+
+	// ErrInvitationEmailMismatch represents an error when an invitation sent to another email address is accepted.
+	ErrInvitationEmailMismatch error = NewBadRequestError("INVITATION_EMAIL_MISMATCH", "this invitation was sent to another email address")
+
+	// ErrCompanySuspended represents an error when the company is suspended by the platform operators.
+	ErrCompanySuspended error = NewBadRequestError("COMPANY_SUSPENDED", "this company is suspended, please contact support")
+
+	// ErrCompanyNotSuspended represents an error when reactivating a company which is not suspended.
+	ErrCompanyNotSuspended error = NewBadRequestError("COMPANY_NOT_SUSPENDED", "this company is not suspended")
+
+	// ErrCompanySuspendSelf represents an error when a platform operator suspends their own company.
+	ErrCompanySuspendSelf error = NewBadRequestError("COMPANY_SUSPEND_SELF", "you can not suspend your own company")
+
+	// ErrImpersonationTargetNotFound represents an error when the company has no admin to impersonate.
+	ErrImpersonationTargetNotFound error = NewNotFoundError("IMPERSONATION_TARGET_NOT_FOUND", "no admin of this company can be impersonated")
+
+	// ErrPlanEventQuotaExceeded represents an error when creating an event would exceed the active events of the plan.
+	ErrPlanEventQuotaExceeded error = NewQuotaExceededError("PLAN_EVENT_QUOTA_EXCEEDED", "your plan does not allow more active events, please upgrade it")
+
+	// ErrPlanGuestQuotaExceeded represents an error when adding guests would exceed the guests per event of the plan.
+	ErrPlanGuestQuotaExceeded error = NewQuotaExceededError("PLAN_GUEST_QUOTA_EXCEEDED", "your plan does not allow more guests for this event, please upgrade it")
+
+	// ErrPlanMessageQuotaExceeded represents an error when sending a message would exceed the monthly messages of the plan.
+	ErrPlanMessageQuotaExceeded error = NewQuotaExceededError("PLAN_MESSAGE_QUOTA_EXCEEDED", "your plan does not allow more messages this month, please upgrade it")
+
+	// ErrPlanNotFound represents an error when the requested plan does not exist.
+	ErrPlanNotFound error = NewNotFoundError("PLAN_NOT_FOUND", "plan not found")
+
+	// ErrGuestContactMissing represents an error when neither an email address nor a phone number identifies the guest.
+	ErrGuestContactMissing error = NewBadRequestError("GUEST_CONTACT_MISSING", "an email address or a phone number is required")
+
+	// ErrSessionNotFound represents an error when the session does not exist, belongs to another user or is already revoked.
+	ErrSessionNotFound error = NewNotFoundError("SESSION_NOT_FOUND", "session not found")
+
+	// ErrEventTitleEmpty represents an error when an event is given an empty title.
+	ErrEventTitleEmpty error = NewBadRequestError("EVENT_TITLE_EMPTY", "event title can not be empty")
+
+	// ErrEventInvalidSchedule represents an error when an event would end before it starts.
+	ErrEventInvalidSchedule error = NewBadRequestError("EVENT_INVALID_SCHEDULE", "the end date of the event must be after its start date")
+
+	// ErrEventInvalidGuestCount represents an error when an event is given a negative guest count.
+	ErrEventInvalidGuestCount error = NewBadRequestError("EVENT_INVALID_GUEST_COUNT", "guest count can not be negative")
+
+	// ErrEventVersionMismatch represents an error when the event was changed since the caller retrieved it.
+	ErrEventVersionMismatch error = NewPreconditionFailedError("EVENT_VERSION_MISMATCH", "this event was changed in the meantime, please review it and try again")
+
+	// ErrGuestListVersionMismatch represents an error when the guests of the event were changed since the caller retrieved them.
+	ErrGuestListVersionMismatch error = NewPreconditionFailedError("GUEST_LIST_VERSION_MISMATCH", "the guests of this event were changed in the meantime, please review them and try again")
+
+	// ErrEventListInvalidType represents an error when the event list is filtered on an unknown event type.
+	ErrEventListInvalidType error = NewBadRequestError("EVENT_LIST_INVALID_TYPE", "unknown event type")
+
+	// ErrEventListInvalidTiming represents an error when the event list is filtered on an unknown status.
+	ErrEventListInvalidTiming error = NewBadRequestError("EVENT_LIST_INVALID_STATUS", "status must be one of upcoming, ongoing or past")
+
+	// ErrEventListInvalidLifecycle represents an error when the event list is filtered on an unknown lifecycle status.
+	ErrEventListInvalidLifecycle error = NewBadRequestError("EVENT_LIST_INVALID_LIFECYCLE", "lifecycle must be one of draft, published, ongoing, completed, cancelled or archived")
+
+	// ErrEventListInvalidPeriod represents an error when the event list is filtered on a period ending before it starts.
+	ErrEventListInvalidPeriod error = NewBadRequestError("EVENT_LIST_INVALID_PERIOD", "the end of the period must be after its start")
+
+	// ErrEventListInvalidSort represents an error when the event list is sorted on an unknown column or direction.
+	ErrEventListInvalidSort error = NewBadRequestError("EVENT_LIST_INVALID_SORT", "events can be sorted on title, start_date, end_date, created_at or guest_count, in asc or desc order")
+
+	// ErrGuestListInvalidCursor represents an error when the cursor of a guest list page is malformed.
+	ErrGuestListInvalidCursor error = NewBadRequestError("GUEST_LIST_INVALID_CURSOR", "invalid guest list cursor")
+
+	// ErrGuestFileInvalid represents an error when an imported guest file is not a readable guest list.
+	ErrGuestFileInvalid error = NewBadRequestError("GUEST_FILE_INVALID", "the guest file must be a .csv or .xlsx file with name, phone, email and vip columns")
+
+	// ErrEventInvalidStatus represents an error when an event is given an unknown status.
+	ErrEventInvalidStatus error = NewBadRequestError("EVENT_INVALID_STATUS", "status must be one of draft, published, ongoing, completed, cancelled or archived")
+
+	// ErrEventInitialStatus represents an error when an event is created in another status than draft or published.
+	ErrEventInitialStatus error = NewBadRequestError("EVENT_INITIAL_STATUS", "an event can only be created as draft or published")
+
+	// ErrEventStatusTransition represents an error when an event can not move from its status to the requested one.
+	ErrEventStatusTransition error = NewBadRequestError("EVENT_STATUS_TRANSITION_NOT_ALLOWED", "the event can not move from its current status to the requested one")
+
+	// ErrEventClosedForRSVP represents an error when a guest answers the invitation of a draft, cancelled or finished event.
+	ErrEventClosedForRSVP error = NewBadRequestError("EVENT_CLOSED_FOR_RSVP", "this event does not accept answers to its invitation")
 )
@@ -1,6 +1,9 @@
 package entity
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // EventType represents the type of an event.
 type EventType string
@@ -80,19 +83,202 @@ func ParseEventType(event string) EventType {
 	}
 }
 
+// EventStatus is the stage of the lifecycle of an event.
+type EventStatus string
+
+var (
+	// EventStatusDraft represents an event being prepared, its public invitation is closed.
+	EventStatusDraft EventStatus = "draft"
+
+	// EventStatusPublished represents an event open to its guests.
+	EventStatusPublished EventStatus = "published"
+
+	// EventStatusOngoing represents an event taking place.
+	EventStatusOngoing EventStatus = "ongoing"
+
+	// EventStatusCompleted represents an event which took place.
+	EventStatusCompleted EventStatus = "completed"
+
+	// EventStatusCancelled represents an event which will not take place, its public invitation is closed.
+	EventStatusCancelled EventStatus = "cancelled"
+
+	// EventStatusArchived represents an event kept for the record, left out of the event list unless asked for.
+	EventStatusArchived EventStatus = "archived"
+)
+
+// eventStatusTransitions lists the statuses an event can move to from each status.
+var eventStatusTransitions = map[EventStatus][]EventStatus{
+	EventStatusDraft:     {EventStatusPublished, EventStatusCancelled, EventStatusArchived},
+	EventStatusPublished: {EventStatusOngoing, EventStatusCancelled},
+	EventStatusOngoing:   {EventStatusCompleted, EventStatusCancelled},
+	EventStatusCompleted: {EventStatusArchived},
+	EventStatusCancelled: {EventStatusArchived},
+}
+
+// ParseEventStatus converts a string to an EventStatus, it reports false when it is not a known status.
+func ParseEventStatus(status string) (EventStatus, bool) {
+	switch EventStatus(status) {
+	case EventStatusDraft,
+		EventStatusPublished,
+		EventStatusOngoing,
+		EventStatusCompleted,
+		EventStatusCancelled,
+		EventStatusArchived:
+		return EventStatus(status), true
+	default:
+		return "", false
+	}
+}
+
+// CanTransitionTo reports whether an event can move from status s to next.
+func (s EventStatus) CanTransitionTo(next EventStatus) bool {
+	return slices.Contains(eventStatusTransitions[s], next)
+}
+
+// AcceptsRSVP reports whether guests can answer the public invitation of an event in status s.
+func (s EventStatus) AcceptsRSVP() bool {
+	return s == EventStatusPublished || s == EventStatusOngoing
+}
+
 // Event represents an event entity with relevant metadata.
+// Version is incremented on every change, so concurrent changes can be detected.
 type Event struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Type        EventType `json:"type"`
-	Description string    `json:"description"`
-	Location    string    `json:"location"`
-	StartDate   time.Time `json:"startDate"`
-	EndDate     time.Time `json:"endDate"`
-	CreatedBy   IDName    `json:"createdBy"`
-	Company     IDName    `json:"company"`
-	GuestCount  int       `json:"guestCount"`
-	Guests      []Guest   `json:"guests"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          int         `json:"id"`
+	Title       string      `json:"title"`
+	Type        EventType   `json:"type"`
+	Description string      `json:"description"`
+	Location    string      `json:"location"`
+	StartDate   time.Time   `json:"startDate"`
+	EndDate     time.Time   `json:"endDate"`
+	CreatedBy   IDName      `json:"createdBy"`
+	Company     IDName      `json:"company"`
+	GuestCount  int         `json:"guestCount"`
+	Guests      []Guest     `json:"guests"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	Version     int         `json:"version"`
+	Status      EventStatus `json:"status"`
+}
+
+// EventUpdate holds the fields of an event to change, nil fields are left unchanged.
+type EventUpdate struct {
+	Title       *string
+	Type        *EventType
+	Description *string
+	Location    *string
+	StartDate   *time.Time
+	EndDate     *time.Time
+	GuestCount  *int
+}
+
+// ApplyTo returns a copy of event with the supplied fields of the update.
+func (u EventUpdate) ApplyTo(event Event) Event {
+	if u.Title != nil {
+		event.Title = *u.Title
+	}
+	if u.Type != nil {
+		event.Type = *u.Type
+	}
+	if u.Description != nil {
+		event.Description = *u.Description
+	}
+	if u.Location != nil {
+		event.Location = *u.Location
+	}
+	if u.StartDate != nil {
+		event.StartDate = *u.StartDate
+	}
+	if u.EndDate != nil {
+		event.EndDate = *u.EndDate
+	}
+	if u.GuestCount != nil {
+		event.GuestCount = *u.GuestCount
+	}
+
+	return event
+}
+
+// EventTiming tells whether an event is yet to start, taking place or over.
+type EventTiming string
+
+var (
+	// EventTimingUpcoming represents an event which has not started yet.
+	EventTimingUpcoming EventTiming = "upcoming"
+
+	// EventTimingOngoing represents an event which has started and is not over yet.
+	EventTimingOngoing EventTiming = "ongoing"
+
+	// EventTimingPast represents an event which is over.
+	EventTimingPast EventTiming = "past"
+)
+
+// TimingAt returns whether the event is upcoming, ongoing or past at the given time.
+func (e Event) TimingAt(now time.Time) EventTiming {
+	switch {
+	case now.After(e.EndDate):
+		return EventTimingPast
+	case now.Before(e.StartDate):
+		return EventTimingUpcoming
+	default:
+		return EventTimingOngoing
+	}
+}
+
+// EventSortField is a column the events of a company can be sorted on.
+type EventSortField string
+
+var (
+	// EventSortFieldTitle sorts events by title.
+	EventSortFieldTitle EventSortField = "title"
+
+	// EventSortFieldStartDate sorts events by start date.
+	EventSortFieldStartDate EventSortField = "start_date"
+
+	// EventSortFieldEndDate sorts events by end date.
+	EventSortFieldEndDate EventSortField = "end_date"
+
+	// EventSortFieldCreatedAt sorts events by creation date, the default.
+	EventSortFieldCreatedAt EventSortField = "created_at"
+
+	// EventSortFieldGuestCount sorts events by guest count.
+	EventSortFieldGuestCount EventSortField = "guest_count"
+)
+
+// SortOrder is the direction records are sorted in.
+type SortOrder string
+
+var (
+	// SortOrderAsc sorts records from the lowest to the highest value.
+	SortOrderAsc SortOrder = "asc"
+
+	// SortOrderDesc sorts records from the highest to the lowest value.
+	SortOrderDesc SortOrder = "desc"
+)
+
+// Keys of PaginationRequest.Field filtering and sorting the events of a company.
+var (
+	EventFieldSearch    = "search"
+	EventFieldType      = "type"
+	EventFieldFrom      = "from"
+	EventFieldTo        = "to"
+	EventFieldTiming    = "status"
+	EventFieldLifecycle = "lifecycle"
+	EventFieldCreatedBy = "created_by"
+	EventFieldSort      = "sort"
+	EventFieldOrder     = "order"
+)
+
+// EventListFilter narrows down and sorts the events of a company. Zero values do not filter,
+// except for Status: archived events are left out unless it asks for them.
+// From and To select the events taking place, even partially, within the period.
+type EventListFilter struct {
+	Search    string
+	Type      EventType
+	From      *time.Time
+	To        *time.Time
+	Timing    EventTiming
+	Status    EventStatus
+	CreatedBy int
+	SortBy    EventSortField
+	Order     SortOrder
 }
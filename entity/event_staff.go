@@ -0,0 +1,27 @@
+package entity
+
+import "slices"
+
+// EventPermissions lists the permissions that can be granted to a staff member on a single event.
+// Messages are only sent when cancelling an event, which takes the company-wide event:update permission,
+// so message:send can not be granted for a single event.
+var EventPermissions = []Permission{
+	PermissionGuestView,
+	PermissionGuestEdit,
+	PermissionGuestCheckIn,
+}
+
+// EventStaff represents a user assigned to an event with a set of permissions.
+type EventStaff struct {
+	EventID     int          `json:"eventId"`
+	UserID      int          `json:"userId"`
+	Name        string       `json:"name"`
+	Email       string       `json:"email"`
+	Role        UserRole     `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Can returns true when the staff member was granted the given permission.
+func (s EventStaff) Can(permission Permission) bool {
+	return slices.Contains(s.Permissions, permission)
+}
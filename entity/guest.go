@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 // Guest represents an event guest with their details.
 type Guest struct {
 	ID          int    `json:"id"`
@@ -15,7 +17,56 @@ type Guest struct {
 	Message     string `json:"message"`
 }
 
+// GuestListFilter narrows down the guests of an event. Nil and empty values do not filter.
+// Search matches the name or the phone number of the guests containing it, or their barcode.
+type GuestListFilter struct {
+	Search      string
+	IsVIP       *bool
+	IsAttending *bool
+	CheckedIn   *bool
+}
+
+// GuestCursor identifies the last guest of a page of a guest list, the next page starts after it.
+// Guest lists are sorted VIP guests first, then by ID.
+type GuestCursor struct {
+	IsVIP bool
+	ID    int
+}
+
+// GuestPage is a page of the guest list of an event.
+// NextCursor is given to retrieve the following page, it is empty on the last page.
+type GuestPage struct {
+	Guests     []Guest
+	NextCursor string
+}
+
 type GuestMessages struct {
 	Name    string `json:"name"`
 	Message string `json:"message"`
 }
+
+// AnonymisedGuestName replaces the name of the guests whose personal data was erased.
+const AnonymisedGuestName = "Anonymised guest"
+
+// GuestPersonalData represents everything stored about a guest of an event, as exported on their request.
+type GuestPersonalData struct {
+	GuestID        int        `json:"guestId"`
+	EventID        int        `json:"eventId"`
+	EventTitle     string     `json:"eventTitle"`
+	EventStartDate time.Time  `json:"eventStartDate"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	Phone          string     `json:"phone"`
+	IsVIP          bool       `json:"vip"`
+	IsAttending    bool       `json:"isAttending"`
+	Message        string     `json:"message"`
+	CheckedIn      bool       `json:"checkedIn"`
+	CheckedInAt    *time.Time `json:"checkedInAt"`
+	BarcodeID      string     `json:"barcode"`
+}
+
+// GuestContact identifies a person among the guests of a company by their email address or phone number.
+type GuestContact struct {
+	Email string
+	Phone string
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// CompanyInvitation represents an invitation for a person to join a company,
+// sent either by email or by phone. Only the hash of the invitation token is stored.
+type CompanyInvitation struct {
+	ID         int        `json:"id"`
+	CompanyID  int        `json:"company_id"`
+	Email      *string    `json:"email"`
+	Phone      *string    `json:"phone"`
+	Role       UserRole   `json:"role"`
+	TokenHash  string     `json:"-"`
+	InvitedBy  int        `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsExpired returns true when the invitation is already past its expiry time.
+func (i CompanyInvitation) IsExpired() bool {
+	return time.Now().After(i.ExpiresAt)
+}
+
+// InvitableRoles lists the roles a company admin can grant to its members.
+var InvitableRoles = []UserRole{UserRoleEOOrganizer, UserRoleCrew}
@@ -0,0 +1,9 @@
+package entity
+
+// Notification represents a message delivered to a user outside of the API,
+// such as a password reset link.
+type Notification struct {
+	Recipient string
+	Subject   string
+	Body      string
+}
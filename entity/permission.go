@@ -0,0 +1,105 @@
+package entity
+
+import "slices"
+
+// Permission represents an action a user is allowed to perform.
+type Permission string
+
+var (
+	// PermissionEventView allows a user to see the events of their company.
+	PermissionEventView Permission = "event:view"
+
+	// PermissionEventCreate allows a user to create events for their company.
+	PermissionEventCreate Permission = "event:create"
+
+	// PermissionEventUpdate allows a user to update the events of their company.
+	PermissionEventUpdate Permission = "event:update"
+
+	// PermissionEventDelete allows a user to delete the events of their company.
+	PermissionEventDelete Permission = "event:delete"
+
+	// PermissionEventStaff allows a user to assign staff to the events of their company.
+	PermissionEventStaff Permission = "event:staff"
+
+	// PermissionGuestView allows a user to see the guest list of an event.
+	PermissionGuestView Permission = "guest:view"
+
+	// PermissionGuestEdit allows a user to add, import and remove the guests of an event.
+	PermissionGuestEdit Permission = "guest:edit"
+
+	// PermissionGuestCheckIn allows a user to check guests in at the door.
+	PermissionGuestCheckIn Permission = "guest:checkin"
+
+	// PermissionMessageSend allows a user to send messages to the guests of an event.
+	PermissionMessageSend Permission = "message:send"
+
+	// PermissionTeamManage allows a user to invite, update and remove the members of their company.
+	PermissionTeamManage Permission = "team:manage"
+
+	// PermissionRoleManage allows a user to customise the permissions granted to each role of their company.
+	PermissionRoleManage Permission = "role:manage"
+
+	// PermissionAPIKeyManage allows a user to create, list and revoke the API keys of their company.
+	PermissionAPIKeyManage Permission = "apikey:manage"
+
+	// PermissionAuditView allows a user to browse the audit log of their company.
+	PermissionAuditView Permission = "audit:view"
+
+	// PermissionGuestPrivacy allows a user to export and erase the personal data of the guests of their company.
+	PermissionGuestPrivacy Permission = "guest:privacy"
+)
+
+// Permissions is the registry of every known permission.
+var Permissions = []Permission{
+	PermissionEventView,
+	PermissionEventCreate,
+	PermissionEventUpdate,
+	PermissionEventDelete,
+	PermissionEventStaff,
+	PermissionGuestView,
+	PermissionGuestEdit,
+	PermissionGuestCheckIn,
+	PermissionMessageSend,
+	PermissionTeamManage,
+	PermissionRoleManage,
+	PermissionAPIKeyManage,
+	PermissionAuditView,
+	PermissionGuestPrivacy,
+}
+
+// DefaultRolePermissions maps every role to the permissions it is granted when its company did not customise them.
+var DefaultRolePermissions = map[UserRole][]Permission{
+	UserRoleSuperAdmin: Permissions,
+	UserRoleEOOrganizer: {
+		PermissionEventView,
+		PermissionEventCreate,
+		PermissionEventUpdate,
+		PermissionEventStaff,
+		PermissionGuestView,
+		PermissionGuestEdit,
+		PermissionGuestCheckIn,
+		PermissionMessageSend,
+		PermissionTeamManage,
+		PermissionRoleManage,
+		PermissionAPIKeyManage,
+		PermissionAuditView,
+		PermissionGuestPrivacy,
+	},
+	UserRoleHost: {
+		PermissionEventView,
+		PermissionEventCreate,
+		PermissionEventUpdate,
+		PermissionEventStaff,
+		PermissionGuestView,
+		PermissionGuestEdit,
+		PermissionGuestCheckIn,
+		PermissionMessageSend,
+	},
+	UserRoleCrew:  {},
+	UserRoleGuest: {},
+}
+
+// IsValidPermission returns true when the permission is part of the registry.
+func IsValidPermission(permission Permission) bool {
+	return slices.Contains(Permissions, permission)
+}
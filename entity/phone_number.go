@@ -244,3 +244,18 @@ func ParsePhoneNumber(input string) (string, string, error) {
 
 	return "", "", errors.New("unknown or missing country code")
 }
+
+// NormalizePhoneNumber returns the phone number as `+<country code><local number>`.
+// The input has to start with a known country code, separators such as spaces or dashes are ignored.
+func NormalizePhoneNumber(input string) (string, error) {
+	countryCode, localNumber, err := ParsePhoneNumber(input)
+	if err != nil {
+		return "", err
+	}
+
+	if len(localNumber) < 6 {
+		return "", errors.New("phone number is too short")
+	}
+
+	return "+" + countryCode + localNumber, nil
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// DefaultPlanCode is the plan of the companies created by signing up.
+const DefaultPlanCode = "free"
+
+// Plan represents the tier a company subscribed to, with the usage it allows.
+// A nil limit is unlimited.
+type Plan struct {
+	Code                string
+	Name                string
+	MaxActiveEvents     *int
+	MaxGuestsPerEvent   *int
+	MaxMessagesPerMonth *int
+}
+
+// PlanUsage represents the usage of a company counted against the limits of its plan.
+// Active events are the events which are not deleted and not over yet, LargestEventGuests is the
+// number of guests of the active event having the most, messages are counted from PeriodStart to PeriodEnd.
+type PlanUsage struct {
+	Plan               Plan
+	ActiveEvents       int
+	LargestEventGuests int
+	MessagesThisPeriod int
+	PeriodStart        time.Time
+	PeriodEnd          time.Time
+}
+
+// WithinLimit returns true when value does not exceed limit, a nil limit is unlimited.
+func WithinLimit(limit *int, value int) bool {
+	return limit == nil || value <= *limit
+}
+
+// MessagePeriod returns the calendar month, in UTC, messages sent at t are counted in.
+func MessagePeriod(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// SignInAttempt represents the failed sign in attempts made against a throttling key,
+// such as an email address or an IP address.
+type SignInAttempt struct {
+	Key          string
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
+
+// IsLocked returns true when sign in attempts against the key are refused at the given time.
+func (a SignInAttempt) IsLocked(at time.Time) bool {
+	return a.LockedUntil != nil && at.Before(*a.LockedUntil)
+}
@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// CompanySSOConfig represents the OpenID Connect provider a company signs its members in with.
+// Members signing in for the first time are created with DefaultRole.
+type CompanySSOConfig struct {
+	CompanyID    int       `json:"companyId"`
+	Issuer       string    `json:"issuer"`
+	ClientID     string    `json:"clientId"`
+	ClientSecret string    `json:"-"`
+	DefaultRole  UserRole  `json:"defaultRole"`
+	Enabled      bool      `json:"enabled"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// SSOLoginState represents a pending single sign-on, created when the user is sent to the identity provider.
+// Only the hash of the state is stored, CodeVerifier is the PKCE secret sent along with the authorization code.
+type SSOLoginState struct {
+	ID           int
+	CompanyID    int
+	StateHash    string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+	UsedAt       *time.Time
+	CreatedAt    time.Time
+}
+
+// IsExpired returns true when the login state is already past its expiry time.
+func (s SSOLoginState) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+// UserIdentity links a user to the subject identifying them at an identity provider.
+type UserIdentity struct {
+	ID        int
+	UserID    int
+	Issuer    string
+	Subject   string
+	CreatedAt time.Time
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -23,24 +24,34 @@ var (
 
 	// UserRoleGuest represents a guest role.
 	UserRoleGuest UserRole = "guest"
+
+	// UserRoleAPIKey represents a request authenticated with an API key rather than by a user.
+	UserRoleAPIKey UserRole = "api_key"
 )
 
 // User represents a user entity with personal and contact information.
 type User struct {
-	ID          int        `json:"id"`
-	FirstName   string     `json:"first_name"`
-	LastName    *string    `json:"last_name"`
-	Role        UserRole   `json:"role"`
-	Email       string     `json:"email"`
-	Password    string     `json:"-"`
-	PhoneNumber *string    `json:"phone_number"`
-	JobTitle    *string    `json:"job_title"`
-	CompanyID   *int       `json:"company_id"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `json:"-"`
+	ID                 int        `json:"id"`
+	FirstName          string     `json:"first_name"`
+	LastName           *string    `json:"last_name"`
+	Role               UserRole   `json:"role"`
+	Email              string     `json:"email"`
+	Password           string     `json:"-"`
+	PhoneNumber        *string    `json:"phone_number"`
+	JobTitle           *string    `json:"job_title"`
+	CompanyID          *int       `json:"company_id"`
+	PasswordChangedAt  *time.Time `json:"-"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	TwoFactorSecret    *string    `json:"-"`
+	TwoFactorEnabledAt *time.Time `json:"-"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	DeletedAt          *time.Time `json:"-"`
 }
 
+// AdminRoles lists the roles a company can require two-factor authentication for.
+var AdminRoles = []UserRole{UserRoleSuperAdmin, UserRoleEOOrganizer}
+
 // GetName returns the full name of the user.
 // If the LastName field is nil, it returns only the FirstName.
 func (u User) GetName() string {
@@ -58,3 +69,18 @@ func (u User) GetCompanyID() int {
 
 	return *u.CompanyID
 }
+
+// IsEmailVerified returns true when the user has confirmed their email address.
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsAdmin returns true when the user has one of the AdminRoles.
+func (u User) IsAdmin() bool {
+	return slices.Contains(AdminRoles, u.Role)
+}
+
+// IsTwoFactorEnabled returns true when the user completed the two-factor authentication enrolment.
+func (u User) IsTwoFactorEnabled() bool {
+	return u.TwoFactorEnabledAt != nil
+}
@@ -1,19 +1,188 @@
 package pkg
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// PasswordAlgorithmBcrypt hashes passwords with bcrypt.
+	PasswordAlgorithmBcrypt = "bcrypt"
+
+	// PasswordAlgorithmArgon2id hashes passwords with argon2id.
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+const (
+	// defaultBcryptCost is used when the configured bcrypt cost is not set.
+	defaultBcryptCost = 12
+
+	// defaultArgon2Memory is the memory, in KiB, used when the configured argon2id memory is not set.
+	defaultArgon2Memory = 64 * 1024
+
+	// defaultArgon2Iterations is used when the configured argon2id iterations are not set.
+	defaultArgon2Iterations = 3
+
+	// defaultArgon2Parallelism is used when the configured argon2id parallelism is not set.
+	defaultArgon2Parallelism = 2
+
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+// HasherConfig holds the algorithm and the parameters new password hashes are produced with.
+// Argon2Memory is expressed in KiB.
+type HasherConfig struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+func (c HasherConfig) withDefaults() HasherConfig {
+	if c.Algorithm != PasswordAlgorithmArgon2id {
+		c.Algorithm = PasswordAlgorithmBcrypt
+	}
+
+	if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+		c.BcryptCost = defaultBcryptCost
+	}
+
+	if c.Argon2Memory == 0 {
+		c.Argon2Memory = defaultArgon2Memory
+	}
+
+	if c.Argon2Iterations == 0 {
+		c.Argon2Iterations = defaultArgon2Iterations
+	}
+
+	if c.Argon2Parallelism == 0 {
+		c.Argon2Parallelism = defaultArgon2Parallelism
+	}
+
+	return c
+}
 
 // Hasher struct provides methods for hashing and comparing passwords.
-type Hasher struct{}
+// The algorithm and its parameters are stored in the hash string, so hashes produced
+// with a previous configuration can still be compared and detected by NeedsRehash.
+// The zero value hashes with bcrypt at the default cost.
+type Hasher struct {
+	config HasherConfig
+}
+
+// NewHasher creates a new Hasher producing hashes with the given configuration.
+func NewHasher(config HasherConfig) Hasher {
+	return Hasher{config: config.withDefaults()}
+}
 
-// HashPassword hashes a plain-text password using bcrypt with the minimum cost parameter.
-// It returns the hashed password as a string and any error encountered during hashing.
+// HashPassword hashes a plain-text password with the configured algorithm.
+// bcrypt hashes use the modular crypt format (`$2a$<cost>$...`),
+// argon2id hashes the PHC string format (`$argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>`).
 func (h Hasher) HashPassword(plainPassword string) (hashedPassword string, err error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(plainPassword), bcrypt.MinCost)
+	config := h.config.withDefaults()
+
+	if config.Algorithm == PasswordAlgorithmArgon2id {
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+
+		key := argon2.IDKey([]byte(plainPassword), salt, config.Argon2Iterations, config.Argon2Memory, config.Argon2Parallelism, argon2KeyLength)
+		return fmt.Sprintf(
+			"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version,
+			config.Argon2Memory,
+			config.Argon2Iterations,
+			config.Argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key),
+		), nil
+	}
+
+	bytes, err := bcrypt.GenerateFromPassword([]byte(plainPassword), config.BcryptCost)
 	return string(bytes), err
 }
 
-// ComparePassword checks if a given plain-text password matches a hashed password.
+// ComparePassword checks if a given plain-text password matches a hashed password,
+// whichever algorithm produced the hash.
 // It returns `true` if the password matches, otherwise `false`.
 func (h Hasher) ComparePassword(password, hashedPassword string) (passwordIsValid bool) {
+	if strings.HasPrefix(hashedPassword, "$argon2id$") {
+		params, salt, key, err := parseArgon2Hash(hashedPassword)
+		if err != nil {
+			return false
+		}
+
+		computed := argon2.IDKey([]byte(password), salt, params.Argon2Iterations, params.Argon2Memory, params.Argon2Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(computed, key) == 1
+	}
+
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
 }
+
+// NeedsRehash returns true when the hash was produced with another algorithm than the configured one,
+// or with weaker parameters, and should be replaced the next time the plain-text password is known.
+func (h Hasher) NeedsRehash(hashedPassword string) bool {
+	config := h.config.withDefaults()
+
+	if strings.HasPrefix(hashedPassword, "$argon2id$") {
+		if config.Algorithm != PasswordAlgorithmArgon2id {
+			return true
+		}
+
+		params, _, _, err := parseArgon2Hash(hashedPassword)
+		if err != nil {
+			return true
+		}
+
+		return params.Argon2Memory < config.Argon2Memory ||
+			params.Argon2Iterations < config.Argon2Iterations ||
+			params.Argon2Parallelism < config.Argon2Parallelism
+	}
+
+	if config.Algorithm != PasswordAlgorithmBcrypt {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost < config.BcryptCost
+}
+
+// parseArgon2Hash extracts the parameters, the salt and the key of an argon2id PHC string.
+func parseArgon2Hash(hashedPassword string) (params HasherConfig, salt, key []byte, err error) {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Iterations, &params.Argon2Parallelism); err != nil {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	params.Algorithm = PasswordAlgorithmArgon2id
+	return params, salt, key, nil
+}
@@ -12,14 +12,21 @@ import (
 // TokenClaims defines the structure for JWT payload claims.
 // It includes standard claims such as expiration time, issuer,
 // and custom claims like the user ID and email.
+// ImpersonatorID is set on tokens issued to a platform operator acting as the user.
+// SessionID is the session the token was issued for, it is not set on impersonation tokens.
 type TokenClaims struct {
 	jwt.StandardClaims
-	ID        int             `json:"user_id"`
-	CompanyID int             `json:"company_id"`
-	Email     string          `json:"email"`
-	Role      entity.UserRole `json:"role"`
+	ID             int             `json:"user_id"`
+	CompanyID      int             `json:"company_id"`
+	Email          string          `json:"email"`
+	Role           entity.UserRole `json:"role"`
+	ImpersonatorID int             `json:"impersonator_id,omitempty"`
+	SessionID      int             `json:"session_id,omitempty"`
 }
 
+// defaultAccessTokenTTL is used when the configured access token duration is not set.
+const defaultAccessTokenTTL = 15 * time.Minute
+
 // JwtGenerator is responsible for generating and validating JWT tokens.
 // It holds necessary configurations such as the application's name,
 // token expiration duration, signing method, and signature key.
@@ -27,6 +34,7 @@ type JwtGenerator struct {
 	applicationName string
 	signingMethod   *jwt.SigningMethodHMAC
 	signatureKey    string
+	accessTokenTTL  time.Duration
 }
 
 // NewJwtGenerator creates and returns a new JwtGenerator instance.
@@ -35,28 +43,55 @@ type JwtGenerator struct {
 func NewJwtGenerator(
 	applicationName string,
 	signatureKey string,
+	accessTokenTTL time.Duration,
 ) *JwtGenerator {
+	if accessTokenTTL <= 0 {
+		accessTokenTTL = defaultAccessTokenTTL
+	}
+
 	return &JwtGenerator{
 		applicationName: applicationName,
 		signingMethod:   jwt.SigningMethodHS256,
 		signatureKey:    signatureKey,
+		accessTokenTTL:  accessTokenTTL,
+	}
+}
+
+// CreateAccessToken generates a JWT token containing the user's ID and email, issued for the given session.
+// The token is signed using the configured signing method and secret key,
+// and expires after the configured access token duration.
+func (g JwtGenerator) CreateAccessToken(userID int, CompanyID int, email string, userRole entity.UserRole, sessionID int) (response *entity.AuthResponse, err error) {
+	return g.createToken(userID, CompanyID, email, userRole, 0, sessionID, g.accessTokenTTL)
+}
+
+// CreateImpersonationToken generates a JWT token letting the platform operator impersonatorID act as the user.
+// The token expires after ttl, or after the configured access token duration when ttl is not set.
+func (g JwtGenerator) CreateImpersonationToken(userID int, companyID int, email string, userRole entity.UserRole, impersonatorID int, ttl time.Duration) (response *entity.AuthResponse, err error) {
+	if ttl <= 0 {
+		ttl = g.accessTokenTTL
 	}
+
+	return g.createToken(userID, companyID, email, userRole, impersonatorID, 0, ttl)
 }
 
-// CreateAccessToken generates a JWT token containing the user's ID and email.
-// The token is signed using the configured signing method and secret key.
-func (g JwtGenerator) CreateAccessToken(userID int, CompanyID int, email string, userRole entity.UserRole) (response *entity.AuthResponse, err error) {
+func (g JwtGenerator) createToken(userID int, CompanyID int, email string, userRole entity.UserRole, impersonatorID, sessionID int, ttl time.Duration) (response *entity.AuthResponse, err error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
 	claims := TokenClaims{
 		StandardClaims: jwt.StandardClaims{
 			Subject:   strconv.Itoa(userID),
 			Issuer:    g.applicationName,
-			NotBefore: time.Now().Unix(),
-			IssuedAt:  time.Now().Unix(),
+			NotBefore: now.Unix(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
 		},
-		ID:        userID,
-		CompanyID: CompanyID,
-		Email:     email,
-		Role:      userRole,
+		ID:             userID,
+		CompanyID:      CompanyID,
+		Email:          email,
+		Role:           userRole,
+		ImpersonatorID: impersonatorID,
+		SessionID:      sessionID,
 	}
 
 	token := jwt.NewWithClaims(g.signingMethod, claims)
@@ -68,6 +103,7 @@ func (g JwtGenerator) CreateAccessToken(userID int, CompanyID int, email string,
 
 	return &entity.AuthResponse{
 		AccessToken: signedToken,
+		ExpiresAt:   expiresAt.Format(time.RFC3339),
 		Email:       email,
 		Role:        userRole,
 	}, nil
@@ -86,7 +122,10 @@ func (g JwtGenerator) ParseToken(accessToken string) (*TokenClaims, error) {
 
 	if err != nil {
 		log.Warnf("[JwtGenerator.ParseToken] Error parsing token: %v", err)
-		return nil, entity.ErrAuthTokenIsExpired
+		if validationErr, ok := err.(*jwt.ValidationError); ok && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, entity.ErrAuthTokenIsExpired
+		}
+		return nil, entity.ErrInvalidAccessToken
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
@@ -100,11 +139,19 @@ func (g JwtGenerator) ParseToken(accessToken string) (*TokenClaims, error) {
 	ID, _ := claims["user_id"].(float64)
 	companyID, _ := claims["company_id"].(float64)
 	userRole, _ := claims["role"].(string)
+	issuedAt, _ := claims["iat"].(float64)
+	impersonatorID, _ := claims["impersonator_id"].(float64)
+	sessionID, _ := claims["session_id"].(float64)
 
 	return &TokenClaims{
-		ID:        int(ID),
-		CompanyID: int(companyID),
-		Email:     email,
-		Role:      entity.UserRole(userRole),
+		StandardClaims: jwt.StandardClaims{
+			IssuedAt: int64(issuedAt),
+		},
+		ID:             int(ID),
+		CompanyID:      int(companyID),
+		Email:          email,
+		Role:           entity.UserRole(userRole),
+		ImpersonatorID: int(impersonatorID),
+		SessionID:      int(sessionID),
 	}, nil
 }
@@ -0,0 +1,30 @@
+package pkg
+
+import "context"
+
+type requestMetadataKey struct{}
+
+// RequestMetadata describes who made a request and where it came from.
+// It is attached to the request context by the delivery layer so services can attribute what they do.
+// ActorID, CompanyID and APIKeyID are zero for unauthenticated requests,
+// ImpersonatorID is the platform operator acting as ActorID, if any.
+type RequestMetadata struct {
+	ActorID        int
+	CompanyID      int
+	APIKeyID       int
+	ImpersonatorID int
+	IP             string
+	UserAgent      string
+	RequestID      string
+}
+
+// WithRequestMetadata returns a copy of ctx carrying the given request metadata.
+func WithRequestMetadata(ctx context.Context, metadata RequestMetadata) context.Context {
+	return context.WithValue(ctx, requestMetadataKey{}, metadata)
+}
+
+// RequestMetadataFromContext returns the request metadata carried by ctx, or its zero value when there is none.
+func RequestMetadataFromContext(ctx context.Context) RequestMetadata {
+	metadata, _ := ctx.Value(requestMetadataKey{}).(RequestMetadata)
+	return metadata
+}
@@ -0,0 +1,38 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// GenerateOpaqueToken returns a random token suitable to be handed out to clients,
+// such as refresh tokens. Only the hash of the token should be persisted.
+func GenerateOpaqueToken() (string, error) {
+	return GenerateRandomString(64)
+}
+
+// HashToken returns the hex encoded SHA-256 digest of the given token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix is prepended to every API key so leaked keys can be recognised.
+const APIKeyPrefix = "gsk_"
+
+// GenerateAPIKey returns a random API key and its public prefix.
+// The prefix identifies the key in listings, only the hash of the whole key should be persisted.
+func GenerateAPIKey() (key string, prefix string, err error) {
+	id, err := GenerateRandomString(8)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := GenerateRandomString(48)
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix = APIKeyPrefix + id
+	return prefix + "_" + secret, prefix, nil
+}
@@ -0,0 +1,62 @@
+package pkg
+
+import "strings"
+
+// userAgentToken maps a token found in a User-Agent header to the name displayed to users.
+type userAgentToken struct {
+	token string
+	name  string
+}
+
+// browserTokens are checked in order, browsers based on another one also mention it in their User-Agent header.
+var browserTokens = []userAgentToken{
+	{token: "Edg/", name: "Edge"},
+	{token: "OPR/", name: "Opera"},
+	{token: "SamsungBrowser/", name: "Samsung Internet"},
+	{token: "Firefox/", name: "Firefox"},
+	{token: "FxiOS/", name: "Firefox"},
+	{token: "CriOS/", name: "Chrome"},
+	{token: "Chrome/", name: "Chrome"},
+	{token: "Safari/", name: "Safari"},
+	{token: "PostmanRuntime/", name: "Postman"},
+	{token: "curl/", name: "curl"},
+}
+
+// osTokens are checked in order, Android and iOS User-Agent headers also mention Linux and Mac OS X.
+var osTokens = []userAgentToken{
+	{token: "Android", name: "Android"},
+	{token: "iPhone", name: "iPhone"},
+	{token: "iPad", name: "iPad"},
+	{token: "Windows", name: "Windows"},
+	{token: "CrOS", name: "ChromeOS"},
+	{token: "Mac OS X", name: "macOS"},
+	{token: "Linux", name: "Linux"},
+}
+
+// DescribeDevice returns a short, human readable description of the device behind a User-Agent header,
+// such as "Chrome on Windows", so users can recognise their sessions.
+func DescribeDevice(userAgent string) string {
+	browser := findUserAgentToken(userAgent, browserTokens)
+	os := findUserAgentToken(userAgent, osTokens)
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return "Unknown device"
+	}
+}
+
+func findUserAgentToken(userAgent string, tokens []userAgentToken) string {
+	for _, candidate := range tokens {
+		if strings.Contains(userAgent, candidate.token) {
+			return candidate.name
+		}
+	}
+
+	return ""
+}
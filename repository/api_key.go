@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// APIKeyRepository provides methods for interacting with the "api_keys" database table.
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository initializes a new APIKeyRepository with a given database connection.
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// CreateAPIKey persists a new API key and returns it with its generated ID.
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, apiKey entity.APIKey) (*entity.APIKey, error) {
+	const ops = "APIKeyRepository.CreateAPIKey"
+
+	scopes := pq.StringArray{}
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	row := r.db.QueryRowContext(
+		ctx,
+		SQLStatementInsertAPIKey,
+		apiKey.CompanyID,
+		apiKey.Name,
+		apiKey.Prefix,
+		apiKey.KeyHash,
+		scopes,
+		apiKey.CreatedBy,
+		apiKey.ExpiresAt,
+	)
+
+	if err := row.Scan(&apiKey.ID, &apiKey.CreatedAt); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert api key: %v", err)
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+// FindByHash retrieves an API key based on its hash.
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectAPIKeyByHash, keyHash)
+	return scanAPIKey(row)
+}
+
+// ListCompanyAPIKeys retrieves every API key of a company that has not been revoked.
+func (r *APIKeyRepository) ListCompanyAPIKeys(ctx context.Context, companyID int) ([]entity.APIKey, error) {
+	const ops = "APIKeyRepository.ListCompanyAPIKeys"
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectCompanyAPIKeys, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch api keys: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	apiKeys := []entity.APIKey{}
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to scan an api key: %v", err)
+			return nil, err
+		}
+
+		apiKeys = append(apiKeys, *apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+// RevokeAPIKey revokes an API key of the given company.
+// It returns false when no active API key with the given ID exists in the company.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, companyID, apiKeyID int) (bool, error) {
+	const ops = "APIKeyRepository.RevokeAPIKey"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementRevokeAPIKey, apiKeyID, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke api key: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// RevokeUserAPIKeys revokes every API key a user created for the given company.
+func (r *APIKeyRepository) RevokeUserAPIKeys(ctx context.Context, companyID, userID int) error {
+	const ops = "APIKeyRepository.RevokeUserAPIKeys"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementRevokeUserAPIKeys, companyID, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke user api keys: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// TouchAPIKey records the API key has just been used.
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, apiKeyID int) error {
+	const ops = "APIKeyRepository.TouchAPIKey"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementTouchAPIKey, apiKeyID); err != nil {
+		logger.Errorf(ctx, ops, "failed to update api key last usage: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*entity.APIKey, error) {
+	var apiKey entity.APIKey
+	var scopes pq.StringArray
+
+	if err := row.Scan(
+		&apiKey.ID,
+		&apiKey.CompanyID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.KeyHash,
+		&scopes,
+		&apiKey.CreatedBy,
+		&apiKey.ExpiresAt,
+		&apiKey.LastUsedAt,
+		&apiKey.RevokedAt,
+		&apiKey.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	apiKey.Scopes = []entity.Permission{}
+	for _, scope := range scopes {
+		apiKey.Scopes = append(apiKey.Scopes, entity.Permission(scope))
+	}
+
+	return &apiKey, nil
+}
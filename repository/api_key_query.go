@@ -0,0 +1,74 @@
+package repository
+
+var (
+	// SQLStatementInsertAPIKey inserts a new API key and returns its ID.
+	SQLStatementInsertAPIKey = `
+		INSERT INTO api_keys (company_id, name, prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at;
+	`
+
+	// SQLStatementSelectAPIKeyByHash selects an API key by its hash.
+	SQLStatementSelectAPIKeyByHash = `
+		SELECT
+			id,
+			company_id,
+			name,
+			prefix,
+			key_hash,
+			scopes,
+			created_by,
+			expires_at,
+			last_used_at,
+			revoked_at,
+			created_at
+		FROM api_keys
+		WHERE key_hash = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementSelectCompanyAPIKeys selects every API key of a company that has not been revoked.
+	SQLStatementSelectCompanyAPIKeys = `
+		SELECT
+			id,
+			company_id,
+			name,
+			prefix,
+			key_hash,
+			scopes,
+			created_by,
+			expires_at,
+			last_used_at,
+			revoked_at,
+			created_at
+		FROM api_keys
+		WHERE company_id = $1
+			AND revoked_at IS NULL
+		ORDER BY created_at DESC;
+	`
+
+	// SQLStatementRevokeAPIKey revokes an API key of a company if it is not revoked yet.
+	SQLStatementRevokeAPIKey = `
+		UPDATE api_keys
+			SET revoked_at = now()
+		WHERE id = $1
+			AND company_id = $2
+			AND revoked_at IS NULL;
+	`
+
+	// SQLStatementRevokeUserAPIKeys revokes the API keys a user created for a company which are not revoked yet.
+	SQLStatementRevokeUserAPIKeys = `
+		UPDATE api_keys
+			SET revoked_at = now()
+		WHERE company_id = $1
+			AND created_by = $2
+			AND revoked_at IS NULL;
+	`
+
+	// SQLStatementTouchAPIKey records the last time an API key was used.
+	SQLStatementTouchAPIKey = `
+		UPDATE api_keys
+			SET last_used_at = now()
+		WHERE id = $1;
+	`
+)
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// AuditRepository provides methods for interacting with the "audit_logs" database table.
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository initializes a new AuditRepository with a given database connection.
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// CreateAuditLog persists a new audit log.
+func (r *AuditRepository) CreateAuditLog(ctx context.Context, auditLog entity.AuditLog) error {
+	const ops = "AuditRepository.CreateAuditLog"
+
+	if _, err := r.db.ExecContext(
+		ctx,
+		SQLStatementInsertAuditLog,
+		auditLog.CompanyID,
+		auditLog.ActorID,
+		auditLog.APIKeyID,
+		auditLog.ImpersonatorID,
+		auditLog.Action,
+		auditLog.TargetType,
+		auditLog.TargetID,
+		nullableJSON(auditLog.Before),
+		nullableJSON(auditLog.After),
+		auditLog.IP,
+		auditLog.RequestID,
+	); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert audit log: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ListAuditLogs retrieves a page of the audit logs matching the filter, newest first,
+// and the total number of matching audit logs.
+func (r *AuditRepository) ListAuditLogs(ctx context.Context, filter entity.AuditLogFilter, limit, offset int) ([]entity.AuditLog, int, error) {
+	const ops = "AuditRepository.ListAuditLogs"
+
+	args := []any{
+		filter.CompanyID,
+		filter.ActorID,
+		filter.Action,
+		filter.TargetType,
+		filter.TargetID,
+		filter.From,
+		filter.To,
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, SQLStatementCountAuditLogs, args...).Scan(&total); err != nil {
+		logger.Errorf(ctx, ops, "failed to count audit logs: %v", err)
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectAuditLogs, append(args, limit, offset)...)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch audit logs: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	auditLogs := []entity.AuditLog{}
+	for rows.Next() {
+		var auditLog entity.AuditLog
+		var before, after []byte
+
+		if err := rows.Scan(
+			&auditLog.ID,
+			&auditLog.CompanyID,
+			&auditLog.ActorID,
+			&auditLog.APIKeyID,
+			&auditLog.ImpersonatorID,
+			&auditLog.Action,
+			&auditLog.TargetType,
+			&auditLog.TargetID,
+			&before,
+			&after,
+			&auditLog.IP,
+			&auditLog.RequestID,
+			&auditLog.CreatedAt,
+		); err != nil {
+			logger.Errorf(ctx, ops, "failed to scan an audit log: %v", err)
+			return nil, 0, err
+		}
+
+		auditLog.Before = json.RawMessage(before)
+		auditLog.After = json.RawMessage(after)
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	return auditLogs, total, rows.Err()
+}
+
+// nullableJSON returns a JSON document as a query argument, empty documents are stored as NULL.
+func nullableJSON(document json.RawMessage) any {
+	if len(document) == 0 {
+		return nil
+	}
+
+	return string(document)
+}
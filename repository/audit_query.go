@@ -0,0 +1,53 @@
+package repository
+
+var (
+	// SQLStatementInsertAuditLog inserts a new audit log and returns its ID.
+	SQLStatementInsertAuditLog = `
+		INSERT INTO audit_logs (company_id, actor_id, api_key_id, impersonator_id, action, target_type, target_id, before, after, ip, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at;
+	`
+
+	// SQLStatementCountAuditLogs counts the audit logs of a company matching the filter.
+	// Empty filters, given as 0, '' or NULL, match every audit log.
+	SQLStatementCountAuditLogs = `
+		SELECT COUNT(id)
+		FROM audit_logs
+		WHERE company_id = $1
+			AND ($2 = 0 OR actor_id = $2)
+			AND ($3 = '' OR action = $3)
+			AND ($4 = '' OR target_type = $4)
+			AND ($5 = '' OR target_id = $5)
+			AND ($6::timestamp IS NULL OR created_at >= $6)
+			AND ($7::timestamp IS NULL OR created_at < $7);
+	`
+
+	// SQLStatementSelectAuditLogs selects a page of the audit logs of a company matching the filter, newest first.
+	// Empty filters, given as 0, '' or NULL, match every audit log.
+	SQLStatementSelectAuditLogs = `
+		SELECT
+			id,
+			company_id,
+			actor_id,
+			api_key_id,
+			impersonator_id,
+			action,
+			target_type,
+			target_id,
+			before,
+			after,
+			ip,
+			request_id,
+			created_at
+		FROM audit_logs
+		WHERE company_id = $1
+			AND ($2 = 0 OR actor_id = $2)
+			AND ($3 = '' OR action = $3)
+			AND ($4 = '' OR target_type = $4)
+			AND ($5 = '' OR target_id = $5)
+			AND ($6::timestamp IS NULL OR created_at >= $6)
+			AND ($7::timestamp IS NULL OR created_at < $7)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $8 OFFSET $9;
+	`
+)
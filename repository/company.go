@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/mhdiiilham/gosm/entity"
 	"github.com/mhdiiilham/gosm/logger"
@@ -62,6 +63,10 @@ func (r *CompanyRepository) FindByID(ctx context.Context, companyID int) (*entit
 		&company.Description,
 		&company.Phone,
 		&company.Email,
+		&company.RequireAdminTwoFactor,
+		&company.SuspendedAt,
+		&company.SuspensionReason,
+		&company.PlanCode,
 	); err != nil {
 		logger.Errorf(ctx, ops, "failed to select company: %v", err)
 		return nil, err
@@ -69,3 +74,131 @@ func (r *CompanyRepository) FindByID(ctx context.Context, companyID int) (*entit
 
 	return &company, nil
 }
+
+// UpdateRequireAdminTwoFactor sets whether the admins of a company have to use two-factor authentication.
+func (r *CompanyRepository) UpdateRequireAdminTwoFactor(ctx context.Context, companyID int, required bool) error {
+	const ops = "CompanyRepository.UpdateRequireAdminTwoFactor"
+
+	if _, err := r.db.ExecContext(ctx, SQLUpdateCompanyRequireAdminTwoFactor, required, companyID); err != nil {
+		logger.Errorf(ctx, ops, "failed to update company: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateCompanyProfile replaces the name, contact details and description of a company.
+// It returns false when the company does not exist.
+func (r *CompanyRepository) UpdateCompanyProfile(ctx context.Context, company entity.Company) (bool, error) {
+	const ops = "CompanyRepository.UpdateCompanyProfile"
+
+	result, err := r.db.ExecContext(
+		ctx,
+		SQLUpdateCompanyProfile,
+		company.Name,
+		company.Email,
+		company.Phone,
+		company.Address,
+		company.Website,
+		company.Description,
+		company.ID,
+	)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update company: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// UpdateCompanyLogo sets the logo of a company, a nil logoURL removes it.
+// It returns false when the company does not exist.
+func (r *CompanyRepository) UpdateCompanyLogo(ctx context.Context, companyID int, logoURL *string) (bool, error) {
+	const ops = "CompanyRepository.UpdateCompanyLogo"
+
+	result, err := r.db.ExecContext(ctx, SQLUpdateCompanyLogo, logoURL, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update company logo: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// UpdateCompanySuspension suspends a company since suspendedAt for the given reason,
+// a nil suspendedAt reactivates it. It returns false when the company does not exist.
+func (r *CompanyRepository) UpdateCompanySuspension(ctx context.Context, companyID int, suspendedAt *time.Time, reason *string) (bool, error) {
+	const ops = "CompanyRepository.UpdateCompanySuspension"
+
+	result, err := r.db.ExecContext(ctx, SQLUpdateCompanySuspension, suspendedAt, reason, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update company suspension: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// ListCompanyUsage retrieves a page of the companies whose name contains search and whose status is status,
+// either "active", "suspended" or empty for both, newest first, and the total number of matching companies.
+func (r *CompanyRepository) ListCompanyUsage(ctx context.Context, search, status string, limit, offset int) ([]entity.CompanyUsage, int, error) {
+	const ops = "CompanyRepository.ListCompanyUsage"
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, SQLCountCompanyUsage, search, status).Scan(&total); err != nil {
+		logger.Errorf(ctx, ops, "failed to count companies: %v", err)
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, SQLSelectCompanyUsage, search, status, limit, offset)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch companies: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	companies := []entity.CompanyUsage{}
+	for rows.Next() {
+		var usage entity.CompanyUsage
+		if err := rows.Scan(
+			&usage.ID,
+			&usage.Name,
+			&usage.Email,
+			&usage.Phone,
+			&usage.LogoURL,
+			&usage.CreatedAt,
+			&usage.SuspendedAt,
+			&usage.SuspensionReason,
+			&usage.PlanCode,
+			&usage.MemberCount,
+			&usage.EventCount,
+			&usage.GuestCount,
+			&usage.LastEventAt,
+		); err != nil {
+			logger.Errorf(ctx, ops, "failed to scan a company: %v", err)
+			return nil, 0, err
+		}
+
+		companies = append(companies, usage)
+	}
+
+	return companies, total, rows.Err()
+}
+
+// UpdateCompanyPlan subscribes a company to the plan identified by planCode.
+// It returns false when the company does not exist.
+func (r *CompanyRepository) UpdateCompanyPlan(ctx context.Context, companyID int, planCode string) (bool, error) {
+	const ops = "CompanyRepository.UpdateCompanyPlan"
+
+	result, err := r.db.ExecContext(ctx, SQLUpdateCompanyPlan, planCode, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update company plan: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
@@ -11,9 +11,108 @@ var (
 	// SQLSelectCompany ...
 	SQLSelectCompany = `
 	SELECT
-		id, name, address, logo_url, website, description, phone, email
+		id, name, address, logo_url, website, description, phone, email, require_admin_two_factor,
+		suspended_at, suspension_reason, plan_code
 	FROM companies
 	WHERE companies.id = $1
 	LIMIT 1;
 	`
+
+	// SQLUpdateCompanyRequireAdminTwoFactor sets whether the admins of a company have to use two-factor authentication.
+	SQLUpdateCompanyRequireAdminTwoFactor = `
+	UPDATE companies
+		SET require_admin_two_factor = $1,
+			updated_at = now()
+	WHERE companies.id = $2;
+	`
+
+	// SQLUpdateCompanyProfile replaces the profile of a company.
+	SQLUpdateCompanyProfile = `
+	UPDATE companies
+		SET name = $1,
+			email = $2,
+			phone = $3,
+			address = $4,
+			website = $5,
+			description = $6,
+			updated_at = now()
+	WHERE companies.id = $7;
+	`
+
+	// SQLUpdateCompanyLogo sets or clears the logo of a company.
+	SQLUpdateCompanyLogo = `
+	UPDATE companies
+		SET logo_url = $1,
+			updated_at = now()
+	WHERE companies.id = $2;
+	`
+
+	// SQLUpdateCompanySuspension suspends a company, or reactivates it when both values are NULL.
+	SQLUpdateCompanySuspension = `
+	UPDATE companies
+		SET suspended_at = $1,
+			suspension_reason = $2,
+			updated_at = now()
+	WHERE companies.id = $3;
+	`
+
+	// SQLCountCompanyUsage counts the companies matching the search and status filters.
+	// The name is matched case-insensitively, the status is either '', 'active' or 'suspended'.
+	SQLCountCompanyUsage = `
+	SELECT COUNT(companies.id)
+	FROM companies
+	WHERE ($1 = '' OR companies.name ILIKE '%' || $1 || '%')
+		AND ($2 = '' OR ($2 = 'suspended') = (companies.suspended_at IS NOT NULL));
+	`
+
+	// SQLSelectCompanyUsage selects a page of the companies matching the search and status filters,
+	// newest first, with their number of members, events and guests.
+	SQLSelectCompanyUsage = `
+	SELECT
+		companies.id,
+		companies.name,
+		COALESCE(companies.email, ''),
+		COALESCE(companies.phone, ''),
+		companies.logo_url,
+		companies.created_at,
+		companies.suspended_at,
+		companies.suspension_reason,
+		companies.plan_code,
+		(
+			SELECT COUNT(*)
+			FROM company_memberships
+			WHERE company_memberships.company_id = companies.id
+		),
+		(
+			SELECT COUNT(*)
+			FROM events
+			WHERE events.company_id = companies.id
+				AND events.deleted_at IS NULL
+		),
+		(
+			SELECT COUNT(*)
+			FROM guests
+			JOIN events ON events.id = guests.event_id
+			WHERE events.company_id = companies.id
+				AND events.deleted_at IS NULL
+		),
+		(
+			SELECT MAX(events.created_at)
+			FROM events
+			WHERE events.company_id = companies.id
+		)
+	FROM companies
+	WHERE ($1 = '' OR companies.name ILIKE '%' || $1 || '%')
+		AND ($2 = '' OR ($2 = 'suspended') = (companies.suspended_at IS NOT NULL))
+	ORDER BY companies.created_at DESC, companies.id DESC
+	LIMIT $3 OFFSET $4;
+	`
+
+	// SQLUpdateCompanyPlan subscribes a company to another plan.
+	SQLUpdateCompanyPlan = `
+	UPDATE companies
+		SET plan_code = $1,
+			updated_at = now()
+	WHERE companies.id = $2;
+	`
 )
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// EmailVerificationRepository provides methods for interacting with the "email_verification_tokens" database table.
+type EmailVerificationRepository struct {
+	db *sql.DB
+}
+
+// NewEmailVerificationRepository initializes a new EmailVerificationRepository with a given database connection.
+func NewEmailVerificationRepository(db *sql.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+// CreateEmailVerificationToken persists a new email verification token and returns it with its generated ID.
+func (r *EmailVerificationRepository) CreateEmailVerificationToken(ctx context.Context, token entity.EmailVerificationToken) (*entity.EmailVerificationToken, error) {
+	const ops = "EmailVerificationRepository.CreateEmailVerificationToken"
+
+	row := r.db.QueryRowContext(ctx, SQLStatementInsertEmailVerificationToken, token.UserID, token.TokenHash, token.ExpiresAt)
+	if err := row.Scan(&token.ID, &token.CreatedAt); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert email verification token: %v", err)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// FindByHash retrieves an email verification token based on its hash.
+func (r *EmailVerificationRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error) {
+	var token entity.EmailVerificationToken
+
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectEmailVerificationTokenByHash, tokenHash)
+	if err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// UseEmailVerificationToken marks an email verification token as used.
+// It returns false when the token was already used, so a token can only be consumed once.
+func (r *EmailVerificationRepository) UseEmailVerificationToken(ctx context.Context, tokenID int) (bool, error) {
+	const ops = "EmailVerificationRepository.UseEmailVerificationToken"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementUseEmailVerificationToken, tokenID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to use email verification token: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// InvalidateUserEmailVerificationTokens marks every outstanding email verification token of a user as used.
+func (r *EmailVerificationRepository) InvalidateUserEmailVerificationTokens(ctx context.Context, userID int) error {
+	const ops = "EmailVerificationRepository.InvalidateUserEmailVerificationTokens"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementInvalidateUserEmailVerificationTokens, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to invalidate email verification tokens: %v", err)
+		return err
+	}
+
+	return nil
+}
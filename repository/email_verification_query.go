@@ -0,0 +1,40 @@
+package repository
+
+var (
+	// SQLStatementInsertEmailVerificationToken inserts a new email verification token and returns its ID.
+	SQLStatementInsertEmailVerificationToken = `
+		INSERT INTO email_verification_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at;
+	`
+
+	// SQLStatementSelectEmailVerificationTokenByHash selects an email verification token by its hash.
+	SQLStatementSelectEmailVerificationTokenByHash = `
+		SELECT
+			id,
+			user_id,
+			token_hash,
+			expires_at,
+			used_at,
+			created_at
+		FROM email_verification_tokens
+		WHERE token_hash = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementUseEmailVerificationToken marks an email verification token as used if it was not used yet.
+	SQLStatementUseEmailVerificationToken = `
+		UPDATE email_verification_tokens
+			SET used_at = now()
+		WHERE id = $1
+			AND used_at IS NULL;
+	`
+
+	// SQLStatementInvalidateUserEmailVerificationTokens marks every outstanding email verification token of a user as used.
+	SQLStatementInvalidateUserEmailVerificationTokens = `
+		UPDATE email_verification_tokens
+			SET used_at = now()
+		WHERE user_id = $1
+			AND used_at IS NULL;
+	`
+)
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"strconv"
 
 	"github.com/lib/pq"
@@ -54,18 +55,19 @@ func (r *EventRepository) CreateEvent(ctx context.Context, event entity.Event) (
 		event.CreatedBy.ID,
 		event.Company.ID,
 		event.GuestCount,
+		event.Status,
 	)
 
-	if err := row.Scan(&event.ID); err != nil {
+	if err := row.Scan(&event.ID, &event.Version); err != nil {
 		return nil, err
 	}
 
 	return &event, nil
 }
 
-// GetEvent retrieves an event by its UUID for a specific user.
-func (r *EventRepository) GetEvent(ctx context.Context, tx *sql.Tx, userID, eventID int) (event *entity.Event, err error) {
-	row := tx.QueryRowContext(ctx, SQLStatementSelectEventsByID, eventID, userID)
+// GetEvent retrieves an event by its ID for a specific company.
+func (r *EventRepository) GetEvent(ctx context.Context, tx *sql.Tx, companyID, eventID int) (event *entity.Event, err error) {
+	row := tx.QueryRowContext(ctx, SQLStatementSelectEventsByID, eventID, companyID)
 
 	event = &entity.Event{}
 	if err := row.Scan(
@@ -83,6 +85,8 @@ func (r *EventRepository) GetEvent(ctx context.Context, tx *sql.Tx, userID, even
 		&event.CreatedAt,
 		&event.UpdatedAt,
 		&event.GuestCount,
+		&event.Version,
+		&event.Status,
 	); err != nil {
 		return nil, err
 	}
@@ -90,21 +94,25 @@ func (r *EventRepository) GetEvent(ctx context.Context, tx *sql.Tx, userID, even
 	return event, nil
 }
 
-// GetEvents retrieves a paginated list of events for a specific company.
-func (r *EventRepository) GetEvents(ctx context.Context, companyID int, limit, offset int) ([]entity.Event, int, error) {
+// GetEvents retrieves a page of the events of a company matching the filter, sorted as the filter asks,
+// and the total number of matching events.
+func (r *EventRepository) GetEvents(ctx context.Context, companyID int, filter entity.EventListFilter, limit, offset int) ([]entity.Event, int, error) {
 	const ops = "EventRepository.GetEvents"
 
+	args := append([]any{companyID}, eventListFilterArgs(filter)...)
+
 	var totalEvents int
-	if err := r.db.QueryRowContext(ctx, SQLStatementCountEvents, companyID).Scan(&totalEvents); err != nil {
-		logger.Errorf(ctx, ops, "failed to fetch events: %v", err)
+	if err := r.db.QueryRowContext(ctx, SQLStatementCountEvents, args...).Scan(&totalEvents); err != nil {
+		logger.Errorf(ctx, ops, "failed to count events: %v", err)
 		return nil, 0, err
 	}
 
-	rows, err := r.db.QueryContext(ctx, SQLStatementSelectEvents, companyID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectEvents, append(args, filter.SortBy, filter.Order, limit, offset)...)
 	if err != nil {
 		logger.Errorf(ctx, ops, "failed to fetch events: %v", err)
 		return nil, 0, err
 	}
+	defer rows.Close()
 
 	events := []entity.Event{}
 	for rows.Next() {
@@ -125,17 +133,83 @@ func (r *EventRepository) GetEvents(ctx context.Context, companyID int, limit, o
 			&event.UpdatedAt,
 			&eventType,
 			&event.GuestCount,
+			&event.Version,
+			&event.Status,
 		); err != nil {
 			logger.Errorf(ctx, ops, "failed to scan an event: %v", err)
+			return nil, 0, err
 		}
 
 		event.Type = entity.ParseEventType(eventType)
-
 		events = append(events, event)
+	}
+
+	return events, totalEvents, rows.Err()
+}
+
+// eventListFilterArgs returns the query arguments filtering the events of a company, in the order
+// SQLStatementCountEvents expects them. Sorting is left out as counting does not need it.
+func eventListFilterArgs(filter entity.EventListFilter) []any {
+	return []any{
+		filter.Search,
+		filter.Type,
+		filter.From,
+		filter.To,
+		filter.Timing,
+		filter.CreatedBy,
+		filter.Status,
+	}
+}
 
+// ListAllEvents retrieves a page of the events of every company, or of the given company when companyID is not 0,
+// and the total number of matching events. It is meant for the platform operators.
+func (r *EventRepository) ListAllEvents(ctx context.Context, companyID int, limit, offset int) ([]entity.Event, int, error) {
+	const ops = "EventRepository.ListAllEvents"
+
+	var totalEvents int
+	if err := r.db.QueryRowContext(ctx, SQLStatementCountAllEvents, companyID).Scan(&totalEvents); err != nil {
+		logger.Errorf(ctx, ops, "failed to count events: %v", err)
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectAllEvents, companyID, limit, offset)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch events: %v", err)
+		return nil, 0, err
 	}
+	defer rows.Close()
 
-	return events, totalEvents, nil
+	events := []entity.Event{}
+	for rows.Next() {
+		event := entity.Event{}
+		var eventType string
+		if err := rows.Scan(
+			&event.ID,
+			&event.Title,
+			&event.Description,
+			&event.Location,
+			&event.StartDate,
+			&event.EndDate,
+			&event.CreatedBy.ID,
+			&event.CreatedBy.Name,
+			&event.Company.ID,
+			&event.Company.Name,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+			&eventType,
+			&event.GuestCount,
+			&event.Version,
+			&event.Status,
+		); err != nil {
+			logger.Errorf(ctx, ops, "failed to scan an event: %v", err)
+			return nil, 0, err
+		}
+
+		event.Type = entity.ParseEventType(eventType)
+		events = append(events, event)
+	}
+
+	return events, totalEvents, rows.Err()
 }
 
 // AddGuests adds a list of guests to an event.
@@ -161,6 +235,7 @@ func (r *EventRepository) AddGuests(ctx context.Context, eventID int, guestList
 		)
 		if err != nil {
 			logger.Errorf(ctx, ops, "failed to add guest to an event: %v", err)
+			continue
 		}
 
 		rowsAffected, _ := r.RowsAffected()
@@ -172,70 +247,95 @@ func (r *EventRepository) AddGuests(ctx context.Context, eventID int, guestList
 	return numberOfSuccess, nil
 }
 
-// GetGuests get a list of guest of an event.
-// TODO: need to apply worker pattern here.
-//
-//	Will have to query guests per batch.
-//	each probably like 100?
-//	Also have to limit the number of workers (like 2 or 3?)
-func (r *EventRepository) GetGuests(ctx context.Context, eventID int) (response []entity.Guest, err error) {
-	response = []entity.Guest{}
+// GetGuests retrieves at most limit guests of an event owned by a company matching the filter,
+// VIP guests first, then by ID. The guests start after the cursor, or at the first guest when it is nil.
+func (r *EventRepository) GetGuests(ctx context.Context, companyID, eventID int, filter entity.GuestListFilter, after *entity.GuestCursor, limit int) ([]entity.Guest, error) {
+	const ops = "EventRepository.GetGuests"
+
+	var afterVIP *bool
+	var afterID int
+	if after != nil {
+		afterVIP = &after.IsVIP
+		afterID = after.ID
+	}
 
-	rows, err := r.db.QueryContext(ctx, SQLStatementGetGuestList, eventID)
+	rows, err := r.db.QueryContext(
+		ctx,
+		SQLStatementGetGuestList,
+		eventID,
+		companyID,
+		filter.Search,
+		filter.IsVIP,
+		filter.IsAttending,
+		filter.CheckedIn,
+		afterVIP,
+		afterID,
+		limit,
+	)
 	if err != nil {
-		logger.Errorf(ctx, "EventRepository.GetGuests", "failed to retrieve list of guest: %v", err)
+		logger.Errorf(ctx, ops, "failed to retrieve list of guest: %v", err)
 		return nil, err
 	}
+	defer rows.Close()
 
+	guests := []entity.Guest{}
 	for rows.Next() {
 		guest := entity.Guest{}
-		rows.Scan(
+		if err := rows.Scan(
 			&guest.ID,
 			&guest.EventID,
 			&guest.Name,
 			&guest.Email,
 			&guest.Phone,
 			&guest.IsVIP,
+			&guest.IsAttending,
 			&guest.CheckedIn,
 			&guest.BarcodeID,
 			&guest.Message,
-		)
+		); err != nil {
+			logger.Errorf(ctx, ops, "failed to scan a guest: %v", err)
+			return nil, err
+		}
+
+		guests = append(guests, guest)
+	}
 
-		response = append(response, guest)
+	return guests, rows.Err()
+}
 
+// GetGuestListVersion retrieves the version of the guest list of an event,
+// which changes whenever one of its guests is added, changed or deleted.
+func (r *EventRepository) GetGuestListVersion(ctx context.Context, eventID int) (string, error) {
+	var version string
+	if err := r.db.QueryRowContext(ctx, SQLStatementSelectGuestListVersion, eventID).Scan(&version); err != nil {
+		logger.Errorf(ctx, "EventRepository.GetGuestListVersion", "failed to retrieve guest list version: %v", err)
+		return "", err
 	}
 
-	return response, nil
+	return version, nil
 }
 
-// DeleteGuests delete list of selected guest.
-func (r *EventRepository) DeleteGuests(ctx context.Context, userID int, guestIDs []int) error {
+// DeleteGuests delete list of selected guest of an event owned by a company.
+// The guests are only deleted while the guest list of the event is at expectedVersion, or at any version when it is empty.
+// It returns false when the guest list is at another version.
+func (r *EventRepository) DeleteGuests(ctx context.Context, companyID, eventID int, guestIDs []int, expectedVersion string) (bool, error) {
 	toDeleteIDs := pq.StringArray{}
 	for _, g := range guestIDs {
 		toDeleteIDs = append(toDeleteIDs, strconv.Itoa(g))
 	}
 
-	if _, err := r.db.ExecContext(ctx, SQLStatemetDeleteGuest, toDeleteIDs); err != nil {
-		logger.Errorf(ctx, "EventRepository.GetGuests", "failed to delete guest: %v", err)
-		return nil
-	}
-
-	return nil
-}
-
-// UpdateGuestVIPStatus update the guest's vip status.
-func (r *EventRepository) UpdateGuestVIPStatus(ctx context.Context, guestID int, vipStatus bool) error {
-	isVIPStatus := "0"
-	if vipStatus {
-		isVIPStatus = "1"
-	}
+	var deletedFromEventID int
+	if err := r.db.QueryRowContext(ctx, SQLStatemetDeleteGuest, eventID, companyID, toDeleteIDs, expectedVersion).Scan(&deletedFromEventID); err != nil {
+		// the version of the guest list did not match.
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
 
-	if _, err := r.db.ExecContext(ctx, SQLStatemetSetGuestVIPStatus, isVIPStatus, guestID); err != nil {
-		logger.Errorf(ctx, "EventRepository.GetGuests", "failed to update guest vip status: %v", err)
-		return nil
+		logger.Errorf(ctx, "EventRepository.DeleteGuests", "failed to delete guest: %v", err)
+		return false, err
 	}
 
-	return nil
+	return true, nil
 }
 
 // GetGuest get a guest of an event.
@@ -259,27 +359,86 @@ func (r *EventRepository) GetGuest(ctx context.Context, barcodeID string) (guest
 	return &targetGuest, nil
 }
 
-// UpdateEvent update an existing event.
-func (r *EventRepository) UpdateEvent(ctx context.Context, event entity.Event) (err error) {
-	// _, err = r.db.ExecContext(
-	// 	ctx,
-	// 	SQLStatemetnUpdateEvent,
-	// 	event.Name,
-	// 	event.Location,
-	// 	event.StartDate,
-	// 	event.EndDate,
-	// 	event.DigitalInvitationURL,
-	// 	event.Host,
-	// 	event.MessageTemplate,
-	// 	event.EventType,
-	// 	event.UUID,
-	// )
-	// if err != nil {
-	// 	logger.Errorf(ctx, "EventRepository.UpdateEvent", "failed to update event: %v", err)
-	// 	return nil
-	// }
+// UpdateEvent replaces the editable fields of an event of event.Company and returns it with its new update time and version.
+// The event is only updated while it is at expectedVersion, or at any version when expectedVersion is 0.
+// It returns sql.ErrNoRows when the event does not exist, belongs to another company, is deleted or is at another version.
+func (r *EventRepository) UpdateEvent(ctx context.Context, event entity.Event, expectedVersion int) (*entity.Event, error) {
+	const ops = "EventRepository.UpdateEvent"
 
-	return nil
+	if err := r.db.QueryRowContext(
+		ctx,
+		SQLStatementUpdateEvent,
+		event.Title,
+		event.Type,
+		event.Description,
+		event.Location,
+		event.StartDate,
+		event.EndDate,
+		event.GuestCount,
+		event.ID,
+		event.Company.ID,
+		expectedVersion,
+	).Scan(&event.UpdatedAt, &event.Version); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, ops, "failed to update event: %v", err)
+		}
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// UpdateEventStatus moves an event of event.Company from previousStatus to event.Status and returns it
+// with its new update time and version. The event is only updated while it is at expectedVersion, or at any version when expectedVersion is 0.
+// It returns sql.ErrNoRows when the event does not exist, belongs to another company, is deleted, is at another version
+// or is no longer in previousStatus.
+func (r *EventRepository) UpdateEventStatus(ctx context.Context, event entity.Event, previousStatus entity.EventStatus, expectedVersion int) (*entity.Event, error) {
+	const ops = "EventRepository.UpdateEventStatus"
+
+	if err := r.db.QueryRowContext(
+		ctx,
+		SQLStatementUpdateEventStatus,
+		event.Status,
+		event.ID,
+		event.Company.ID,
+		previousStatus,
+		expectedVersion,
+	).Scan(&event.UpdatedAt, &event.Version); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, ops, "failed to update event status: %v", err)
+		}
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// GetEventStatus retrieves the status of an event which is not deleted, whatever its company.
+// It returns sql.ErrNoRows when the event does not exist or is deleted.
+func (r *EventRepository) GetEventStatus(ctx context.Context, eventID int) (entity.EventStatus, error) {
+	var status entity.EventStatus
+	if err := r.db.QueryRowContext(ctx, SQLStatementSelectEventStatus, eventID).Scan(&status); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, "EventRepository.GetEventStatus", "failed to retrieve event status: %v", err)
+		}
+		return "", err
+	}
+
+	return status, nil
+}
+
+// GetGuestEventStatus retrieves the status of the event of the guest with the given barcode.
+// It returns sql.ErrNoRows when the guest does not exist or its event is deleted.
+func (r *EventRepository) GetGuestEventStatus(ctx context.Context, barcodeID string) (entity.EventStatus, error) {
+	var status entity.EventStatus
+	if err := r.db.QueryRowContext(ctx, SQLStatementSelectGuestEventStatus, barcodeID).Scan(&status); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, "EventRepository.GetGuestEventStatus", "failed to retrieve event status: %v", err)
+		}
+		return "", err
+	}
+
+	return status, nil
 }
 
 // UpdateGuestInvitation update given guest_uuid invitation related values.
@@ -319,32 +478,41 @@ func (r *EventRepository) UpdateGuestAttendingStatus(ctx context.Context, guestI
 	return nil
 }
 
-// DeleteEvent soft delete an event based on it given uuid.
-func (r *EventRepository) DeleteEvent(ctx context.Context, eventID int) (bool, error) {
-	result, err := r.db.ExecContext(ctx, SQLStatementDeleteEvent, eventID)
+// DeleteEvent soft delete an event of a company based on its given id.
+// The event is only deleted while it is at expectedVersion, or at any version when expectedVersion is 0.
+// It returns false when the event does not exist, is already deleted or is at another version.
+func (r *EventRepository) DeleteEvent(ctx context.Context, companyID, eventID, expectedVersion int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, SQLStatementDeleteEvent, eventID, companyID, expectedVersion)
 	if err != nil {
 		logger.Errorf(ctx, "EventRepository.DeleteEvent", "failed to delete event: %v", err)
 		return false, err
 	}
 
+	rowAffected, _ := result.RowsAffected()
+	if rowAffected == 0 {
+		return false, nil
+	}
+
 	// ignore result and error.
 	_, err = r.db.ExecContext(ctx, SQLStatementDeleteEventGuests, eventID)
 	if err != nil {
 		logger.Errorf(ctx, "EventRepository.DeleteEvent", "failed to remove guests from event: %v", err)
 	}
 
-	rowAffected, _ := result.RowsAffected()
-	return int(rowAffected) != 0, nil
+	return true, nil
 }
 
-// SetGuestIsArrived update an guest `is_arrived`
-func (r *EventRepository) SetGuestIsArrived(ctx context.Context, barcodeID string, isArrived bool) (err error) {
-	_, err = r.db.ExecContext(ctx, SQLStatementUpdateGuestArrived, isArrived, barcodeID)
+// SetGuestIsArrived update `is_arrived` of a guest of an event.
+// It returns false when the event has no guest with the given barcode.
+func (r *EventRepository) SetGuestIsArrived(ctx context.Context, eventID int, barcodeID string, isArrived bool) (bool, error) {
+	result, err := r.db.ExecContext(ctx, SQLStatementUpdateGuestArrived, isArrived, barcodeID, eventID)
 	if err != nil {
-		logger.Errorf(ctx, "EventRepository.DeleteEvent", "failed to update guest is_arrived: %v", err)
+		logger.Errorf(ctx, "EventRepository.SetGuestIsArrived", "failed to update guest is_arrived: %v", err)
+		return false, err
 	}
 
-	return nil
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected != 0, nil
 }
 
 func (r *EventRepository) UpdateGuest(ctx context.Context, guestID, name, phone, message string, isAttending bool) error {
@@ -376,3 +544,69 @@ func (r *EventRepository) GetGuestMessages(ctx context.Context, eventID string)
 
 	return messages, nil
 }
+
+// FindGuestPersonalData retrieves the guests of the events of a company, deleted events included,
+// whose email address is email or whose phone number has the digits of one of phones.
+func (r *EventRepository) FindGuestPersonalData(ctx context.Context, companyID int, email string, phones []string) ([]entity.GuestPersonalData, error) {
+	const ops = "EventRepository.FindGuestPersonalData"
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectGuestPersonalData, companyID, email, pq.StringArray(phones))
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch guest personal data: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []entity.GuestPersonalData{}
+	for rows.Next() {
+		var record entity.GuestPersonalData
+		if err := rows.Scan(
+			&record.GuestID,
+			&record.EventID,
+			&record.EventTitle,
+			&record.EventStartDate,
+			&record.Name,
+			&record.Email,
+			&record.Phone,
+			&record.IsVIP,
+			&record.IsAttending,
+			&record.Message,
+			&record.CheckedIn,
+			&record.CheckedInAt,
+			&record.BarcodeID,
+		); err != nil {
+			logger.Errorf(ctx, ops, "failed to scan guest personal data: %v", err)
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// AnonymiseGuests erases the name, email address, phone number and message of the guests matched like
+// FindGuestPersonalData, and returns their IDs. Their check-in and attendance are kept.
+func (r *EventRepository) AnonymiseGuests(ctx context.Context, companyID int, email string, phones []string) ([]int, error) {
+	const ops = "EventRepository.AnonymiseGuests"
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementAnonymiseGuests, companyID, email, pq.StringArray(phones), entity.AnonymisedGuestName)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to anonymise guests: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	guestIDs := []int{}
+	for rows.Next() {
+		var guestID int
+		if err := rows.Scan(&guestID); err != nil {
+			logger.Errorf(ctx, ops, "failed to scan anonymised guest: %v", err)
+			return nil, err
+		}
+
+		guestIDs = append(guestIDs, guestID)
+	}
+
+	return guestIDs, rows.Err()
+}
@@ -2,8 +2,8 @@ package repository
 
 var (
 	// SQLStatementInsertEvent inserts a new event into the "events" table.
-	// It stores the event's UUID, name, location, start and end dates, and digital invitation URL.
-	// The query returns the newly created event's ID.
+	// It stores the event's title, type, description, location, start and end dates, creator, company, guest count and status.
+	// The query returns the newly created event's ID and version.
 	SQLStatementInsertEvent = `
 		INSERT INTO events (
 			title,
@@ -14,29 +14,36 @@ var (
 			end_time,
 			created_by,
 			company_id,
-			guest_count
+			guest_count,
+			status
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING "id";
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING "id", "version";
 	`
 
-	// SQLStatemetnUpdateEvent update event fields.
-	SQLStatemetnUpdateEvent = `
+	// SQLStatementUpdateEvent replaces the editable fields of an event of a company which is not deleted
+	// and still at version $10, or at any version when $10 is 0, and returns its new update time and version.
+	SQLStatementUpdateEvent = `
 		UPDATE events
-			SET name = $1,
-				location = $2,
-				start_date = $3,
-				end_date = $4,
-				digital_invitation_url = $5,
-				host = $6,
-				message_template = $7,
-				event_type = $8
-		WHERE events.uuid = $9;
-	`
-
-	// SQLStatementSelectEvents retrieves a paginated list of events from the "events" table.
-	// It selects events where `deleted_at` is NULL, meaning only active events are returned.
-	// The results are ordered by `created_at` in descending order.
+			SET title = $1,
+				event_type = $2,
+				description = $3,
+				location = $4,
+				start_time = $5,
+				end_time = $6,
+				guest_count = $7,
+				updated_at = now(),
+				version = version + 1
+		WHERE id = $8
+			AND company_id = $9
+			AND deleted_at IS NULL
+			AND ($10::INTEGER = 0 OR version = $10::INTEGER)
+		RETURNING updated_at, version;
+	`
+
+	// SQLStatementSelectEvents retrieves a page of the events of a company which are not deleted and match the filter,
+	// given as $2 to $8 like in SQLStatementCountEvents. They are sorted on the column $9, in the direction $10,
+	// newest events come first when no column is given.
 	SQLStatementSelectEvents = `
 		SELECT
 			events.id,
@@ -52,29 +59,65 @@ var (
 			events.created_at,
 			events.updated_at,
 			events.event_type,
-			events.guest_count
+			events.guest_count,
+			events.version,
+			events.status
 		FROM events
 		JOIN users ON events.created_by = users.id
 		JOIN companies ON events.company_id = companies.id
 		WHERE events.company_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3;
+			AND events.deleted_at IS NULL
+			AND ($2::TEXT = '' OR strpos(lower(events.title), lower($2::TEXT)) > 0)
+			AND ($3::TEXT = '' OR events.event_type::TEXT = $3::TEXT)
+			AND ($4::timestamp IS NULL OR events.end_time >= $4::timestamp)
+			AND ($5::timestamp IS NULL OR events.start_time < $5::timestamp)
+			AND ($6::TEXT = ''
+				OR ($6::TEXT = 'upcoming' AND events.start_time > now())
+				OR ($6::TEXT = 'ongoing' AND events.start_time <= now() AND events.end_time >= now())
+				OR ($6::TEXT = 'past' AND events.end_time < now()))
+			AND ($7::INTEGER = 0 OR events.created_by = $7::INTEGER)
+			AND (($8::TEXT = '' AND events.status <> 'archived') OR events.status = $8::TEXT)
+		ORDER BY
+			CASE WHEN $9::TEXT = 'title' AND $10::TEXT = 'asc' THEN events.title END ASC,
+			CASE WHEN $9::TEXT = 'title' AND $10::TEXT = 'desc' THEN events.title END DESC,
+			CASE WHEN $9::TEXT = 'start_date' AND $10::TEXT = 'asc' THEN events.start_time END ASC,
+			CASE WHEN $9::TEXT = 'start_date' AND $10::TEXT = 'desc' THEN events.start_time END DESC,
+			CASE WHEN $9::TEXT = 'end_date' AND $10::TEXT = 'asc' THEN events.end_time END ASC,
+			CASE WHEN $9::TEXT = 'end_date' AND $10::TEXT = 'desc' THEN events.end_time END DESC,
+			CASE WHEN $9::TEXT = 'created_at' AND $10::TEXT = 'asc' THEN events.created_at END ASC,
+			CASE WHEN $9::TEXT = 'guest_count' AND $10::TEXT = 'asc' THEN events.guest_count END ASC,
+			CASE WHEN $9::TEXT = 'guest_count' AND $10::TEXT = 'desc' THEN events.guest_count END DESC,
+			events.created_at DESC,
+			events.id DESC
+		LIMIT $11 OFFSET $12;
 	`
 
-	// SQLStatementCountEvents counts the total number of events associated with a user.
-	// It only includes events where `deleted_at` is NULL, meaning soft-deleted events are excluded.
+	// SQLStatementCountEvents counts the events of a company which are not deleted and match the filter.
+	// $2 is searched in the title as plain text, case-insensitively, $3 is the event type, $4 and $5 the period the events take place in,
+	// $6 whether they are upcoming, ongoing or past, $7 their creator and $8 their status.
+	// Empty filters, given as 0, '' or NULL, match every event, except archived events which are only matched on their status.
 	SQLStatementCountEvents = `
 		SELECT COUNT(events.id) AS "total_events"
 		FROM events
 		WHERE events.company_id = $1
+			AND events.deleted_at IS NULL
+			AND ($2::TEXT = '' OR strpos(lower(events.title), lower($2::TEXT)) > 0)
+			AND ($3::TEXT = '' OR events.event_type::TEXT = $3::TEXT)
+			AND ($4::timestamp IS NULL OR events.end_time >= $4::timestamp)
+			AND ($5::timestamp IS NULL OR events.start_time < $5::timestamp)
+			AND ($6::TEXT = ''
+				OR ($6::TEXT = 'upcoming' AND events.start_time > now())
+				OR ($6::TEXT = 'ongoing' AND events.start_time <= now() AND events.end_time >= now())
+				OR ($6::TEXT = 'past' AND events.end_time < now()))
+			AND ($7::INTEGER = 0 OR events.created_by = $7::INTEGER)
+			AND (($8::TEXT = '' AND events.status <> 'archived') OR events.status = $8::TEXT);
 	`
 
-	// SQLStatementSelectEventsByID retrieves a specific event by its UUID.
-	// It ensures the event is not soft-deleted (`deleted_at IS NULL`) and belongs to the specified user.
-	SQLStatementSelectEventsByID = `
+	// SQLStatementSelectAllEvents retrieves a page of the events of every company, or of a single company
+	// when $1 is not 0, for the platform operators. Deleted events are left out, newest events come first.
+	SQLStatementSelectAllEvents = `
 		SELECT
 			events.id,
-			events.event_type,
 			events.title,
 			events.description,
 			events.location,
@@ -86,47 +129,144 @@ var (
 			companies.name,
 			events.created_at,
 			events.updated_at,
-			events.guest_count
+			events.event_type,
+			events.guest_count,
+			events.version,
+			events.status
 		FROM events
 		JOIN users ON events.created_by = users.id
 		JOIN companies ON events.company_id = companies.id
+		WHERE ($1 = 0 OR events.company_id = $1)
+			AND events.deleted_at IS NULL
+		ORDER BY events.created_at DESC, events.id DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	// SQLStatementCountAllEvents counts the events of every company, or of a single company when $1 is not 0.
+	SQLStatementCountAllEvents = `
+		SELECT COUNT(events.id)
+		FROM events
+		WHERE ($1 = 0 OR events.company_id = $1)
+			AND events.deleted_at IS NULL;
+	`
+
+	// SQLStatementUpdateEventStatus moves an event of a company which is not deleted from the status $4 to the status $1,
+	// while it is at version $5, or at any version when $5 is 0, and returns its new update time and version.
+	SQLStatementUpdateEventStatus = `
+		UPDATE events
+			SET status = $1,
+				updated_at = now(),
+				version = version + 1
+		WHERE id = $2
+			AND company_id = $3
+			AND deleted_at IS NULL
+			AND status = $4
+			AND ($5::INTEGER = 0 OR version = $5::INTEGER)
+		RETURNING updated_at, version;
+	`
+
+	// SQLStatementSelectEventStatus retrieves the status of an event which is not deleted.
+	SQLStatementSelectEventStatus = `
+		SELECT events.status
+		FROM events
 		WHERE events.id = $1
-			AND events.created_by = $2;
+			AND events.deleted_at IS NULL;
 	`
 
-	// SQLStatementInsertEventUserOrganizer links a user to an event as an organizer.
-	SQLStatementInsertEventUserOrganizer = `
-		INSERT INTO event_user_organizers (
-			user_id,
-			event_id
-		)
-		VALUES ($1, $2);
+	// SQLStatementSelectGuestEventStatus retrieves the status of the event, which is not deleted, of the guest with the barcode $1.
+	SQLStatementSelectGuestEventStatus = `
+		SELECT events.status
+		FROM guests
+		JOIN events ON guests.event_id = events.id
+		WHERE guests.barcode_id = $1
+			AND events.deleted_at IS NULL
+		LIMIT 1;
+	`
+
+	// SQLStatementSelectEventsByID retrieves a specific event by its ID.
+	// It ensures the event belongs to the specified company and is not deleted.
+	SQLStatementSelectEventsByID = `
+		SELECT
+			events.id,
+			events.event_type,
+			events.title,
+			events.description,
+			events.location,
+			events.start_time,
+			events.end_time,
+			events.created_by,
+			users.first_name,
+			events.company_id,
+			companies.name,
+			events.created_at,
+			events.updated_at,
+			events.guest_count,
+			events.version,
+			events.status
+		FROM events
+		JOIN users ON events.created_by = users.id
+		JOIN companies ON events.company_id = companies.id
+		WHERE events.id = $1
+			AND events.company_id = $2
+			AND events.deleted_at IS NULL;
 	`
 
 	// SQLStatementAddGuestToEvent inserts a new guest into the "event_user_guests" table.
 	// The guest will be associated with a specific event by `event_uuid`.
 	// The query ensures that duplicate guests (same name and phone number) are not added.
+	// The version of the guest list of the event is bumped when the guest is added.
 	SQLStatementAddGuestToEvent = `
-		INSERT INTO guests (event_id, name, email, phone, is_vip, barcode_id, is_attending, message)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+		WITH new_guest AS (
+			INSERT INTO guests (event_id, name, email, phone, is_vip, barcode_id, is_attending, message)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING event_id
+		)
+		UPDATE events
+			SET guest_list_version = guest_list_version + 1
+		FROM new_guest
+		WHERE events.id = new_guest.event_id;
 	`
 
-	// SQLStatementGetGuestList retrieves all guests associated with a given event.
-	// The results are ordered by VIP status in descending order.
+	// SQLStatementGetGuestList retrieves a page of at most $9 guests of an event of a company, VIP guests first, then by ID.
+	// $3 is searched as plain text in the name, the phone number and the barcode of the guests, $4 to $6 filter them on their VIP,
+	// attending and check-in status when not NULL. The page starts after the guest whose VIP status is $7 and ID is $8,
+	// or at the first guest when $7 is NULL.
 	SQLStatementGetGuestList = `
 		SELECT
-			id,
-			event_id,
-			name,
-			email,
-			phone,
-			is_vip,
-			checked_in,
-			barcode_id,
-			message
+			guests.id,
+			guests.event_id,
+			guests.name,
+			COALESCE(guests.email, ''),
+			COALESCE(guests.phone, ''),
+			guests.is_vip,
+			guests.is_attending,
+			guests.checked_in,
+			COALESCE(guests.barcode_id, ''),
+			COALESCE(guests.message, '')
 		FROM guests
+		JOIN events ON guests.event_id = events.id
 		WHERE guests.event_id = $1
-		ORDER BY guests.is_vip DESC;
+			AND events.company_id = $2
+			AND ($3::TEXT = ''
+				OR strpos(lower(guests.name), lower($3::TEXT)) > 0
+				OR strpos(lower(guests.phone), lower($3::TEXT)) > 0
+				OR guests.barcode_id = $3::TEXT)
+			AND ($4::BOOLEAN IS NULL OR guests.is_vip = $4::BOOLEAN)
+			AND ($5::BOOLEAN IS NULL OR guests.is_attending = $5::BOOLEAN)
+			AND ($6::BOOLEAN IS NULL OR guests.checked_in = $6::BOOLEAN)
+			AND ($7::BOOLEAN IS NULL
+				OR guests.is_vip < $7::BOOLEAN
+				OR (guests.is_vip = $7::BOOLEAN AND guests.id > $8::INTEGER))
+		ORDER BY guests.is_vip DESC, guests.id ASC
+		LIMIT $9;
+	`
+
+	// SQLStatementSelectGuestListVersion selects the version of the guest list of an event, which is bumped
+	// whenever a guest is added, changed or deleted.
+	SQLStatementSelectGuestListVersion = `
+		SELECT guest_list_version::TEXT
+		FROM events
+		WHERE events.id = $1;
 	`
 
 	// SQLStatementGetGuest retrieves guests associated with a given id.
@@ -155,17 +295,25 @@ var (
 		WHERE event_user_guests.short_id = $3;
 	`
 
-	// SQLStatemetDeleteGuest delete guest's.
+	// SQLStatemetDeleteGuest delete guests of an event owned by a company, when its guest list is still at version $4
+	// or at any version when $4 is empty. It returns the event ID when the version of the guest list matched.
+	// The version is bumped first, which locks the event until the guests are deleted, so a concurrent delete
+	// sent with the same version waits for this one and then no longer matches.
 	SQLStatemetDeleteGuest = `
-		DELETE FROM event_user_guests
-		WHERE event_user_guests.guest_uuid = ANY($1);
-	`
-
-	// SQLStatemetSetGuestVIPStatus update guest's vip status.
-	SQLStatemetSetGuestVIPStatus = `
-		UPDATE event_user_guests
-			SET is_vip = $1
-		WHERE guest_uuid = $2;
+		WITH bumped_list AS (
+			UPDATE events
+				SET guest_list_version = guest_list_version + 1
+			WHERE events.id = $1
+				AND events.company_id = $2
+				AND ($4::TEXT = '' OR events.guest_list_version::TEXT = $4::TEXT)
+			RETURNING events.id
+		), deleted_guests AS (
+			DELETE FROM guests
+			USING bumped_list
+			WHERE guests.event_id = bumped_list.id
+				AND guests.id = ANY($3::int[])
+		)
+		SELECT id FROM bumped_list;
 	`
 
 	// SQLStatementUpdateGuestInvitation update guest: is_invitation_sent, will_attend_event, and qr_code_identifier
@@ -177,22 +325,39 @@ var (
 		WHERE guest_uuid = $4;
 	`
 
-	// SQLStatementUpdateGuestArrived update guest is_arrived
+	// SQLStatementUpdateGuestArrived update is_arrived of a guest of an event.
+	// The version of the guest list of the event is bumped when the guest is updated.
 	SQLStatementUpdateGuestArrived = `
-		UPDATE guests
-			SET checked_in = $1,
-				checked_in_at = NOW()
-		WHERE barcode_id = $2;
+		WITH updated_guest AS (
+			UPDATE guests
+				SET checked_in = $1,
+					checked_in_at = NOW()
+			WHERE barcode_id = $2
+				AND event_id = $3
+			RETURNING event_id
+		)
+		UPDATE events
+			SET guest_list_version = guest_list_version + 1
+		FROM updated_guest
+		WHERE events.id = updated_guest.event_id;
 	`
 
-	// SQLStatementUpdateGuest ...
+	// SQLStatementUpdateGuest updates the answer of a guest to the invitation of its event,
+	// and bumps the version of the guest list of the event.
 	SQLStatementUpdateGuest = `
-		UPDATE guests
-			SET name = $1,
-				is_attending = $2,
-				phone = $3,
-				message = $4
-		WHERE guests.barcode_id = $5
+		WITH updated_guest AS (
+			UPDATE guests
+				SET name = $1,
+					is_attending = $2,
+					phone = $3,
+					message = $4
+			WHERE guests.barcode_id = $5
+			RETURNING event_id
+		)
+		UPDATE events
+			SET guest_list_version = guest_list_version + 1
+		FROM updated_guest
+		WHERE events.id = updated_guest.event_id;
 	`
 
 	// SQLStatementGetGuestMessages
@@ -206,16 +371,76 @@ var (
 			AND guests.message != '';
 	`
 
-	// SQLStatementDeleteEvent soft delete an events.
+	// SQLStatementDeleteEvent soft delete an event of a company, when it is still at version $3 or at any version when $3 is 0.
 	SQLStatementDeleteEvent = `
 		UPDATE events
-			SET deleted_at = now()
-		WHERE events.uuid = $1;
+			SET deleted_at = now(),
+				version = version + 1
+		WHERE events.id = $1
+			AND events.company_id = $2
+			AND events.deleted_at IS NULL
+			AND ($3::INTEGER = 0 OR events.version = $3::INTEGER);
 	`
 
 	// SQLStatementDeleteEventGuests delete guest from events.
 	SQLStatementDeleteEventGuests = `
-		DELETE FROM event_user_guests
-		WHERE event_user_guests.event_uuid = $1;
+		DELETE FROM guests
+		WHERE guests.event_id = $1;
+	`
+
+	// SQLStatementSelectGuestPersonalData retrieves every guest row of the events of a company, deleted events included,
+	// matching the email address $2, case-insensitively, or one of the phone numbers $3 compared on their digits only.
+	SQLStatementSelectGuestPersonalData = `
+		SELECT
+			guests.id,
+			guests.event_id,
+			events.title,
+			events.start_time,
+			guests.name,
+			COALESCE(guests.email, ''),
+			COALESCE(guests.phone, ''),
+			guests.is_vip,
+			guests.is_attending,
+			COALESCE(guests.message, ''),
+			guests.checked_in,
+			guests.checked_in_at,
+			guests.barcode_id
+		FROM guests
+		JOIN events ON guests.event_id = events.id
+		WHERE events.company_id = $1
+			AND guests.anonymised_at IS NULL
+			AND (
+				($2 <> '' AND LOWER(guests.email) = LOWER($2))
+				OR regexp_replace(COALESCE(guests.phone, ''), '[^0-9]', '', 'g') = ANY($3::text[])
+			)
+		ORDER BY events.start_time DESC, guests.id;
+	`
+
+	// SQLStatementAnonymiseGuests erases the personal data of the guests matched like SQLStatementSelectGuestPersonalData.
+	// The check-in, attendance and VIP status are kept so the statistics of the events do not change,
+	// the version of the guest lists of their events is bumped.
+	SQLStatementAnonymiseGuests = `
+		WITH anonymised_guests AS (
+			UPDATE guests
+				SET name = $4,
+					email = '',
+					phone = '',
+					message = '',
+					anonymised_at = now()
+			FROM events
+			WHERE guests.event_id = events.id
+				AND events.company_id = $1
+				AND guests.anonymised_at IS NULL
+				AND (
+					($2 <> '' AND LOWER(guests.email) = LOWER($2))
+					OR regexp_replace(COALESCE(guests.phone, ''), '[^0-9]', '', 'g') = ANY($3::text[])
+				)
+			RETURNING guests.id, guests.event_id
+		), bumped_lists AS (
+			UPDATE events
+				SET guest_list_version = guest_list_version + 1
+			WHERE events.id IN (SELECT event_id FROM anonymised_guests)
+		)
+		SELECT id FROM anonymised_guests;
 	`
 )
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// AssignEventStaff links a user to an event with the given permissions, replacing any previous permissions.
+// It returns false when the user does not belong to the company owning the event.
+func (r *EventRepository) AssignEventStaff(ctx context.Context, eventID, userID int, permissions []entity.Permission) (bool, error) {
+	const ops = "EventRepository.AssignEventStaff"
+
+	storedPermissions := pq.StringArray{}
+	for _, permission := range permissions {
+		storedPermissions = append(storedPermissions, string(permission))
+	}
+
+	result, err := r.db.ExecContext(ctx, SQLStatementUpsertEventStaff, eventID, userID, storedPermissions)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to assign event staff: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// GetEventStaff retrieves a single staff member of an event.
+func (r *EventRepository) GetEventStaff(ctx context.Context, eventID, userID int) (*entity.EventStaff, error) {
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectEventStaff, eventID, userID)
+	return scanEventStaff(row)
+}
+
+// GetEventStaffList retrieves every staff member of an event.
+func (r *EventRepository) GetEventStaffList(ctx context.Context, eventID int) ([]entity.EventStaff, error) {
+	const ops = "EventRepository.GetEventStaffList"
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectEventStaffList, eventID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch event staff: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	staffList := []entity.EventStaff{}
+	for rows.Next() {
+		staff, err := scanEventStaff(rows)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to scan event staff: %v", err)
+			return nil, err
+		}
+
+		staffList = append(staffList, *staff)
+	}
+
+	return staffList, rows.Err()
+}
+
+// RemoveEventStaff unlinks a user from an event.
+// It returns false when the user was not assigned to the event.
+func (r *EventRepository) RemoveEventStaff(ctx context.Context, eventID, userID int) (bool, error) {
+	const ops = "EventRepository.RemoveEventStaff"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementDeleteEventStaff, eventID, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to remove event staff: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// GetStaffEvents retrieves a page of the events of a company a user is assigned to as staff matching the filter,
+// sorted as the filter asks, and the total number of matching events.
+func (r *EventRepository) GetStaffEvents(ctx context.Context, companyID, userID int, filter entity.EventListFilter, limit, offset int) ([]entity.Event, int, error) {
+	const ops = "EventRepository.GetStaffEvents"
+
+	args := append([]any{userID, companyID}, eventListFilterArgs(filter)...)
+
+	var totalEvents int
+	if err := r.db.QueryRowContext(ctx, SQLStatementCountStaffEvents, args...).Scan(&totalEvents); err != nil {
+		logger.Errorf(ctx, ops, "failed to count events: %v", err)
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectStaffEvents, append(args, filter.SortBy, filter.Order, limit, offset)...)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch events: %v", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []entity.Event{}
+	for rows.Next() {
+		event := entity.Event{}
+		var eventType string
+		if err := rows.Scan(
+			&event.ID,
+			&event.Title,
+			&event.Description,
+			&event.Location,
+			&event.StartDate,
+			&event.EndDate,
+			&event.CreatedBy.ID,
+			&event.CreatedBy.Name,
+			&event.Company.ID,
+			&event.Company.Name,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+			&eventType,
+			&event.GuestCount,
+			&event.Version,
+			&event.Status,
+		); err != nil {
+			logger.Errorf(ctx, ops, "failed to scan an event: %v", err)
+			return nil, 0, err
+		}
+
+		event.Type = entity.ParseEventType(eventType)
+		events = append(events, event)
+	}
+
+	return events, totalEvents, rows.Err()
+}
+
+func scanEventStaff(row rowScanner) (*entity.EventStaff, error) {
+	var staff entity.EventStaff
+	var lastName *string
+	var permissions pq.StringArray
+
+	if err := row.Scan(
+		&staff.EventID,
+		&staff.UserID,
+		&staff.Name,
+		&lastName,
+		&staff.Email,
+		&staff.Role,
+		&permissions,
+	); err != nil {
+		return nil, err
+	}
+
+	staff.Name = entity.User{FirstName: staff.Name, LastName: lastName}.GetName()
+	staff.Permissions = []entity.Permission{}
+	for _, permission := range permissions {
+		staff.Permissions = append(staff.Permissions, entity.Permission(permission))
+	}
+
+	return &staff, nil
+}
@@ -0,0 +1,137 @@
+package repository
+
+var (
+	// SQLStatementUpsertEventStaff links a user to an event with a set of permissions.
+	// The user is only linked when they are a member of the company owning the event,
+	// whichever company they are currently switched into.
+	SQLStatementUpsertEventStaff = `
+		INSERT INTO event_user_organizers (event_id, user_id, permissions)
+		SELECT events.id, company_memberships.user_id, $3
+		FROM events
+		JOIN company_memberships ON company_memberships.company_id = events.company_id
+		WHERE events.id = $1
+			AND company_memberships.user_id = $2
+		ON CONFLICT (event_id, user_id) DO UPDATE
+			SET permissions = EXCLUDED.permissions;
+	`
+
+	// SQLStatementSelectEventStaff selects a single staff member of an event, with their role in the company owning the event.
+	SQLStatementSelectEventStaff = `
+		SELECT
+			event_user_organizers.event_id,
+			event_user_organizers.user_id,
+			users.first_name,
+			users.last_name,
+			users.email,
+			company_memberships.role,
+			event_user_organizers.permissions
+		FROM event_user_organizers
+		JOIN users ON event_user_organizers.user_id = users.id
+		JOIN events ON event_user_organizers.event_id = events.id
+		JOIN company_memberships ON company_memberships.user_id = users.id
+			AND company_memberships.company_id = events.company_id
+		WHERE event_user_organizers.event_id = $1
+			AND event_user_organizers.user_id = $2
+		LIMIT 1;
+	`
+
+	// SQLStatementSelectEventStaffList selects every staff member of an event, with their role in the company owning the event.
+	// Users who left that company are left out, like SQLStatementSelectEventStaff no longer finds them.
+	SQLStatementSelectEventStaffList = `
+		SELECT
+			event_user_organizers.event_id,
+			event_user_organizers.user_id,
+			users.first_name,
+			users.last_name,
+			users.email,
+			company_memberships.role,
+			event_user_organizers.permissions
+		FROM event_user_organizers
+		JOIN users ON event_user_organizers.user_id = users.id
+		JOIN events ON event_user_organizers.event_id = events.id
+		JOIN company_memberships ON company_memberships.user_id = users.id
+			AND company_memberships.company_id = events.company_id
+		WHERE event_user_organizers.event_id = $1
+		ORDER BY event_user_organizers.created_at ASC;
+	`
+
+	// SQLStatementDeleteEventStaff unlinks a user from an event.
+	SQLStatementDeleteEventStaff = `
+		DELETE FROM event_user_organizers
+		WHERE event_id = $1
+			AND user_id = $2;
+	`
+
+	// SQLStatementSelectStaffEvents retrieves a page of the events of a company a user is assigned to,
+	// filtered and sorted like SQLStatementSelectEvents with every parameter shifted by one.
+	SQLStatementSelectStaffEvents = `
+		SELECT
+			events.id,
+			events.title,
+			events.description,
+			events.location,
+			events.start_time,
+			events.end_time,
+			events.created_by,
+			users.first_name,
+			events.company_id,
+			companies.name,
+			events.created_at,
+			events.updated_at,
+			events.event_type,
+			events.guest_count,
+			events.version,
+			events.status
+		FROM events
+		JOIN event_user_organizers ON event_user_organizers.event_id = events.id
+		JOIN users ON events.created_by = users.id
+		JOIN companies ON events.company_id = companies.id
+		WHERE event_user_organizers.user_id = $1
+			AND events.company_id = $2
+			AND events.deleted_at IS NULL
+			AND ($3::TEXT = '' OR strpos(lower(events.title), lower($3::TEXT)) > 0)
+			AND ($4::TEXT = '' OR events.event_type::TEXT = $4::TEXT)
+			AND ($5::timestamp IS NULL OR events.end_time >= $5::timestamp)
+			AND ($6::timestamp IS NULL OR events.start_time < $6::timestamp)
+			AND ($7::TEXT = ''
+				OR ($7::TEXT = 'upcoming' AND events.start_time > now())
+				OR ($7::TEXT = 'ongoing' AND events.start_time <= now() AND events.end_time >= now())
+				OR ($7::TEXT = 'past' AND events.end_time < now()))
+			AND ($8::INTEGER = 0 OR events.created_by = $8::INTEGER)
+			AND (($9::TEXT = '' AND events.status <> 'archived') OR events.status = $9::TEXT)
+		ORDER BY
+			CASE WHEN $10::TEXT = 'title' AND $11::TEXT = 'asc' THEN events.title END ASC,
+			CASE WHEN $10::TEXT = 'title' AND $11::TEXT = 'desc' THEN events.title END DESC,
+			CASE WHEN $10::TEXT = 'start_date' AND $11::TEXT = 'asc' THEN events.start_time END ASC,
+			CASE WHEN $10::TEXT = 'start_date' AND $11::TEXT = 'desc' THEN events.start_time END DESC,
+			CASE WHEN $10::TEXT = 'end_date' AND $11::TEXT = 'asc' THEN events.end_time END ASC,
+			CASE WHEN $10::TEXT = 'end_date' AND $11::TEXT = 'desc' THEN events.end_time END DESC,
+			CASE WHEN $10::TEXT = 'created_at' AND $11::TEXT = 'asc' THEN events.created_at END ASC,
+			CASE WHEN $10::TEXT = 'guest_count' AND $11::TEXT = 'asc' THEN events.guest_count END ASC,
+			CASE WHEN $10::TEXT = 'guest_count' AND $11::TEXT = 'desc' THEN events.guest_count END DESC,
+			events.created_at DESC,
+			events.id DESC
+		LIMIT $12 OFFSET $13;
+	`
+
+	// SQLStatementCountStaffEvents counts the events of a company a user is assigned to,
+	// filtered like SQLStatementCountEvents with every parameter shifted by one.
+	SQLStatementCountStaffEvents = `
+		SELECT COUNT(event_user_organizers.event_id) AS "total_events"
+		FROM event_user_organizers
+		JOIN events ON event_user_organizers.event_id = events.id
+		WHERE event_user_organizers.user_id = $1
+			AND events.company_id = $2
+			AND events.deleted_at IS NULL
+			AND ($3::TEXT = '' OR strpos(lower(events.title), lower($3::TEXT)) > 0)
+			AND ($4::TEXT = '' OR events.event_type::TEXT = $4::TEXT)
+			AND ($5::timestamp IS NULL OR events.end_time >= $5::timestamp)
+			AND ($6::timestamp IS NULL OR events.start_time < $6::timestamp)
+			AND ($7::TEXT = ''
+				OR ($7::TEXT = 'upcoming' AND events.start_time > now())
+				OR ($7::TEXT = 'ongoing' AND events.start_time <= now() AND events.end_time >= now())
+				OR ($7::TEXT = 'past' AND events.end_time < now()))
+			AND ($8::INTEGER = 0 OR events.created_by = $8::INTEGER)
+			AND (($9::TEXT = '' AND events.status <> 'archived') OR events.status = $9::TEXT);
+	`
+)
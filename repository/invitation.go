@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// InvitationRepository provides methods for interacting with the "company_invitations" database table.
+type InvitationRepository struct {
+	db *sql.DB
+}
+
+// NewInvitationRepository initializes a new InvitationRepository with a given database connection.
+func NewInvitationRepository(db *sql.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// RunInTransactions executes a function within a database transaction.
+func (r *InvitationRepository) RunInTransactions(ctx context.Context, fn entity.TransactionFunc) error {
+	const ops = "InvitationRepository.RunInTransactions"
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to begin database transaction: %v", err)
+		return err
+	}
+
+	if err := fn(ctx, tx); err != nil {
+		tx.Rollback()
+		logger.Errorf(ctx, ops, "failed to execute transaction: %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateInvitation persists a new company invitation and returns it with its generated ID.
+func (r *InvitationRepository) CreateInvitation(ctx context.Context, invitation entity.CompanyInvitation) (*entity.CompanyInvitation, error) {
+	const ops = "InvitationRepository.CreateInvitation"
+
+	row := r.db.QueryRowContext(
+		ctx,
+		SQLStatementInsertInvitation,
+		invitation.CompanyID,
+		invitation.Email,
+		invitation.Phone,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+	)
+
+	if err := row.Scan(&invitation.ID, &invitation.CreatedAt); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert invitation: %v", err)
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// FindByHash retrieves a company invitation based on its token hash.
+func (r *InvitationRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.CompanyInvitation, error) {
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectInvitationByHash, tokenHash)
+	return scanInvitation(row)
+}
+
+// ListPendingInvitations retrieves every invitation of a company that can still be accepted.
+func (r *InvitationRepository) ListPendingInvitations(ctx context.Context, companyID int) ([]entity.CompanyInvitation, error) {
+	const ops = "InvitationRepository.ListPendingInvitations"
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectPendingInvitations, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch invitations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []entity.CompanyInvitation{}
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to scan an invitation: %v", err)
+			return nil, err
+		}
+
+		invitations = append(invitations, *invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// AcceptInvitation marks an invitation as accepted.
+// It returns false when the invitation was already accepted or revoked, so an invitation can only be used once.
+func (r *InvitationRepository) AcceptInvitation(ctx context.Context, tx *sql.Tx, invitationID int) (bool, error) {
+	const ops = "InvitationRepository.AcceptInvitation"
+
+	result, err := tx.ExecContext(ctx, SQLStatementAcceptInvitation, invitationID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to accept invitation: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// RevokeInvitation revokes a pending invitation of the given company.
+// It returns false when no pending invitation with the given ID exists in the company.
+func (r *InvitationRepository) RevokeInvitation(ctx context.Context, companyID, invitationID int) (bool, error) {
+	const ops = "InvitationRepository.RevokeInvitation"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementRevokeInvitation, invitationID, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke invitation: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanInvitation(row rowScanner) (*entity.CompanyInvitation, error) {
+	var invitation entity.CompanyInvitation
+	if err := row.Scan(
+		&invitation.ID,
+		&invitation.CompanyID,
+		&invitation.Email,
+		&invitation.Phone,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.RevokedAt,
+		&invitation.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+var (
+	// SQLStatementInsertInvitation inserts a new company invitation and returns its ID.
+	SQLStatementInsertInvitation = `
+		INSERT INTO company_invitations (company_id, email, phone, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at;
+	`
+
+	// SQLStatementSelectInvitationByHash selects a company invitation by its token hash.
+	SQLStatementSelectInvitationByHash = `
+		SELECT
+			id,
+			company_id,
+			email,
+			phone,
+			role,
+			token_hash,
+			invited_by,
+			expires_at,
+			accepted_at,
+			revoked_at,
+			created_at
+		FROM company_invitations
+		WHERE token_hash = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementSelectPendingInvitations selects every invitation of a company that can still be accepted.
+	SQLStatementSelectPendingInvitations = `
+		SELECT
+			id,
+			company_id,
+			email,
+			phone,
+			role,
+			token_hash,
+			invited_by,
+			expires_at,
+			accepted_at,
+			revoked_at,
+			created_at
+		FROM company_invitations
+		WHERE company_id = $1
+			AND accepted_at IS NULL
+			AND revoked_at IS NULL
+			AND expires_at > now()
+		ORDER BY created_at DESC;
+	`
+
+	// SQLStatementAcceptInvitation marks an invitation as accepted if it is still pending.
+	SQLStatementAcceptInvitation = `
+		UPDATE company_invitations
+			SET accepted_at = now()
+		WHERE id = $1
+			AND accepted_at IS NULL
+			AND revoked_at IS NULL;
+	`
+
+	// SQLStatementRevokeInvitation revokes a pending invitation of a company.
+	SQLStatementRevokeInvitation = `
+		UPDATE company_invitations
+			SET revoked_at = now()
+		WHERE id = $1
+			AND company_id = $2
+			AND accepted_at IS NULL
+			AND revoked_at IS NULL;
+	`
+)
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// PasswordResetRepository provides methods for interacting with the "password_reset_tokens" database table.
+type PasswordResetRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordResetRepository initializes a new PasswordResetRepository with a given database connection.
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// CreatePasswordResetToken persists a new password reset token and returns it with its generated ID.
+func (r *PasswordResetRepository) CreatePasswordResetToken(ctx context.Context, token entity.PasswordResetToken) (*entity.PasswordResetToken, error) {
+	const ops = "PasswordResetRepository.CreatePasswordResetToken"
+
+	row := r.db.QueryRowContext(ctx, SQLStatementInsertPasswordResetToken, token.UserID, token.TokenHash, token.ExpiresAt)
+	if err := row.Scan(&token.ID, &token.CreatedAt); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert password reset token: %v", err)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// FindByHash retrieves a password reset token based on its hash.
+func (r *PasswordResetRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	var token entity.PasswordResetToken
+
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectPasswordResetTokenByHash, tokenHash)
+	if err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// UsePasswordResetToken marks a password reset token as used.
+// It returns false when the token was already used, so a token can only be consumed once.
+func (r *PasswordResetRepository) UsePasswordResetToken(ctx context.Context, tokenID int) (bool, error) {
+	const ops = "PasswordResetRepository.UsePasswordResetToken"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementUsePasswordResetToken, tokenID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to use password reset token: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// InvalidateUserPasswordResetTokens marks every outstanding password reset token of a user as used.
+func (r *PasswordResetRepository) InvalidateUserPasswordResetTokens(ctx context.Context, userID int) error {
+	const ops = "PasswordResetRepository.InvalidateUserPasswordResetTokens"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementInvalidateUserPasswordResetTokens, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to invalidate password reset tokens: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+var (
+	// SQLStatementInsertPasswordResetToken inserts a new password reset token and returns its ID.
+	SQLStatementInsertPasswordResetToken = `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at;
+	`
+
+	// SQLStatementSelectPasswordResetTokenByHash selects a password reset token by its hash.
+	SQLStatementSelectPasswordResetTokenByHash = `
+		SELECT
+			id,
+			user_id,
+			token_hash,
+			expires_at,
+			used_at,
+			created_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementUsePasswordResetToken marks a password reset token as used if it was not used yet.
+	SQLStatementUsePasswordResetToken = `
+		UPDATE password_reset_tokens
+			SET used_at = now()
+		WHERE id = $1
+			AND used_at IS NULL;
+	`
+
+	// SQLStatementInvalidateUserPasswordResetTokens marks every outstanding password reset token of a user as used.
+	SQLStatementInvalidateUserPasswordResetTokens = `
+		UPDATE password_reset_tokens
+			SET used_at = now()
+		WHERE user_id = $1
+			AND used_at IS NULL;
+	`
+)
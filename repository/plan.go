@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// PlanRepository provides database operations related to the plans of the companies and their usage.
+type PlanRepository struct {
+	db *sql.DB
+}
+
+// NewPlanRepository initializes a new instance of PlanRepository.
+func NewPlanRepository(db *sql.DB) *PlanRepository {
+	return &PlanRepository{db: db}
+}
+
+// FindPlan retrieves a plan by its code. It returns sql.ErrNoRows when the plan does not exist.
+func (r *PlanRepository) FindPlan(ctx context.Context, code string) (*entity.Plan, error) {
+	const ops = "PlanRepository.FindPlan"
+
+	var plan entity.Plan
+	if err := r.db.QueryRowContext(ctx, SQLSelectPlan, code).Scan(
+		&plan.Code,
+		&plan.Name,
+		&plan.MaxActiveEvents,
+		&plan.MaxGuestsPerEvent,
+		&plan.MaxMessagesPerMonth,
+	); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, ops, "failed to select plan: %v", err)
+		}
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// GetCompanyPlanUsage retrieves the plan of a company and its usage, the messages are counted since periodStart.
+// It returns sql.ErrNoRows when the company does not exist.
+func (r *PlanRepository) GetCompanyPlanUsage(ctx context.Context, companyID int, periodStart time.Time) (*entity.PlanUsage, error) {
+	const ops = "PlanRepository.GetCompanyPlanUsage"
+
+	var usage entity.PlanUsage
+	if err := r.db.QueryRowContext(ctx, SQLSelectCompanyPlanUsage, companyID, periodStart).Scan(
+		&usage.Plan.Code,
+		&usage.Plan.Name,
+		&usage.Plan.MaxActiveEvents,
+		&usage.Plan.MaxGuestsPerEvent,
+		&usage.Plan.MaxMessagesPerMonth,
+		&usage.ActiveEvents,
+		&usage.LargestEventGuests,
+		&usage.MessagesThisPeriod,
+	); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, ops, "failed to select company plan usage: %v", err)
+		}
+		return nil, err
+	}
+
+	return &usage, nil
+}
+
+// GetEventGuestQuota retrieves the plan of the company owning an event and the number of guests of the event.
+// It returns sql.ErrNoRows when the event does not exist.
+func (r *PlanRepository) GetEventGuestQuota(ctx context.Context, eventID int) (*entity.Plan, int, error) {
+	const ops = "PlanRepository.GetEventGuestQuota"
+
+	var plan entity.Plan
+	var guestCount int
+	if err := r.db.QueryRowContext(ctx, SQLSelectEventGuestQuota, eventID).Scan(
+		&plan.Code,
+		&plan.Name,
+		&plan.MaxActiveEvents,
+		&plan.MaxGuestsPerEvent,
+		&plan.MaxMessagesPerMonth,
+		&guestCount,
+	); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, ops, "failed to select event guest quota: %v", err)
+		}
+		return nil, 0, err
+	}
+
+	return &plan, guestCount, nil
+}
+
+// ConsumeMessageQuota counts count messages sent by a company during the period starting at periodStart,
+// unless the total would exceed limit, a nil limit is unlimited.
+// It returns false, without counting them, when the limit would be exceeded.
+func (r *PlanRepository) ConsumeMessageQuota(ctx context.Context, companyID int, periodStart time.Time, count int, limit *int) (bool, error) {
+	const ops = "PlanRepository.ConsumeMessageQuota"
+
+	var messageCount int
+	if err := r.db.QueryRowContext(ctx, SQLConsumeMessageQuota, companyID, periodStart, count, limit).Scan(&messageCount); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		logger.Errorf(ctx, ops, "failed to consume message quota: %v", err)
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+var (
+	// SQLSelectPlan selects a plan by its code.
+	SQLSelectPlan = `
+	SELECT code, name, max_active_events, max_guests_per_event, max_messages_per_month
+	FROM plans
+	WHERE plans.code = $1;
+	`
+
+	// SQLSelectCompanyPlanUsage selects the plan of a company with its usage: the number of active events,
+	// which are not deleted, cancelled, archived nor over yet, the number of guests of the active event having the most,
+	// and the number of messages sent since the month $2.
+	SQLSelectCompanyPlanUsage = `
+	SELECT
+		plans.code,
+		plans.name,
+		plans.max_active_events,
+		plans.max_guests_per_event,
+		plans.max_messages_per_month,
+		(
+			SELECT COUNT(*)
+			FROM events
+			WHERE events.company_id = companies.id
+				AND events.deleted_at IS NULL
+				AND events.end_time >= now()
+				AND events.status NOT IN ('cancelled', 'archived')
+		),
+		(
+			SELECT COALESCE(MAX(event_guests.guest_count), 0)
+			FROM (
+				SELECT COUNT(guests.id) AS guest_count
+				FROM events
+				JOIN guests ON guests.event_id = events.id
+				WHERE events.company_id = companies.id
+					AND events.deleted_at IS NULL
+					AND events.end_time >= now()
+					AND events.status NOT IN ('cancelled', 'archived')
+				GROUP BY events.id
+			) event_guests
+		),
+		COALESCE((
+			SELECT company_message_usage.message_count
+			FROM company_message_usage
+			WHERE company_message_usage.company_id = companies.id
+				AND company_message_usage.month = $2
+		), 0)
+	FROM companies
+	JOIN plans ON plans.code = companies.plan_code
+	WHERE companies.id = $1;
+	`
+
+	// SQLSelectEventGuestQuota selects the plan of the company owning an event, and the number of guests of the event.
+	SQLSelectEventGuestQuota = `
+	SELECT
+		plans.code,
+		plans.name,
+		plans.max_active_events,
+		plans.max_guests_per_event,
+		plans.max_messages_per_month,
+		(
+			SELECT COUNT(*)
+			FROM guests
+			WHERE guests.event_id = events.id
+		)
+	FROM events
+	JOIN companies ON companies.id = events.company_id
+	JOIN plans ON plans.code = companies.plan_code
+	WHERE events.id = $1
+		AND events.deleted_at IS NULL;
+	`
+
+	// SQLConsumeMessageQuota adds $3 messages to the messages sent by a company during the month $2,
+	// unless the total would exceed the limit $4. A NULL limit is unlimited.
+	// No row is returned when the limit would be exceeded.
+	SQLConsumeMessageQuota = `
+	INSERT INTO company_message_usage (company_id, month, message_count)
+	SELECT $1::INTEGER, $2::DATE, $3::INTEGER
+	WHERE $4::INTEGER IS NULL OR $3::INTEGER <= $4::INTEGER
+	ON CONFLICT (company_id, month) DO UPDATE
+		SET message_count = company_message_usage.message_count + EXCLUDED.message_count
+		WHERE $4::INTEGER IS NULL OR company_message_usage.message_count + EXCLUDED.message_count <= $4::INTEGER
+	RETURNING message_count;
+	`
+)
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// RefreshTokenRepository provides methods for interacting with the "refresh_tokens" database table.
+type RefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository initializes a new RefreshTokenRepository with a given database connection.
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// CreateRefreshToken persists a new refresh token and returns it with its generated ID.
+func (r *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token entity.RefreshToken) (*entity.RefreshToken, error) {
+	const ops = "RefreshTokenRepository.CreateRefreshToken"
+
+	row := r.db.QueryRowContext(
+		ctx,
+		SQLStatementInsertRefreshToken,
+		token.UserID,
+		token.CompanyID,
+		token.FamilyID,
+		token.TokenHash,
+		token.Remember,
+		token.ExpiresAt,
+	)
+
+	if err := row.Scan(&token.ID, &token.CreatedAt); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert refresh token: %v", err)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// FindByHash retrieves a refresh token based on its hash.
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectRefreshTokenByHash, tokenHash)
+	if err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.CompanyID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.Remember,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RevokeRefreshToken revokes a single refresh token.
+// It returns false when the token was already revoked, which allows callers to detect concurrent rotation.
+func (r *RefreshTokenRepository) RevokeRefreshToken(ctx context.Context, tokenID int) (bool, error) {
+	const ops = "RefreshTokenRepository.RevokeRefreshToken"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementRevokeRefreshToken, tokenID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke refresh token: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token in the given family, and the session it started.
+func (r *RefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	const ops = "RefreshTokenRepository.RevokeRefreshTokenFamily"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementRevokeRefreshTokenFamily, familyID); err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke refresh token family: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every refresh token and session owned by the given user.
+func (r *RefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	const ops = "RefreshTokenRepository.RevokeUserRefreshTokens"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementRevokeUserRefreshTokens, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke user refresh tokens: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserCompanyRefreshTokens revokes every refresh token and session the given user was issued for the given company.
+func (r *RefreshTokenRepository) RevokeUserCompanyRefreshTokens(ctx context.Context, userID, companyID int) error {
+	const ops = "RefreshTokenRepository.RevokeUserCompanyRefreshTokens"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementRevokeUserCompanyRefreshTokens, userID, companyID); err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke user company refresh tokens: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+var (
+	// SQLStatementInsertRefreshToken inserts a new refresh token and returns its ID.
+	SQLStatementInsertRefreshToken = `
+		INSERT INTO refresh_tokens (user_id, company_id, family_id, token_hash, remember, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at;
+	`
+
+	// SQLStatementSelectRefreshTokenByHash selects a refresh token by its hash.
+	SQLStatementSelectRefreshTokenByHash = `
+		SELECT
+			id,
+			user_id,
+			company_id,
+			family_id,
+			token_hash,
+			remember,
+			expires_at,
+			revoked_at,
+			created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementRevokeRefreshToken revokes a single refresh token if it is not revoked yet.
+	SQLStatementRevokeRefreshToken = `
+		UPDATE refresh_tokens
+			SET revoked_at = now()
+		WHERE id = $1
+			AND revoked_at IS NULL;
+	`
+
+	// SQLStatementRevokeRefreshTokenFamily revokes every refresh token sharing the same family, and the session it started.
+	SQLStatementRevokeRefreshTokenFamily = `
+		WITH revoked_sessions AS (
+			UPDATE user_sessions
+				SET revoked_at = now()
+			WHERE family_id = $1
+				AND revoked_at IS NULL
+		)
+		UPDATE refresh_tokens
+			SET revoked_at = now()
+		WHERE family_id = $1
+			AND revoked_at IS NULL;
+	`
+
+	// SQLStatementRevokeUserRefreshTokens revokes every refresh token and session owned by a user.
+	SQLStatementRevokeUserRefreshTokens = `
+		WITH revoked_sessions AS (
+			UPDATE user_sessions
+				SET revoked_at = now()
+			WHERE user_id = $1
+				AND revoked_at IS NULL
+		)
+		UPDATE refresh_tokens
+			SET revoked_at = now()
+		WHERE user_id = $1
+			AND revoked_at IS NULL;
+	`
+
+	// SQLStatementRevokeUserCompanyRefreshTokens revokes every refresh token and session a user was issued for a company.
+	SQLStatementRevokeUserCompanyRefreshTokens = `
+		WITH revoked_sessions AS (
+			UPDATE user_sessions
+				SET revoked_at = now()
+			WHERE user_id = $1
+				AND company_id = $2
+				AND revoked_at IS NULL
+		)
+		UPDATE refresh_tokens
+			SET revoked_at = now()
+		WHERE user_id = $1
+			AND company_id = $2
+			AND revoked_at IS NULL;
+	`
+)
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// RolePermissionRepository provides methods for interacting with the "company_role_permissions" database table.
+type RolePermissionRepository struct {
+	db *sql.DB
+}
+
+// NewRolePermissionRepository initializes a new RolePermissionRepository with a given database connection.
+func NewRolePermissionRepository(db *sql.DB) *RolePermissionRepository {
+	return &RolePermissionRepository{db: db}
+}
+
+// GetCompanyRolePermissions retrieves the permissions a company customised, keyed by role.
+// Roles that were not customised are absent from the result.
+func (r *RolePermissionRepository) GetCompanyRolePermissions(ctx context.Context, companyID int) (map[entity.UserRole][]entity.Permission, error) {
+	const ops = "RolePermissionRepository.GetCompanyRolePermissions"
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectCompanyRolePermissions, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch role permissions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rolePermissions := map[entity.UserRole][]entity.Permission{}
+	for rows.Next() {
+		var role string
+		var storedPermissions pq.StringArray
+		if err := rows.Scan(&role, &storedPermissions); err != nil {
+			logger.Errorf(ctx, ops, "failed to scan role permissions: %v", err)
+			return nil, err
+		}
+
+		permissions := []entity.Permission{}
+		for _, permission := range storedPermissions {
+			permissions = append(permissions, entity.Permission(permission))
+		}
+
+		rolePermissions[entity.UserRole(role)] = permissions
+	}
+
+	return rolePermissions, rows.Err()
+}
+
+// SetCompanyRolePermissions replaces the permissions granted to a role of a company.
+func (r *RolePermissionRepository) SetCompanyRolePermissions(ctx context.Context, companyID int, role entity.UserRole, permissions []entity.Permission) error {
+	const ops = "RolePermissionRepository.SetCompanyRolePermissions"
+
+	storedPermissions := pq.StringArray{}
+	for _, permission := range permissions {
+		storedPermissions = append(storedPermissions, string(permission))
+	}
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementUpsertCompanyRolePermissions, companyID, role, storedPermissions); err != nil {
+		logger.Errorf(ctx, ops, "failed to set role permissions: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ResetCompanyRolePermissions removes the customisation of a role of a company, restoring its default permissions.
+func (r *RolePermissionRepository) ResetCompanyRolePermissions(ctx context.Context, companyID int, role entity.UserRole) error {
+	const ops = "RolePermissionRepository.ResetCompanyRolePermissions"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementDeleteCompanyRolePermissions, companyID, role); err != nil {
+		logger.Errorf(ctx, ops, "failed to reset role permissions: %v", err)
+		return err
+	}
+
+	return nil
+}
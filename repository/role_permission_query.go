@@ -0,0 +1,28 @@
+package repository
+
+var (
+	// SQLStatementSelectCompanyRolePermissions selects every role permission customised by a company.
+	SQLStatementSelectCompanyRolePermissions = `
+		SELECT
+			role,
+			permissions
+		FROM company_role_permissions
+		WHERE company_id = $1;
+	`
+
+	// SQLStatementUpsertCompanyRolePermissions customises the permissions granted to a role of a company.
+	SQLStatementUpsertCompanyRolePermissions = `
+		INSERT INTO company_role_permissions (company_id, role, permissions)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (company_id, role) DO UPDATE
+			SET permissions = EXCLUDED.permissions,
+				updated_at = now();
+	`
+
+	// SQLStatementDeleteCompanyRolePermissions removes the customisation of a role of a company.
+	SQLStatementDeleteCompanyRolePermissions = `
+		DELETE FROM company_role_permissions
+		WHERE company_id = $1
+			AND role = $2;
+	`
+)
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// SessionRepository provides methods for interacting with the "user_sessions" database table.
+// A session is revoked together with its refresh token family, by either repository.
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository initializes a new SessionRepository with a given database connection.
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// CreateSession persists a new session and returns it with its generated ID.
+func (r *SessionRepository) CreateSession(ctx context.Context, session entity.Session) (*entity.Session, error) {
+	const ops = "SessionRepository.CreateSession"
+
+	row := r.db.QueryRowContext(
+		ctx,
+		SQLStatementInsertSession,
+		session.UserID,
+		session.CompanyID,
+		session.FamilyID,
+		session.Device,
+		session.IP,
+		session.UserAgent,
+		session.CompanyLocked,
+	)
+
+	if err := row.Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert session: %v", err)
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindSessionByFamilyID retrieves the session started with a refresh token family.
+// It returns sql.ErrNoRows for families issued before sessions were tracked.
+func (r *SessionRepository) FindSessionByFamilyID(ctx context.Context, familyID string) (*entity.Session, error) {
+	return scanSession(r.db.QueryRowContext(ctx, SQLStatementSelectSessionByFamilyID, familyID))
+}
+
+// FindUserSessionByID retrieves a session of a user.
+// It returns sql.ErrNoRows when the session does not exist or belongs to another user.
+func (r *SessionRepository) FindUserSessionByID(ctx context.Context, userID, sessionID int) (*entity.Session, error) {
+	return scanSession(r.db.QueryRowContext(ctx, SQLStatementSelectUserSessionByID, sessionID, userID))
+}
+
+// ListActiveUserSessions retrieves the sessions of a user which are neither revoked nor expired, most recently seen first.
+func (r *SessionRepository) ListActiveUserSessions(ctx context.Context, userID int) ([]entity.Session, error) {
+	const ops = "SessionRepository.ListActiveUserSessions"
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectActiveUserSessions, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch user sessions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []entity.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to scan a user session: %v", err)
+			return nil, err
+		}
+
+		sessions = append(sessions, *session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// TouchSession records the session is in use and returns false when it was revoked or does not exist.
+// The time the session was last seen is only updated once a minute, to spare a write on every request.
+func (r *SessionRepository) TouchSession(ctx context.Context, sessionID int) (bool, error) {
+	const ops = "SessionRepository.TouchSession"
+
+	var active bool
+	if err := r.db.QueryRowContext(ctx, SQLStatementTouchSession, sessionID).Scan(&active); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		logger.Errorf(ctx, ops, "failed to touch session: %v", err)
+		return false, err
+	}
+
+	return active, nil
+}
+
+// RevokeUserSession revokes a session of a user and the refresh tokens of its family.
+// It returns false when the session does not exist, belongs to another user or is already revoked.
+func (r *SessionRepository) RevokeUserSession(ctx context.Context, userID, sessionID int) (bool, error) {
+	const ops = "SessionRepository.RevokeUserSession"
+
+	var revoked int
+	if err := r.db.QueryRowContext(ctx, SQLStatementRevokeUserSession, sessionID, userID).Scan(&revoked); err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke session: %v", err)
+		return false, err
+	}
+
+	return revoked == 1, nil
+}
+
+// RevokeOtherUserSessions revokes every session of a user but keptSessionID, and the refresh tokens of their families.
+// It returns the number of revoked sessions.
+func (r *SessionRepository) RevokeOtherUserSessions(ctx context.Context, userID, keptSessionID int) (int, error) {
+	const ops = "SessionRepository.RevokeOtherUserSessions"
+
+	var revoked int
+	if err := r.db.QueryRowContext(ctx, SQLStatementRevokeOtherUserSessions, userID, keptSessionID).Scan(&revoked); err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke other sessions: %v", err)
+		return 0, err
+	}
+
+	return revoked, nil
+}
+
+func scanSession(row rowScanner) (*entity.Session, error) {
+	var session entity.Session
+	if err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.CompanyID,
+		&session.FamilyID,
+		&session.Device,
+		&session.IP,
+		&session.UserAgent,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.RevokedAt,
+		&session.CompanyLocked,
+	); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
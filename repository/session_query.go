@@ -0,0 +1,128 @@
+package repository
+
+var (
+	// SQLStatementInsertSession inserts a new session and returns its ID.
+	SQLStatementInsertSession = `
+		INSERT INTO user_sessions (user_id, company_id, family_id, device, ip, user_agent, company_locked)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, last_seen_at;
+	`
+
+	// SQLStatementSelectSessionByFamilyID selects the session started with a refresh token family.
+	SQLStatementSelectSessionByFamilyID = `
+		SELECT
+			id,
+			user_id,
+			company_id,
+			family_id,
+			device,
+			ip,
+			user_agent,
+			created_at,
+			last_seen_at,
+			revoked_at,
+			company_locked
+		FROM user_sessions
+		WHERE family_id = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementSelectUserSessionByID selects a session of a user.
+	SQLStatementSelectUserSessionByID = `
+		SELECT
+			id,
+			user_id,
+			company_id,
+			family_id,
+			device,
+			ip,
+			user_agent,
+			created_at,
+			last_seen_at,
+			revoked_at,
+			company_locked
+		FROM user_sessions
+		WHERE id = $1 AND user_id = $2
+		LIMIT 1;
+	`
+
+	// SQLStatementSelectActiveUserSessions selects the sessions of a user which are not revoked
+	// and still have a usable refresh token, most recently seen first.
+	SQLStatementSelectActiveUserSessions = `
+		SELECT
+			s.id,
+			s.user_id,
+			s.company_id,
+			s.family_id,
+			s.device,
+			s.ip,
+			s.user_agent,
+			s.created_at,
+			s.last_seen_at,
+			s.revoked_at,
+			s.company_locked
+		FROM user_sessions s
+		WHERE s.user_id = $1
+			AND s.revoked_at IS NULL
+			AND EXISTS (
+				SELECT 1 FROM refresh_tokens rt
+				WHERE rt.family_id = s.family_id
+					AND rt.revoked_at IS NULL
+					AND rt.expires_at > now()
+			)
+		ORDER BY s.last_seen_at DESC, s.id DESC;
+	`
+
+	// SQLStatementTouchSession records the session is in use, at most once a minute,
+	// and returns whether it is still active.
+	SQLStatementTouchSession = `
+		WITH touched AS (
+			UPDATE user_sessions
+				SET last_seen_at = now()
+			WHERE id = $1
+				AND revoked_at IS NULL
+				AND last_seen_at < now() - INTERVAL '1 minute'
+		)
+		SELECT revoked_at IS NULL
+		FROM user_sessions
+		WHERE id = $1;
+	`
+
+	// SQLStatementRevokeUserSession revokes a session of a user and the refresh tokens of its family,
+	// and returns the number of revoked sessions.
+	SQLStatementRevokeUserSession = `
+		WITH revoked_sessions AS (
+			UPDATE user_sessions
+				SET revoked_at = now()
+			WHERE id = $1
+				AND user_id = $2
+				AND revoked_at IS NULL
+			RETURNING family_id
+		), revoked_tokens AS (
+			UPDATE refresh_tokens
+				SET revoked_at = now()
+			WHERE family_id IN (SELECT family_id FROM revoked_sessions)
+				AND revoked_at IS NULL
+		)
+		SELECT COUNT(*) FROM revoked_sessions;
+	`
+
+	// SQLStatementRevokeOtherUserSessions revokes every session of a user but one and the refresh tokens of their families,
+	// and returns the number of revoked sessions.
+	SQLStatementRevokeOtherUserSessions = `
+		WITH revoked_sessions AS (
+			UPDATE user_sessions
+				SET revoked_at = now()
+			WHERE user_id = $1
+				AND id <> $2
+				AND revoked_at IS NULL
+			RETURNING family_id
+		), revoked_tokens AS (
+			UPDATE refresh_tokens
+				SET revoked_at = now()
+			WHERE family_id IN (SELECT family_id FROM revoked_sessions)
+				AND revoked_at IS NULL
+		)
+		SELECT COUNT(*) FROM revoked_sessions;
+	`
+)
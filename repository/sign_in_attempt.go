@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// SignInAttemptRepository provides methods for interacting with the "sign_in_attempts" database table.
+// It is the store to use when the API runs on more than one instance.
+type SignInAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewSignInAttemptRepository initializes a new SignInAttemptRepository with a given database connection.
+func NewSignInAttemptRepository(db *sql.DB) *SignInAttemptRepository {
+	return &SignInAttemptRepository{db: db}
+}
+
+// GetSignInAttempt retrieves the failed sign in attempts of a key.
+// An attempt without failures is returned when the key never failed.
+func (r *SignInAttemptRepository) GetSignInAttempt(ctx context.Context, key string) (*entity.SignInAttempt, error) {
+	const ops = "SignInAttemptRepository.GetSignInAttempt"
+
+	attempt, err := scanSignInAttempt(r.db.QueryRowContext(ctx, SQLStatementSelectSignInAttempt, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &entity.SignInAttempt{Key: key}, nil
+		}
+
+		logger.Errorf(ctx, ops, "failed to fetch sign in attempt: %v", err)
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+// RecordSignInFailure counts a failed sign in attempt of a key made at the given time.
+// The count restarts when the previous failure is older than window.
+func (r *SignInAttemptRepository) RecordSignInFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*entity.SignInAttempt, error) {
+	const ops = "SignInAttemptRepository.RecordSignInFailure"
+
+	attempt, err := scanSignInAttempt(r.db.QueryRowContext(ctx, SQLStatementRecordSignInFailure, key, at, at.Add(-window)))
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to record sign in failure: %v", err)
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+// LockSignIn refuses sign in attempts of a key until the given time.
+func (r *SignInAttemptRepository) LockSignIn(ctx context.Context, key string, until time.Time) error {
+	const ops = "SignInAttemptRepository.LockSignIn"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementLockSignIn, key, until); err != nil {
+		logger.Errorf(ctx, ops, "failed to lock sign in: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ResetSignInAttempts forgets the failed sign in attempts of a key.
+func (r *SignInAttemptRepository) ResetSignInAttempts(ctx context.Context, key string) error {
+	const ops = "SignInAttemptRepository.ResetSignInAttempts"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementDeleteSignInAttempts, key); err != nil {
+		logger.Errorf(ctx, ops, "failed to reset sign in attempts: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func scanSignInAttempt(row rowScanner) (*entity.SignInAttempt, error) {
+	var attempt entity.SignInAttempt
+	if err := row.Scan(&attempt.Key, &attempt.Failures, &attempt.LastFailedAt, &attempt.LockedUntil); err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+// memorySignInAttemptSweepInterval is the number of recorded failures between two sweeps of the stale attempts.
+const memorySignInAttemptSweepInterval = 1000
+
+// MemorySignInAttemptStore keeps failed sign in attempts in memory.
+// Attempts are lost on restart and not shared between instances, use SignInAttemptRepository for that.
+type MemorySignInAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]entity.SignInAttempt
+	recorded int
+}
+
+// NewMemorySignInAttemptStore initializes a new, empty MemorySignInAttemptStore.
+func NewMemorySignInAttemptStore() *MemorySignInAttemptStore {
+	return &MemorySignInAttemptStore{attempts: map[string]entity.SignInAttempt{}}
+}
+
+// GetSignInAttempt retrieves the failed sign in attempts of a key.
+// An attempt without failures is returned when the key never failed.
+func (s *MemorySignInAttemptStore) GetSignInAttempt(ctx context.Context, key string) (*entity.SignInAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, found := s.attempts[key]
+	if !found {
+		return &entity.SignInAttempt{Key: key}, nil
+	}
+
+	return &attempt, nil
+}
+
+// RecordSignInFailure counts a failed sign in attempt of a key made at the given time.
+// The count restarts when the previous failure is older than window.
+func (s *MemorySignInAttemptStore) RecordSignInFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*entity.SignInAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recorded++
+	if s.recorded%memorySignInAttemptSweepInterval == 0 {
+		s.sweep(at, window)
+	}
+
+	attempt, found := s.attempts[key]
+	if !found || attempt.LastFailedAt.Before(at.Add(-window)) {
+		attempt.Key = key
+		attempt.Failures = 0
+	}
+
+	attempt.Failures++
+	attempt.LastFailedAt = at
+	s.attempts[key] = attempt
+
+	return &attempt, nil
+}
+
+// LockSignIn refuses sign in attempts of a key until the given time.
+func (s *MemorySignInAttemptStore) LockSignIn(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempt, found := s.attempts[key]; found {
+		attempt.LockedUntil = &until
+		s.attempts[key] = attempt
+	}
+
+	return nil
+}
+
+// ResetSignInAttempts forgets the failed sign in attempts of a key.
+func (s *MemorySignInAttemptStore) ResetSignInAttempts(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+// sweep forgets the attempts whose failures would be restarted and which are no longer locked,
+// so the store does not grow with every key ever tried.
+func (s *MemorySignInAttemptStore) sweep(at time.Time, window time.Duration) {
+	for key, attempt := range s.attempts {
+		if attempt.LastFailedAt.Before(at.Add(-window)) && !attempt.IsLocked(at) {
+			delete(s.attempts, key)
+		}
+	}
+}
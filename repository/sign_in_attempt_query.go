@@ -0,0 +1,42 @@
+package repository
+
+var (
+	// SQLStatementSelectSignInAttempt selects the failed sign in attempts of a throttling key.
+	SQLStatementSelectSignInAttempt = `
+		SELECT
+			key,
+			failures,
+			last_failed_at,
+			locked_until
+		FROM sign_in_attempts
+		WHERE key = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementRecordSignInFailure counts a failed sign in attempt of a throttling key.
+	// The count restarts when the previous failure happened before $3.
+	SQLStatementRecordSignInFailure = `
+		INSERT INTO sign_in_attempts (key, failures, last_failed_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE
+			SET failures = CASE
+					WHEN sign_in_attempts.last_failed_at < $3 THEN 1
+					ELSE sign_in_attempts.failures + 1
+				END,
+				last_failed_at = EXCLUDED.last_failed_at
+		RETURNING key, failures, last_failed_at, locked_until;
+	`
+
+	// SQLStatementLockSignIn refuses sign in attempts of a throttling key until the given time.
+	SQLStatementLockSignIn = `
+		UPDATE sign_in_attempts
+			SET locked_until = $2
+		WHERE key = $1;
+	`
+
+	// SQLStatementDeleteSignInAttempts forgets the failed sign in attempts of a throttling key.
+	SQLStatementDeleteSignInAttempts = `
+		DELETE FROM sign_in_attempts
+		WHERE key = $1;
+	`
+)
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// SSORepository provides methods for interacting with the single sign-on database tables:
+// "company_sso_configs", "sso_login_states" and "user_identities".
+type SSORepository struct {
+	db *sql.DB
+}
+
+// NewSSORepository initializes a new SSORepository with a given database connection.
+func NewSSORepository(db *sql.DB) *SSORepository {
+	return &SSORepository{db: db}
+}
+
+// GetCompanySSOConfig retrieves the single sign-on configuration of a company.
+func (r *SSORepository) GetCompanySSOConfig(ctx context.Context, companyID int) (*entity.CompanySSOConfig, error) {
+	var config entity.CompanySSOConfig
+	var defaultRole string
+
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectCompanySSOConfig, companyID)
+	if err := row.Scan(
+		&config.CompanyID,
+		&config.Issuer,
+		&config.ClientID,
+		&config.ClientSecret,
+		&defaultRole,
+		&config.Enabled,
+		&config.CreatedAt,
+		&config.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	config.DefaultRole = entity.UserRole(defaultRole)
+	return &config, nil
+}
+
+// UpsertCompanySSOConfig creates or replaces the single sign-on configuration of a company.
+func (r *SSORepository) UpsertCompanySSOConfig(ctx context.Context, config entity.CompanySSOConfig) (*entity.CompanySSOConfig, error) {
+	const ops = "SSORepository.UpsertCompanySSOConfig"
+
+	row := r.db.QueryRowContext(
+		ctx,
+		SQLStatementUpsertCompanySSOConfig,
+		config.CompanyID,
+		config.Issuer,
+		config.ClientID,
+		config.ClientSecret,
+		config.DefaultRole,
+		config.Enabled,
+	)
+
+	if err := row.Scan(&config.CreatedAt, &config.UpdatedAt); err != nil {
+		logger.Errorf(ctx, ops, "failed to upsert sso config: %v", err)
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// DeleteCompanySSOConfig deletes the single sign-on configuration of a company.
+// It returns false when the company had no configuration.
+func (r *SSORepository) DeleteCompanySSOConfig(ctx context.Context, companyID int) (bool, error) {
+	const ops = "SSORepository.DeleteCompanySSOConfig"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementDeleteCompanySSOConfig, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to delete sso config: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// CreateLoginState persists a new single sign-on login state and returns it with its generated ID.
+func (r *SSORepository) CreateLoginState(ctx context.Context, state entity.SSOLoginState) (*entity.SSOLoginState, error) {
+	const ops = "SSORepository.CreateLoginState"
+
+	row := r.db.QueryRowContext(
+		ctx,
+		SQLStatementInsertSSOLoginState,
+		state.CompanyID,
+		state.StateHash,
+		state.CodeVerifier,
+		state.Nonce,
+		state.ExpiresAt,
+	)
+
+	if err := row.Scan(&state.ID, &state.CreatedAt); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert sso login state: %v", err)
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// FindLoginStateByHash retrieves a single sign-on login state based on its hash.
+func (r *SSORepository) FindLoginStateByHash(ctx context.Context, stateHash string) (*entity.SSOLoginState, error) {
+	var state entity.SSOLoginState
+
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectSSOLoginStateByHash, stateHash)
+	if err := row.Scan(
+		&state.ID,
+		&state.CompanyID,
+		&state.StateHash,
+		&state.CodeVerifier,
+		&state.Nonce,
+		&state.ExpiresAt,
+		&state.UsedAt,
+		&state.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// UseLoginState marks a single sign-on login state as used.
+// It returns false when the state was already used, so a state can only be completed once.
+func (r *SSORepository) UseLoginState(ctx context.Context, stateID int) (bool, error) {
+	const ops = "SSORepository.UseLoginState"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementUseSSOLoginState, stateID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to use sso login state: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// FindUserIdentity retrieves the identity of a subject at an identity provider.
+func (r *SSORepository) FindUserIdentity(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectUserIdentity, issuer, subject)
+	if err := row.Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// CreateUserIdentity links a user to a subject at an identity provider.
+func (r *SSORepository) CreateUserIdentity(ctx context.Context, userID int, issuer, subject string) error {
+	const ops = "SSORepository.CreateUserIdentity"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementInsertUserIdentity, userID, issuer, subject); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert user identity: %v", err)
+		return err
+	}
+
+	return nil
+}
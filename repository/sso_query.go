@@ -0,0 +1,90 @@
+package repository
+
+var (
+	// SQLStatementSelectCompanySSOConfig selects the single sign-on configuration of a company.
+	SQLStatementSelectCompanySSOConfig = `
+		SELECT
+			company_id,
+			issuer,
+			client_id,
+			client_secret,
+			default_role,
+			enabled,
+			created_at,
+			updated_at
+		FROM company_sso_configs
+		WHERE company_id = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementUpsertCompanySSOConfig creates or replaces the single sign-on configuration of a company.
+	SQLStatementUpsertCompanySSOConfig = `
+		INSERT INTO company_sso_configs (company_id, issuer, client_id, client_secret, default_role, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (company_id) DO UPDATE
+			SET issuer = EXCLUDED.issuer,
+				client_id = EXCLUDED.client_id,
+				client_secret = EXCLUDED.client_secret,
+				default_role = EXCLUDED.default_role,
+				enabled = EXCLUDED.enabled,
+				updated_at = now()
+		RETURNING created_at, updated_at;
+	`
+
+	// SQLStatementDeleteCompanySSOConfig deletes the single sign-on configuration of a company.
+	SQLStatementDeleteCompanySSOConfig = `
+		DELETE FROM company_sso_configs
+		WHERE company_id = $1;
+	`
+
+	// SQLStatementInsertSSOLoginState inserts a new single sign-on login state.
+	SQLStatementInsertSSOLoginState = `
+		INSERT INTO sso_login_states (company_id, state_hash, code_verifier, nonce, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at;
+	`
+
+	// SQLStatementSelectSSOLoginStateByHash selects a single sign-on login state by its hash.
+	SQLStatementSelectSSOLoginStateByHash = `
+		SELECT
+			id,
+			company_id,
+			state_hash,
+			code_verifier,
+			nonce,
+			expires_at,
+			used_at,
+			created_at
+		FROM sso_login_states
+		WHERE state_hash = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementUseSSOLoginState marks a single sign-on login state as used if it is still unused.
+	SQLStatementUseSSOLoginState = `
+		UPDATE sso_login_states
+			SET used_at = now()
+		WHERE id = $1
+			AND used_at IS NULL;
+	`
+
+	// SQLStatementSelectUserIdentity selects the identity of a subject at an identity provider.
+	SQLStatementSelectUserIdentity = `
+		SELECT
+			id,
+			user_id,
+			issuer,
+			subject,
+			created_at
+		FROM user_identities
+		WHERE issuer = $1
+			AND subject = $2
+		LIMIT 1;
+	`
+
+	// SQLStatementInsertUserIdentity links a user to a subject at an identity provider.
+	SQLStatementInsertUserIdentity = `
+		INSERT INTO user_identities (user_id, issuer, subject)
+		VALUES ($1, $2, $3);
+	`
+)
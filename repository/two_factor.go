@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// TwoFactorRepository provides methods for interacting with the "two_factor_recovery_codes"
+// and "two_factor_challenges" database tables.
+type TwoFactorRepository struct {
+	db *sql.DB
+}
+
+// NewTwoFactorRepository initializes a new TwoFactorRepository with a given database connection.
+func NewTwoFactorRepository(db *sql.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// ReplaceRecoveryCodes replaces every recovery code of a user with the given code hashes.
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	const ops = "TwoFactorRepository.ReplaceRecoveryCodes"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to begin database transaction: %v", err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, SQLStatementDeleteUserRecoveryCodes, userID); err != nil {
+		tx.Rollback()
+		logger.Errorf(ctx, ops, "failed to delete recovery codes: %v", err)
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, SQLStatementInsertRecoveryCode, userID, codeHash); err != nil {
+			tx.Rollback()
+			logger.Errorf(ctx, ops, "failed to insert recovery code: %v", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteRecoveryCodes deletes every recovery code of a user.
+func (r *TwoFactorRepository) DeleteRecoveryCodes(ctx context.Context, userID int) error {
+	const ops = "TwoFactorRepository.DeleteRecoveryCodes"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementDeleteUserRecoveryCodes, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to delete recovery codes: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks a recovery code of a user as used.
+// It returns false when the code does not exist or was already used, so a code can only be used once.
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	const ops = "TwoFactorRepository.UseRecoveryCode"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementUseRecoveryCode, userID, codeHash)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to use recovery code: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// CreateChallenge persists a new two-factor challenge and returns it with its generated ID.
+func (r *TwoFactorRepository) CreateChallenge(ctx context.Context, challenge entity.TwoFactorChallenge) (*entity.TwoFactorChallenge, error) {
+	const ops = "TwoFactorRepository.CreateChallenge"
+
+	row := r.db.QueryRowContext(
+		ctx,
+		SQLStatementInsertTwoFactorChallenge,
+		challenge.UserID,
+		challenge.TokenHash,
+		challenge.Remember,
+		challenge.ExpiresAt,
+		challenge.SSOCompanyID,
+	)
+
+	if err := row.Scan(&challenge.ID, &challenge.CreatedAt); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert two-factor challenge: %v", err)
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// FindChallengeByHash retrieves a two-factor challenge based on its token hash.
+func (r *TwoFactorRepository) FindChallengeByHash(ctx context.Context, tokenHash string) (*entity.TwoFactorChallenge, error) {
+	var challenge entity.TwoFactorChallenge
+
+	row := r.db.QueryRowContext(ctx, SQLStatementSelectTwoFactorChallengeByHash, tokenHash)
+	if err := row.Scan(
+		&challenge.ID,
+		&challenge.UserID,
+		&challenge.TokenHash,
+		&challenge.Remember,
+		&challenge.Attempts,
+		&challenge.ExpiresAt,
+		&challenge.UsedAt,
+		&challenge.CreatedAt,
+		&challenge.SSOCompanyID,
+	); err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// UseChallenge marks a two-factor challenge as used.
+// It returns false when the challenge was already used, so a challenge can only be completed once.
+func (r *TwoFactorRepository) UseChallenge(ctx context.Context, challengeID int) (bool, error) {
+	const ops = "TwoFactorRepository.UseChallenge"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementUseTwoFactorChallenge, challengeID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to use two-factor challenge: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// ClaimChallengeAttempt records an attempt on a two-factor challenge.
+// It returns false when the challenge was used or reached maxAttempts, the check and the increment
+// are a single statement so concurrent attempts can not go past the limit.
+func (r *TwoFactorRepository) ClaimChallengeAttempt(ctx context.Context, challengeID, maxAttempts int) (bool, error) {
+	const ops = "TwoFactorRepository.ClaimChallengeAttempt"
+
+	var attempts int
+	if err := r.db.QueryRowContext(ctx, SQLStatementClaimTwoFactorChallengeAttempt, challengeID, maxAttempts).Scan(&attempts); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		logger.Errorf(ctx, ops, "failed to claim two-factor challenge attempt: %v", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UseChallengeWithRecoveryCode marks a two-factor challenge as used, then the recovery code of the user.
+// Both happen in one transaction: it returns false and changes nothing when the challenge was already used,
+// or when the code does not exist or was already used, so a recovery code is never spent on a used challenge.
+func (r *TwoFactorRepository) UseChallengeWithRecoveryCode(ctx context.Context, challengeID, userID int, codeHash string) (bool, error) {
+	const ops = "TwoFactorRepository.UseChallengeWithRecoveryCode"
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to begin database transaction: %v", err)
+		return false, err
+	}
+
+	result, err := tx.ExecContext(ctx, SQLStatementUseTwoFactorChallenge, challengeID)
+	if err != nil {
+		tx.Rollback()
+		logger.Errorf(ctx, ops, "failed to use two-factor challenge: %v", err)
+		return false, err
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected != 1 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	result, err = tx.ExecContext(ctx, SQLStatementUseRecoveryCode, userID, codeHash)
+	if err != nil {
+		tx.Rollback()
+		logger.Errorf(ctx, ops, "failed to use recovery code: %v", err)
+		return false, err
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected != 1 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf(ctx, ops, "failed to commit database transaction: %v", err)
+		return false, err
+	}
+
+	return true, nil
+}
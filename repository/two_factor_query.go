@@ -0,0 +1,67 @@
+package repository
+
+var (
+	// SQLStatementDeleteUserRecoveryCodes deletes every recovery code of a user.
+	SQLStatementDeleteUserRecoveryCodes = `
+		DELETE FROM two_factor_recovery_codes
+		WHERE user_id = $1;
+	`
+
+	// SQLStatementInsertRecoveryCode inserts a new recovery code for a user.
+	SQLStatementInsertRecoveryCode = `
+		INSERT INTO two_factor_recovery_codes (user_id, code_hash)
+		VALUES ($1, $2);
+	`
+
+	// SQLStatementUseRecoveryCode marks a recovery code of a user as used if it is still unused.
+	SQLStatementUseRecoveryCode = `
+		UPDATE two_factor_recovery_codes
+			SET used_at = now()
+		WHERE user_id = $1
+			AND code_hash = $2
+			AND used_at IS NULL;
+	`
+
+	// SQLStatementInsertTwoFactorChallenge inserts a new two-factor challenge and returns its ID.
+	SQLStatementInsertTwoFactorChallenge = `
+		INSERT INTO two_factor_challenges (user_id, token_hash, remember, expires_at, sso_company_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at;
+	`
+
+	// SQLStatementSelectTwoFactorChallengeByHash selects a two-factor challenge by its token hash.
+	SQLStatementSelectTwoFactorChallengeByHash = `
+		SELECT
+			id,
+			user_id,
+			token_hash,
+			remember,
+			attempts,
+			expires_at,
+			used_at,
+			created_at,
+			sso_company_id
+		FROM two_factor_challenges
+		WHERE token_hash = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementUseTwoFactorChallenge marks a two-factor challenge as used if it is still unused.
+	SQLStatementUseTwoFactorChallenge = `
+		UPDATE two_factor_challenges
+			SET used_at = now()
+		WHERE id = $1
+			AND used_at IS NULL;
+	`
+
+	// SQLStatementClaimTwoFactorChallengeAttempt records an attempt on a two-factor challenge
+	// if it is still unused and below the maximum number of attempts.
+	SQLStatementClaimTwoFactorChallengeAttempt = `
+		UPDATE two_factor_challenges
+			SET attempts = attempts + 1
+		WHERE id = $1
+			AND attempts < $2
+			AND used_at IS NULL
+		RETURNING attempts;
+	`
+)
@@ -59,7 +59,12 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity
 		&existingUser.Email,
 		&existingUser.Password,
 		&existingUser.PhoneNumber,
+		&existingUser.JobTitle,
 		&existingUser.CompanyID,
+		&existingUser.PasswordChangedAt,
+		&existingUser.EmailVerifiedAt,
+		&existingUser.TwoFactorSecret,
+		&existingUser.TwoFactorEnabledAt,
 	); err != nil {
 		return nil, err
 	}
@@ -82,10 +87,300 @@ func (r *UserRepository) GetUserByID(ctx context.Context, userID int) (targetUse
 		&targetUser.Email,
 		&targetUser.Password,
 		&targetUser.PhoneNumber,
+		&targetUser.JobTitle,
 		&targetUser.CompanyID,
+		&targetUser.PasswordChangedAt,
+		&targetUser.EmailVerifiedAt,
+		&targetUser.TwoFactorSecret,
+		&targetUser.TwoFactorEnabledAt,
 	); err != nil {
 		return nil, err
 	}
 
 	return targetUser, nil
 }
+
+// UpdateProfile replaces the name, phone number and job title of a user.
+// It returns false when the user does not exist.
+func (r *UserRepository) UpdateProfile(ctx context.Context, user entity.User) (bool, error) {
+	const ops = "UserRepository.UpdateProfile"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementUpdateUserProfile, user.FirstName, user.LastName, user.PhoneNumber, user.JobTitle, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update user profile: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// UpdatePassword replaces the password hash of a user and records when the password was changed.
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID int, hashedPassword string) error {
+	const ops = "UserRepository.UpdatePassword"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementUpdateUserPassword, hashedPassword, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to update user password: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RehashPassword replaces the password hash of a user by an upgraded hash of the same password.
+// It returns false when the stored hash is no longer previousHash, such as after a concurrent password change.
+func (r *UserRepository) RehashPassword(ctx context.Context, userID int, previousHash, hashedPassword string) (bool, error) {
+	const ops = "UserRepository.RehashPassword"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementRehashUserPassword, hashedPassword, userID, previousHash)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to rehash user password: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// CreateInvitedUser inserts a user who accepted an invitation to the company within the transaction,
+// with their email address already verified when emailVerified is true.
+func (r *UserRepository) CreateInvitedUser(ctx context.Context, tx *sql.Tx, newUser entity.User, companyID int, emailVerified bool) (*entity.User, error) {
+	const ops = "UserRepository.CreateInvitedUser"
+
+	row := tx.QueryRowContext(
+		ctx, SQLStatementInsertUser,
+		newUser.FirstName,
+		newUser.LastName,
+		newUser.Role,
+		newUser.Email,
+		newUser.Password,
+		newUser.PhoneNumber,
+		companyID,
+	)
+
+	if err := row.Scan(&newUser.ID); err != nil {
+		logger.Errorf(ctx, ops, "failed to insert new user: %v", err)
+		return nil, err
+	}
+
+	if emailVerified {
+		if _, err := tx.ExecContext(ctx, SQLStatementMarkUserEmailVerified, newUser.ID); err != nil {
+			logger.Errorf(ctx, ops, "failed to mark user email as verified: %v", err)
+			return nil, err
+		}
+	}
+
+	return &newUser, nil
+}
+
+// MarkEmailVerified marks the email address of a user as verified.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID int) error {
+	const ops = "UserRepository.MarkEmailVerified"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementMarkUserEmailVerified, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to mark user email as verified: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ListCompanyMembers retrieves every user belonging to the given company.
+func (r *UserRepository) ListCompanyMembers(ctx context.Context, companyID int) ([]entity.User, error) {
+	const ops = "UserRepository.ListCompanyMembers"
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectCompanyMembers, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch company members: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []entity.User{}
+	for rows.Next() {
+		var member entity.User
+		if err := rows.Scan(
+			&member.ID,
+			&member.FirstName,
+			&member.LastName,
+			&member.Role,
+			&member.Email,
+			&member.PhoneNumber,
+			&member.JobTitle,
+			&member.CompanyID,
+			&member.CreatedAt,
+		); err != nil {
+			logger.Errorf(ctx, ops, "failed to scan a company member: %v", err)
+			return nil, err
+		}
+
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// UpdateCompanyMemberRole changes the role of a user belonging to the given company.
+// It returns false when the user is not a member of the company.
+func (r *UserRepository) UpdateCompanyMemberRole(ctx context.Context, companyID, userID int, role entity.UserRole) (bool, error) {
+	const ops = "UserRepository.UpdateCompanyMemberRole"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementUpdateCompanyMemberRole, role, userID, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update company member role: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// RemoveCompanyMember removes the membership of a user to the given company.
+// It returns false when the user is not a member of the company.
+func (r *UserRepository) RemoveCompanyMember(ctx context.Context, companyID, userID int) (bool, error) {
+	const ops = "UserRepository.RemoveCompanyMember"
+
+	var removed int
+	if err := r.db.QueryRowContext(ctx, SQLStatementRemoveCompanyMember, userID, companyID).Scan(&removed); err != nil {
+		logger.Errorf(ctx, ops, "failed to remove company member: %v", err)
+		return false, err
+	}
+
+	return removed == 1, nil
+}
+
+// AddCompanyMember adds a user to the given company with the given role.
+// It returns false when the user already belongs to the company.
+func (r *UserRepository) AddCompanyMember(ctx context.Context, tx *sql.Tx, companyID, userID int, role entity.UserRole) (bool, error) {
+	const ops = "UserRepository.AddCompanyMember"
+
+	var added int
+	if err := tx.QueryRowContext(ctx, SQLStatementInsertCompanyMember, userID, companyID, role).Scan(&added); err != nil {
+		logger.Errorf(ctx, ops, "failed to add company member: %v", err)
+		return false, err
+	}
+
+	return added == 1, nil
+}
+
+// ListUserMemberships retrieves every company the given user belongs to, ordered by company name.
+func (r *UserRepository) ListUserMemberships(ctx context.Context, userID int) ([]entity.CompanyMembership, error) {
+	const ops = "UserRepository.ListUserMemberships"
+
+	rows, err := r.db.QueryContext(ctx, SQLStatementSelectUserMemberships, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch user memberships: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []entity.CompanyMembership{}
+	for rows.Next() {
+		membership, err := scanCompanyMembership(rows)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to scan a user membership: %v", err)
+			return nil, err
+		}
+
+		memberships = append(memberships, *membership)
+	}
+
+	return memberships, rows.Err()
+}
+
+// FindCompanyMembership retrieves the membership of a user to a company.
+// It returns sql.ErrNoRows when the user does not belong to the company.
+func (r *UserRepository) FindCompanyMembership(ctx context.Context, userID, companyID int) (*entity.CompanyMembership, error) {
+	return scanCompanyMembership(r.db.QueryRowContext(ctx, SQLStatementSelectUserMembership, userID, companyID))
+}
+
+// SetCurrentCompany makes a company the user belongs to their current company, used when they sign in.
+// It returns false when the user does not belong to the company.
+func (r *UserRepository) SetCurrentCompany(ctx context.Context, userID, companyID int) (bool, error) {
+	const ops = "UserRepository.SetCurrentCompany"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementUpdateUserCurrentCompany, userID, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to set current company: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// FindCompanyMemberWithRole retrieves the oldest member of a company having the given role,
+// or the given member when userID is not 0. It returns sql.ErrNoRows when there is no such member.
+func (r *UserRepository) FindCompanyMemberWithRole(ctx context.Context, companyID int, role entity.UserRole, userID int) (*entity.User, error) {
+	var member entity.User
+	if err := r.db.QueryRowContext(ctx, SQLStatementSelectCompanyMemberWithRole, companyID, role, userID).Scan(
+		&member.ID,
+		&member.FirstName,
+		&member.LastName,
+		&member.Role,
+		&member.Email,
+		&member.CompanyID,
+	); err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// SetTwoFactorSecret stores a new two-factor secret for a user, pending its confirmation.
+// It returns false when the user already enabled two-factor authentication.
+func (r *UserRepository) SetTwoFactorSecret(ctx context.Context, userID int, secret string) (bool, error) {
+	const ops = "UserRepository.SetTwoFactorSecret"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementSetUserTwoFactorSecret, secret, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to set two-factor secret: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// EnableTwoFactor enables the two-factor authentication of a user who stored a secret.
+// It returns false when there is no pending secret.
+func (r *UserRepository) EnableTwoFactor(ctx context.Context, userID int) (bool, error) {
+	const ops = "UserRepository.EnableTwoFactor"
+
+	result, err := r.db.ExecContext(ctx, SQLStatementEnableUserTwoFactor, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to enable two-factor: %v", err)
+		return false, err
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected == 1, nil
+}
+
+// DisableTwoFactor disables the two-factor authentication of a user and forgets its secret.
+func (r *UserRepository) DisableTwoFactor(ctx context.Context, userID int) error {
+	const ops = "UserRepository.DisableTwoFactor"
+
+	if _, err := r.db.ExecContext(ctx, SQLStatementDisableUserTwoFactor, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to disable two-factor: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func scanCompanyMembership(row rowScanner) (*entity.CompanyMembership, error) {
+	var membership entity.CompanyMembership
+	if err := row.Scan(
+		&membership.UserID,
+		&membership.CompanyID,
+		&membership.CompanyName,
+		&membership.CompanyLogoURL,
+		&membership.Role,
+		&membership.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
@@ -1,42 +1,271 @@
 package repository
 
 var (
-	// SQLStatementInsertUser Insert a new user and return the user ID
+	// SQLStatementInsertUser Insert a new user, with their membership of the company when given, and return the user ID
 	SQLStatementInsertUser = `
-		INSERT INTO users (first_name, last_name, role, email, password_hash, phone, company_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id;
+		WITH new_user AS (
+			INSERT INTO users (first_name, last_name, role, email, password_hash, phone, company_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, company_id, role
+		), new_membership AS (
+			INSERT INTO company_memberships (user_id, company_id, role)
+			SELECT id, company_id, role
+			FROM new_user
+			WHERE company_id IS NOT NULL
+		)
+		SELECT id FROM new_user;
 	`
 
 	// SQLStatementSelectUserByEmail Select a user by email
+	// The role is the one of the user in their current company.
 	SQLStatementSelectUserByEmail = `
 		SELECT
-			id,
-			first_name,
-			last_name,
-			role,
-			email,
-			password_hash,
-			phone,
-			company_id
-		FROM users
-		WHERE email = $1
+			u.id,
+			u.first_name,
+			u.last_name,
+			COALESCE(m.role, u.role),
+			u.email,
+			u.password_hash,
+			u.phone,
+			u.job_title,
+			u.company_id,
+			u.password_changed_at,
+			u.email_verified_at,
+			u.two_factor_secret,
+			u.two_factor_enabled_at
+		FROM users u
+		LEFT JOIN company_memberships m
+			ON m.user_id = u.id
+			AND m.company_id = u.company_id
+		WHERE u.email = $1
 		LIMIT 1;
 	`
 
 	// SQLStatementSelectUserByID Select a user by ID
+	// The role is the one of the user in their current company.
 	SQLStatementSelectUserByID = `
 		SELECT
-			id,
-			first_name,
-			last_name,
-			role,
-			email,
-			password_hash,
-			phone,
-			company_id
-		FROM users
-		WHERE id = $1
+			u.id,
+			u.first_name,
+			u.last_name,
+			COALESCE(m.role, u.role),
+			u.email,
+			u.password_hash,
+			u.phone,
+			u.job_title,
+			u.company_id,
+			u.password_changed_at,
+			u.email_verified_at,
+			u.two_factor_secret,
+			u.two_factor_enabled_at
+		FROM users u
+		LEFT JOIN company_memberships m
+			ON m.user_id = u.id
+			AND m.company_id = u.company_id
+		WHERE u.id = $1
+		LIMIT 1;
+	`
+
+	// SQLStatementUpdateUserProfile updates the name, phone number and job title of a user.
+	SQLStatementUpdateUserProfile = `
+		UPDATE users
+			SET first_name = $1,
+				last_name = $2,
+				phone = $3,
+				job_title = $4,
+				updated_at = now()
+		WHERE id = $5;
+	`
+
+	// SQLStatementUpdateUserPassword updates a user's password hash and records when it was changed.
+	SQLStatementUpdateUserPassword = `
+		UPDATE users
+			SET password_hash = $1,
+				password_changed_at = now(),
+				updated_at = now()
+		WHERE id = $2;
+	`
+
+	// SQLStatementRehashUserPassword replaces a user's password hash by an upgraded hash of the same password.
+	// The password is unchanged so password_changed_at is kept, and nothing is updated when the hash changed meanwhile.
+	SQLStatementRehashUserPassword = `
+		UPDATE users
+			SET password_hash = $1,
+				updated_at = now()
+		WHERE id = $2
+			AND password_hash = $3;
+	`
+
+	// SQLStatementMarkUserEmailVerified marks a user's email address as verified.
+	SQLStatementMarkUserEmailVerified = `
+		UPDATE users
+			SET email_verified_at = now(),
+				updated_at = now()
+		WHERE id = $1;
+	`
+
+	// SQLStatementSelectCompanyMembers selects every user belonging to a company, with their role in the company.
+	SQLStatementSelectCompanyMembers = `
+		SELECT
+			u.id,
+			u.first_name,
+			u.last_name,
+			m.role,
+			u.email,
+			u.phone,
+			u.job_title,
+			m.company_id,
+			m.created_at
+		FROM company_memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.company_id = $1
+			AND u.deleted_at IS NULL
+		ORDER BY m.created_at ASC;
+	`
+
+	// SQLStatementUpdateCompanyMemberRole updates the role of a user belonging to a company.
+	SQLStatementUpdateCompanyMemberRole = `
+		UPDATE company_memberships
+			SET role = $1,
+				updated_at = now()
+		WHERE user_id = $2
+			AND company_id = $3;
+	`
+
+	// SQLStatementRemoveCompanyMember removes the membership of a user to a company and returns the number of removed memberships.
+	// When it was the current company of the user, they are moved to their oldest remaining membership.
+	SQLStatementRemoveCompanyMember = `
+		WITH membership AS (
+			DELETE FROM company_memberships
+			WHERE user_id = $1
+				AND company_id = $2
+			RETURNING user_id
+		), current_company AS (
+			UPDATE users
+				SET company_id = (
+						SELECT company_id
+						FROM company_memberships
+						WHERE user_id = $1
+							AND company_id <> $2
+						ORDER BY created_at ASC
+						LIMIT 1
+					),
+					updated_at = now()
+			WHERE id IN (SELECT user_id FROM membership)
+				AND company_id = $2
+		)
+		SELECT count(*) FROM membership;
+	`
+
+	// SQLStatementInsertCompanyMember adds a user to a company and returns the number of created memberships,
+	// which is 0 when the user already belongs to the company. It becomes the current company of users without one.
+	SQLStatementInsertCompanyMember = `
+		WITH membership AS (
+			INSERT INTO company_memberships (user_id, company_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, company_id) DO NOTHING
+			RETURNING user_id, company_id, role
+		), current_company AS (
+			UPDATE users
+				SET company_id = membership.company_id,
+					role = membership.role,
+					updated_at = now()
+			FROM membership
+			WHERE users.id = membership.user_id
+				AND users.company_id IS NULL
+		)
+		SELECT count(*) FROM membership;
+	`
+
+	// SQLStatementSelectUserMemberships selects every company a user belongs to.
+	SQLStatementSelectUserMemberships = `
+		SELECT
+			m.user_id,
+			m.company_id,
+			c.name,
+			c.logo_url,
+			m.role,
+			m.created_at
+		FROM company_memberships m
+		JOIN companies c ON c.id = m.company_id
+		WHERE m.user_id = $1
+		ORDER BY c.name ASC;
+	`
+
+	// SQLStatementSelectUserMembership selects the membership of a user to a company.
+	SQLStatementSelectUserMembership = `
+		SELECT
+			m.user_id,
+			m.company_id,
+			c.name,
+			c.logo_url,
+			m.role,
+			m.created_at
+		FROM company_memberships m
+		JOIN companies c ON c.id = m.company_id
+		WHERE m.user_id = $1
+			AND m.company_id = $2
 		LIMIT 1;
 	`
+
+	// SQLStatementUpdateUserCurrentCompany makes a company the user belongs to their current company.
+	SQLStatementUpdateUserCurrentCompany = `
+		UPDATE users
+			SET company_id = m.company_id,
+				role = m.role,
+				updated_at = now()
+		FROM company_memberships m
+		WHERE users.id = $1
+			AND m.user_id = users.id
+			AND m.company_id = $2;
+	`
+
+	// SQLStatementSelectCompanyMemberWithRole selects the oldest member of a company having the given role,
+	// or the given member when $3 is not 0.
+	SQLStatementSelectCompanyMemberWithRole = `
+		SELECT
+			u.id,
+			u.first_name,
+			u.last_name,
+			m.role,
+			u.email,
+			m.company_id
+		FROM company_memberships m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.company_id = $1
+			AND m.role = $2
+			AND ($3 = 0 OR m.user_id = $3)
+			AND u.deleted_at IS NULL
+		ORDER BY m.created_at ASC
+		LIMIT 1;
+	`
+
+	// SQLStatementSetUserTwoFactorSecret stores a new, not yet enabled, two-factor secret for a user.
+	SQLStatementSetUserTwoFactorSecret = `
+		UPDATE users
+			SET two_factor_secret = $1,
+				two_factor_enabled_at = NULL,
+				updated_at = now()
+		WHERE id = $2
+			AND two_factor_enabled_at IS NULL;
+	`
+
+	// SQLStatementEnableUserTwoFactor enables the two-factor authentication of a user.
+	SQLStatementEnableUserTwoFactor = `
+		UPDATE users
+			SET two_factor_enabled_at = now(),
+				updated_at = now()
+		WHERE id = $1
+			AND two_factor_secret IS NOT NULL
+			AND two_factor_enabled_at IS NULL;
+	`
+
+	// SQLStatementDisableUserTwoFactor disables the two-factor authentication of a user.
+	SQLStatementDisableUserTwoFactor = `
+		UPDATE users
+			SET two_factor_secret = NULL,
+				two_factor_enabled_at = NULL,
+				updated_at = now()
+		WHERE id = $1;
+	`
 )
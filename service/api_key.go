@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+// APIKeyRepository defines the contract for persisting API keys.
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, apiKey entity.APIKey) (*entity.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	ListCompanyAPIKeys(ctx context.Context, companyID int) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, companyID, apiKeyID int) (bool, error)
+	TouchAPIKey(ctx context.Context, apiKeyID int) error
+}
+
+// RolePermissionResolver defines the contract for resolving the permissions granted to a role of a company.
+type RolePermissionResolver interface {
+	GetRolePermissions(ctx context.Context, companyID int, role entity.UserRole) ([]entity.Permission, error)
+}
+
+// APIKeyMembershipFinder defines the contract for finding the membership of the creator of an API key.
+type APIKeyMembershipFinder interface {
+	FindCompanyMembership(ctx context.Context, userID, companyID int) (*entity.CompanyMembership, error)
+}
+
+// APIKeyService manages the API keys machines use to call the API on behalf of a company.
+type APIKeyService struct {
+	apiKeyRepository       APIKeyRepository
+	rolePermissionResolver RolePermissionResolver
+	membershipFinder       APIKeyMembershipFinder
+}
+
+// NewAPIKeyService initializes a new APIKeyService.
+// membershipFinder is used to check the creator of a key still belongs to its company on every request.
+func NewAPIKeyService(apiKeyRepository APIKeyRepository, rolePermissionResolver RolePermissionResolver, membershipFinder APIKeyMembershipFinder) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepository:       apiKeyRepository,
+		rolePermissionResolver: rolePermissionResolver,
+		membershipFinder:       membershipFinder,
+	}
+}
+
+// CreateAPIKey creates a new API key for a company and returns it along with the plain key.
+// The plain key is only available at creation. Scopes have to be part of entity.APIKeyScopes
+// and granted to the role of the creator, so a key never has more permissions than the user who created it.
+func (s *APIKeyService) CreateAPIKey(
+	ctx context.Context,
+	companyID, createdBy int,
+	creatorRole entity.UserRole,
+	name string,
+	scopes []entity.Permission,
+	expiresAt *time.Time,
+) (*entity.APIKey, string, error) {
+	const ops = "APIKeyService.CreateAPIKey"
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", entity.ErrAPIKeyMissingName
+	}
+
+	creatorPermissions, err := s.rolePermissionResolver.GetRolePermissions(ctx, companyID, creatorRole)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(scopes) == 0 {
+		return nil, "", entity.ErrAPIKeyInvalidScope
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(entity.APIKeyScopes, scope) || !slices.Contains(creatorPermissions, scope) {
+			return nil, "", entity.ErrAPIKeyInvalidScope
+		}
+	}
+
+	key, prefix, err := pkg.GenerateAPIKey()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate api key: %v", err)
+		return nil, "", entity.UnknownError(err)
+	}
+
+	apiKey, err := s.apiKeyRepository.CreateAPIKey(ctx, entity.APIKey{
+		CompanyID: companyID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   pkg.HashToken(key),
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to store api key: %v", err)
+		return nil, "", entity.UnknownError(err)
+	}
+
+	return apiKey, key, nil
+}
+
+// ListAPIKeys retrieves the API keys of a company that have not been revoked.
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, companyID int) ([]entity.APIKey, error) {
+	const ops = "APIKeyService.ListAPIKeys"
+
+	apiKeys, err := s.apiKeyRepository.ListCompanyAPIKeys(ctx, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to list api keys: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return apiKeys, nil
+}
+
+// RevokeAPIKey revokes an API key of a company, requests using it are refused right away.
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, companyID, apiKeyID int) error {
+	const ops = "APIKeyService.RevokeAPIKey"
+
+	revoked, err := s.apiKeyRepository.RevokeAPIKey(ctx, companyID, apiKeyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke api key: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !revoked {
+		return entity.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey returns the API key matching the given plain key when it is neither revoked nor expired,
+// and its creator still belongs to the company with a role granting every scope of the key.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*entity.APIKey, error) {
+	const ops = "APIKeyService.AuthenticateAPIKey"
+
+	if !strings.HasPrefix(key, pkg.APIKeyPrefix) {
+		return nil, entity.ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepository.FindByHash(ctx, pkg.HashToken(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrInvalidAPIKey
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve api key: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if apiKey.IsRevoked() {
+		return nil, entity.ErrInvalidAPIKey
+	}
+
+	if apiKey.IsExpired() {
+		return nil, entity.ErrAPIKeyIsExpired
+	}
+
+	// a key never has more permissions than its creator, who may have left the company or been demoted since.
+	membership, err := s.membershipFinder.FindCompanyMembership(ctx, apiKey.CreatedBy, apiKey.CompanyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrAPIKeyCreatorPermissionRevoked
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve api key creator membership: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	creatorPermissions, err := s.rolePermissionResolver.GetRolePermissions(ctx, apiKey.CompanyID, membership.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range apiKey.Scopes {
+		if !slices.Contains(creatorPermissions, scope) {
+			return nil, entity.ErrAPIKeyCreatorPermissionRevoked
+		}
+	}
+
+	// failing to record the usage must not fail the request.
+	_ = s.apiKeyRepository.TouchAPIKey(ctx, apiKey.ID)
+
+	return apiKey, nil
+}
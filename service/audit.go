@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+// AuditRepository defines the contract for persisting audit logs.
+type AuditRepository interface {
+	CreateAuditLog(ctx context.Context, auditLog entity.AuditLog) error
+	ListAuditLogs(ctx context.Context, filter entity.AuditLogFilter, limit, offset int) ([]entity.AuditLog, int, error)
+}
+
+// AuditRecorder defines the contract for recording the state-changing actions of services.
+type AuditRecorder interface {
+	Record(ctx context.Context, auditLog entity.AuditLog, before, after any)
+}
+
+// AuditService records state-changing actions and lets admins browse them.
+type AuditService struct {
+	auditRepository AuditRepository
+}
+
+// NewAuditService initializes a new AuditService.
+func NewAuditService(auditRepository AuditRepository) *AuditService {
+	return &AuditService{auditRepository: auditRepository}
+}
+
+// Record records an action. The actor, company, API key, impersonator, IP address and request ID missing from auditLog
+// are taken from the request metadata of ctx. before and after are the state of the target around the action,
+// only the top-level fields that differ are kept; either of them is nil when the target was created or removed.
+// Recording is best effort: failures are logged and never fail the recorded action.
+func (s *AuditService) Record(ctx context.Context, auditLog entity.AuditLog, before, after any) {
+	const ops = "AuditService.Record"
+
+	metadata := pkg.RequestMetadataFromContext(ctx)
+	if auditLog.ActorID == nil && metadata.ActorID != 0 {
+		auditLog.ActorID = pointer.ToInt(metadata.ActorID)
+	}
+	if auditLog.CompanyID == nil && metadata.CompanyID != 0 {
+		auditLog.CompanyID = pointer.ToInt(metadata.CompanyID)
+	}
+	if auditLog.APIKeyID == nil && metadata.APIKeyID != 0 {
+		auditLog.APIKeyID = pointer.ToInt(metadata.APIKeyID)
+	}
+	if auditLog.ImpersonatorID == nil && metadata.ImpersonatorID != 0 {
+		auditLog.ImpersonatorID = pointer.ToInt(metadata.ImpersonatorID)
+	}
+	if auditLog.IP == "" {
+		auditLog.IP = metadata.IP
+	}
+	if auditLog.RequestID == "" {
+		auditLog.RequestID = metadata.RequestID
+	}
+
+	var err error
+	auditLog.Before, auditLog.After, err = diffAuditStates(before, after)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to encode the state of %s %s: %v", auditLog.TargetType, auditLog.TargetID, err)
+	}
+
+	if err := s.auditRepository.CreateAuditLog(ctx, auditLog); err != nil {
+		logger.Errorf(ctx, ops, "failed to record %s on %s %s: %v", auditLog.Action, auditLog.TargetType, auditLog.TargetID, err)
+	}
+}
+
+// ListAuditLogs retrieves a paginated list of the audit logs of a company matching the filter, newest first.
+func (s *AuditService) ListAuditLogs(ctx context.Context, filter entity.AuditLogFilter, request entity.PaginationRequest) (response entity.PaginationResponse, err error) {
+	const ops = "AuditService.ListAuditLogs"
+
+	offset := (request.Page - 1) * request.PerPage
+	auditLogs, totalRecords, err := s.auditRepository.ListAuditLogs(ctx, filter, request.PerPage, offset)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to list audit logs: %v", err)
+		return response, entity.UnknownError(err)
+	}
+
+	lastPage := (totalRecords + request.PerPage - 1) / request.PerPage
+
+	return entity.PaginationResponse{
+		Records:      auditLogs,
+		Page:         request.Page,
+		PerPage:      request.PerPage,
+		LastPage:     lastPage,
+		TotalRecords: totalRecords,
+	}, nil
+}
+
+// diffAuditStates encodes the states of a target before and after an action.
+// When both states are JSON objects, fields holding the same value in both are left out.
+func diffAuditStates(before, after any) (json.RawMessage, json.RawMessage, error) {
+	beforeJSON, err := encodeAuditState(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterJSON, err := encodeAuditState(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if beforeJSON == nil || afterJSON == nil {
+		return beforeJSON, afterJSON, nil
+	}
+
+	var beforeFields, afterFields map[string]any
+	if json.Unmarshal(beforeJSON, &beforeFields) != nil || json.Unmarshal(afterJSON, &afterFields) != nil {
+		return beforeJSON, afterJSON, nil
+	}
+
+	for field, value := range beforeFields {
+		if afterValue, found := afterFields[field]; found && reflect.DeepEqual(value, afterValue) {
+			delete(beforeFields, field)
+			delete(afterFields, field)
+		}
+	}
+
+	if beforeJSON, err = json.Marshal(beforeFields); err != nil {
+		return nil, nil, err
+	}
+
+	if afterJSON, err = json.Marshal(afterFields); err != nil {
+		return nil, nil, err
+	}
+
+	return beforeJSON, afterJSON, nil
+}
+
+func encodeAuditState(state any) (json.RawMessage, error) {
+	if state == nil || reflect.ValueOf(state).Kind() == reflect.Pointer && reflect.ValueOf(state).IsNil() {
+		return nil, nil
+	}
+
+	return json.Marshal(state)
+}
@@ -2,7 +2,11 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/mail"
+	"strconv"
+	"time"
 
 	"github.com/AlekSi/pointer"
 	"github.com/mhdiiilham/gosm/entity"
@@ -15,41 +19,208 @@ type UserRepository interface {
 	CreateUser(ctx context.Context, newUser entity.User, companyID *int) (createdUser *entity.User, err error)
 	FindByEmail(ct context.Context, email string) (existingUser *entity.User, err error)
 	GetUserByID(ctx context.Context, userID int) (targetUser *entity.User, err error)
+	UpdateProfile(ctx context.Context, user entity.User) (bool, error)
+	UpdatePassword(ctx context.Context, userID int, hashedPassword string) error
+	RehashPassword(ctx context.Context, userID int, previousHash, hashedPassword string) (bool, error)
+	MarkEmailVerified(ctx context.Context, userID int) error
+	SetTwoFactorSecret(ctx context.Context, userID int, secret string) (bool, error)
+	EnableTwoFactor(ctx context.Context, userID int) (bool, error)
+	DisableTwoFactor(ctx context.Context, userID int) error
+	ListUserMemberships(ctx context.Context, userID int) ([]entity.CompanyMembership, error)
+	FindCompanyMembership(ctx context.Context, userID, companyID int) (*entity.CompanyMembership, error)
+	SetCurrentCompany(ctx context.Context, userID, companyID int) (bool, error)
 }
 
 // CompanyRepository defines an interface for company-related database operations.
 type CompanyRepository interface {
 	CreateCompany(ctx context.Context, companyName string) (*entity.Company, error)
 	FindByID(ctx context.Context, ID int) (*entity.Company, error)
+	UpdateRequireAdminTwoFactor(ctx context.Context, companyID int, required bool) error
+}
+
+// RefreshTokenRepository defines an interface for refresh token related database operations.
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token entity.RefreshToken) (*entity.RefreshToken, error)
+	FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, tokenID int) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int) error
+	RevokeUserCompanyRefreshTokens(ctx context.Context, userID, companyID int) error
+}
+
+// SessionRepository defines an interface for session related database operations.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session entity.Session) (*entity.Session, error)
+	FindSessionByFamilyID(ctx context.Context, familyID string) (*entity.Session, error)
+	FindUserSessionByID(ctx context.Context, userID, sessionID int) (*entity.Session, error)
+	ListActiveUserSessions(ctx context.Context, userID int) ([]entity.Session, error)
+	RevokeUserSession(ctx context.Context, userID, sessionID int) (bool, error)
+	RevokeOtherUserSessions(ctx context.Context, userID, keptSessionID int) (int, error)
+}
+
+// PasswordResetRepository defines an interface for password reset token related database operations.
+type PasswordResetRepository interface {
+	CreatePasswordResetToken(ctx context.Context, token entity.PasswordResetToken) (*entity.PasswordResetToken, error)
+	FindByHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+	UsePasswordResetToken(ctx context.Context, tokenID int) (bool, error)
+	InvalidateUserPasswordResetTokens(ctx context.Context, userID int) error
+}
+
+// EmailVerificationRepository defines an interface for email verification token related database operations.
+type EmailVerificationRepository interface {
+	CreateEmailVerificationToken(ctx context.Context, token entity.EmailVerificationToken) (*entity.EmailVerificationToken, error)
+	FindByHash(ctx context.Context, tokenHash string) (*entity.EmailVerificationToken, error)
+	UseEmailVerificationToken(ctx context.Context, tokenID int) (bool, error)
+	InvalidateUserEmailVerificationTokens(ctx context.Context, userID int) error
+}
+
+// TwoFactorRepository defines an interface for two-factor recovery codes and sign in challenges database operations.
+type TwoFactorRepository interface {
+	ReplaceRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	DeleteRecoveryCodes(ctx context.Context, userID int) error
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error)
+	CreateChallenge(ctx context.Context, challenge entity.TwoFactorChallenge) (*entity.TwoFactorChallenge, error)
+	FindChallengeByHash(ctx context.Context, tokenHash string) (*entity.TwoFactorChallenge, error)
+	UseChallenge(ctx context.Context, challengeID int) (bool, error)
+	ClaimChallengeAttempt(ctx context.Context, challengeID, maxAttempts int) (bool, error)
+	UseChallengeWithRecoveryCode(ctx context.Context, challengeID, userID int, codeHash string) (bool, error)
 }
 
 // PasswordHasher defines an interface for handling password hashing and comparison.
+// NeedsRehash reports hashes produced with an outdated algorithm or weaker parameters.
 type PasswordHasher interface {
 	HashPassword(plainPassword string) (hashedPassword string, err error)
 	ComparePassword(password, hashedPassword string) (passwordIsValid bool)
+	NeedsRehash(hashedPassword string) bool
 }
 
 // JwtGenerator defines an interface for handling JWT operations, including token creation and parsing.
 type JwtGenerator interface {
-	CreateAccessToken(userID int, companyID int, email string, userRole entity.UserRole) (response *entity.AuthResponse, err error)
+	CreateAccessToken(userID int, companyID int, email string, userRole entity.UserRole, sessionID int) (response *entity.AuthResponse, err error)
 	ParseToken(accessToken string) (*pkg.TokenClaims, error)
 }
 
+// Notifier defines an interface for delivering notifications, such as password reset links, to users.
+type Notifier interface {
+	Notify(ctx context.Context, notification entity.Notification) error
+}
+
+const (
+	// defaultRefreshTokenTTL is used when the configured refresh token duration is not set.
+	defaultRefreshTokenTTL = 24 * time.Hour
+
+	// defaultRememberRefreshTokenTTL is used when the configured "remember me" refresh token duration is not set.
+	defaultRememberRefreshTokenTTL = 30 * 24 * time.Hour
+
+	// defaultPasswordResetTokenTTL is used when the configured password reset token duration is not set.
+	defaultPasswordResetTokenTTL = time.Hour
+
+	// defaultEmailVerificationTokenTTL is used when the configured email verification token duration is not set.
+	defaultEmailVerificationTokenTTL = 48 * time.Hour
+
+	// defaultTwoFactorChallengeTTL is used when the configured two-factor challenge duration is not set.
+	defaultTwoFactorChallengeTTL = 5 * time.Minute
+)
+
+// AuthenticatorConfig holds the tunable values used by Authenticator.
+type AuthenticatorConfig struct {
+	// RefreshTokenTTL is the lifetime of a refresh token.
+	RefreshTokenTTL time.Duration
+
+	// RememberRefreshTokenTTL is the lifetime of a refresh token when the user asked to be remembered.
+	RememberRefreshTokenTTL time.Duration
+
+	// PasswordResetTokenTTL is the lifetime of a password reset token.
+	PasswordResetTokenTTL time.Duration
+
+	// PasswordResetURL is the frontend page receiving the password reset token as `token` query parameter.
+	PasswordResetURL string
+
+	// EmailVerificationTokenTTL is the lifetime of an email verification token.
+	EmailVerificationTokenTTL time.Duration
+
+	// EmailVerificationURL is the frontend page receiving the email verification token as `token` query parameter.
+	EmailVerificationURL string
+
+	// TwoFactorChallengeTTL is the time a user has to enter their two-factor code after giving their password.
+	TwoFactorChallengeTTL time.Duration
+
+	// TwoFactorIssuer is the name authenticator apps display next to the account.
+	TwoFactorIssuer string
+
+	// SignInThrottle holds the limits applied to failed sign in attempts.
+	SignInThrottle SignInThrottleConfig
+}
+
 // Authenticator struct provides authentication and authorization-related operations.
 type Authenticator struct {
-	userRepository    UserRepository
-	companyRepository CompanyRepository
-	passwordHasher    PasswordHasher
-	jwtGenerator      JwtGenerator
+	userRepository              UserRepository
+	companyRepository           CompanyRepository
+	refreshTokenRepository      RefreshTokenRepository
+	sessionRepository           SessionRepository
+	passwordResetRepository     PasswordResetRepository
+	emailVerificationRepository EmailVerificationRepository
+	twoFactorRepository         TwoFactorRepository
+	signInAttemptStore          SignInAttemptStore
+	passwordHasher              PasswordHasher
+	jwtGenerator                JwtGenerator
+	notifier                    Notifier
+	auditRecorder               AuditRecorder
+	config                      AuthenticatorConfig
 }
 
 // NewAuthorizationService initializes and returns an instance of Authenticator.
-func NewAuthorizationService(userRepository UserRepository, companyRepository CompanyRepository, passwordHasher PasswordHasher, jwtGenerator JwtGenerator) *Authenticator {
+func NewAuthorizationService(
+	userRepository UserRepository,
+	companyRepository CompanyRepository,
+	refreshTokenRepository RefreshTokenRepository,
+	sessionRepository SessionRepository,
+	passwordResetRepository PasswordResetRepository,
+	emailVerificationRepository EmailVerificationRepository,
+	twoFactorRepository TwoFactorRepository,
+	signInAttemptStore SignInAttemptStore,
+	passwordHasher PasswordHasher,
+	jwtGenerator JwtGenerator,
+	notifier Notifier,
+	auditRecorder AuditRecorder,
+	config AuthenticatorConfig,
+) *Authenticator {
+	if config.RefreshTokenTTL <= 0 {
+		config.RefreshTokenTTL = defaultRefreshTokenTTL
+	}
+
+	if config.RememberRefreshTokenTTL <= 0 {
+		config.RememberRefreshTokenTTL = defaultRememberRefreshTokenTTL
+	}
+
+	if config.PasswordResetTokenTTL <= 0 {
+		config.PasswordResetTokenTTL = defaultPasswordResetTokenTTL
+	}
+
+	if config.EmailVerificationTokenTTL <= 0 {
+		config.EmailVerificationTokenTTL = defaultEmailVerificationTokenTTL
+	}
+
+	if config.TwoFactorChallengeTTL <= 0 {
+		config.TwoFactorChallengeTTL = defaultTwoFactorChallengeTTL
+	}
+
+	config.SignInThrottle = config.SignInThrottle.withDefaults()
+
 	return &Authenticator{
-		userRepository:    userRepository,
-		companyRepository: companyRepository,
-		passwordHasher:    passwordHasher,
-		jwtGenerator:      jwtGenerator,
+		userRepository:              userRepository,
+		companyRepository:           companyRepository,
+		refreshTokenRepository:      refreshTokenRepository,
+		sessionRepository:           sessionRepository,
+		passwordResetRepository:     passwordResetRepository,
+		emailVerificationRepository: emailVerificationRepository,
+		twoFactorRepository:         twoFactorRepository,
+		signInAttemptStore:          signInAttemptStore,
+		passwordHasher:              passwordHasher,
+		jwtGenerator:                jwtGenerator,
+		notifier:                    notifier,
+		auditRecorder:               auditRecorder,
+		config:                      config,
 	}
 }
 
@@ -61,7 +232,8 @@ func (a *Authenticator) RegisterNewUser(ctx context.Context, user entity.User, c
 		return nil, nil, entity.ErrUserInvalidEmailAddress
 	}
 
-	if user.Role == "" {
+	// platform operators are never created by signing up.
+	if user.Role == "" || user.Role == entity.UserRoleSuperAdmin {
 		return nil, nil, entity.ErrUserRoleIsEmpty
 	}
 
@@ -73,6 +245,14 @@ func (a *Authenticator) RegisterNewUser(ctx context.Context, user entity.User, c
 		return nil, nil, entity.ErrUserPasswordEmpty
 	}
 
+	if user.PhoneNumber != nil {
+		phoneNumber, err := entity.NormalizePhoneNumber(*user.PhoneNumber)
+		if err != nil {
+			return nil, nil, entity.ErrUserInvalidPhoneNumber
+		}
+		user.PhoneNumber = &phoneNumber
+	}
+
 	var companyID *int
 	newlyCreatedCompany, err := a.companyRepository.CreateCompany(ctx, companyName)
 	if err != nil {
@@ -96,67 +276,356 @@ func (a *Authenticator) RegisterNewUser(ctx context.Context, user entity.User, c
 		return nil, nil, entity.UnknownError(err)
 	}
 
+	// the user can always ask for a new link, failing to send one must not fail the registration.
+	if err := a.SendEmailVerification(ctx, createdUser.ID); err != nil {
+		logger.Errorf(ctx, ops, "failed to send email verification: %v", err)
+	}
+
+	a.recordUserAction(ctx, entity.AuditActionUserRegister, createdUser, nil, map[string]any{
+		"email":       createdUser.Email,
+		"role":        createdUser.Role,
+		"companyName": companyName,
+	})
+
 	return createdUser, newlyCreatedCompany, nil
 }
 
 // GenerateAccessToken generates a JWT access token for the given user.
-// This function takes a user entity and uses the JWT generator to create a signed access token.
+// This function takes a user entity and uses the JWT generator to create a signed access token,
+// alongside a refresh token starting a new token family. A longer-lived refresh token is issued when `remember` is true.
+// The token family starts a new session of the user, recording the device and IP address of the request.
 // If the token generation fails, it logs the error and returns a structured application error.
-func (a *Authenticator) GenerateAccessToken(ctx context.Context, userID int, companyID int, userEmail string, userRole entity.UserRole) (authResponse *entity.AuthResponse, err error) {
+func (a *Authenticator) GenerateAccessToken(ctx context.Context, userID int, companyID int, userEmail string, userRole entity.UserRole, remember bool) (authResponse *entity.AuthResponse, err error) {
+	return a.generateAccessToken(ctx, userID, companyID, userEmail, userRole, remember, false)
+}
+
+// SignInWithSSO signs in a user authenticated by the single sign-on of a company, with their role in that company.
+// The session they start is locked to that company: it can not switch to the other companies of the user.
+// Users with two-factor authentication enabled are asked for their code first, as with a password sign in.
+func (a *Authenticator) SignInWithSSO(ctx context.Context, user *entity.User, companyID int) (authResponse *entity.AuthResponse, err error) {
+	if user.IsTwoFactorEnabled() {
+		return a.createTwoFactorChallenge(ctx, user, false, &companyID)
+	}
+
+	return a.generateAccessToken(ctx, user.ID, companyID, user.Email, user.Role, false, true)
+}
+
+func (a *Authenticator) generateAccessToken(ctx context.Context, userID int, companyID int, userEmail string, userRole entity.UserRole, remember, companyLocked bool) (authResponse *entity.AuthResponse, err error) {
 	const ops = "Authenticator.GenerateAccessToken"
 
+	familyID, err := pkg.GenerateRandomString(32)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate refresh token family: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	session, err := a.startSession(ctx, userID, companyID, familyID, companyLocked)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to start session: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
 	// Generate an access token using the JWT generator
-	authResponse, err = a.jwtGenerator.CreateAccessToken(userID, companyID, userEmail, userRole)
+	authResponse, err = a.jwtGenerator.CreateAccessToken(userID, companyID, userEmail, userRole, session.ID)
 	if err != nil {
 		logger.Errorf(ctx, ops, "failed to generate user access token: %v", err)
 		return nil, entity.UnknownError(err)
 	}
 
+	if err := a.issueRefreshToken(ctx, authResponse, userID, companyID, familyID, remember); err != nil {
+		logger.Errorf(ctx, ops, "failed to issue refresh token: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
 	return authResponse, nil
 }
 
+// RefreshAccessToken exchanges a valid refresh token for a new access token.
+// The refresh token is rotated: the presented token is revoked and a new one from the same family is issued.
+// Presenting an already rotated token is treated as a token theft and revokes the whole family.
+func (a *Authenticator) RefreshAccessToken(ctx context.Context, refreshToken string) (authResponse *entity.AuthResponse, err error) {
+	const ops = "Authenticator.RefreshAccessToken"
+
+	if refreshToken == "" {
+		return nil, entity.ErrInvalidRefreshToken
+	}
+
+	storedToken, err := a.refreshTokenRepository.FindByHash(ctx, pkg.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrInvalidRefreshToken
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve refresh token: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if storedToken.IsRevoked() {
+		logger.Warn(ctx, ops, "revoked refresh token reused, revoking family of user %d", storedToken.UserID)
+		if err := a.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, storedToken.FamilyID); err != nil {
+			return nil, entity.UnknownError(err)
+		}
+		return nil, entity.ErrInvalidRefreshToken
+	}
+
+	if storedToken.IsExpired() {
+		return nil, entity.ErrRefreshTokenIsExpired
+	}
+
+	rotated, err := a.refreshTokenRepository.RevokeRefreshToken(ctx, storedToken.ID)
+	if err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	// another request rotated this token in the meantime.
+	if !rotated {
+		if err := a.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, storedToken.FamilyID); err != nil {
+			return nil, entity.UnknownError(err)
+		}
+		return nil, entity.ErrInvalidRefreshToken
+	}
+
+	user, err := a.userRepository.GetUserByID(ctx, storedToken.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrInvalidRefreshToken
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	// tokens issued before memberships existed are kept in the current company of the user.
+	companyID := user.GetCompanyID()
+	if storedToken.CompanyID != nil {
+		companyID = pointer.GetInt(storedToken.CompanyID)
+	}
+
+	role := user.Role
+	if companyID != 0 {
+		membership, err := a.userRepository.FindCompanyMembership(ctx, user.ID, companyID)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				logger.Errorf(ctx, ops, "failed to retrieve company membership: %v", err)
+				return nil, entity.UnknownError(err)
+			}
+
+			// the user was removed from the company the session was started for.
+			if err := a.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, storedToken.FamilyID); err != nil {
+				return nil, entity.UnknownError(err)
+			}
+			return nil, entity.ErrInvalidRefreshToken
+		}
+		role = membership.Role
+	}
+
+	session, err := a.sessionRepository.FindSessionByFamilyID(ctx, storedToken.FamilyID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, ops, "failed to retrieve session: %v", err)
+			return nil, entity.UnknownError(err)
+		}
+
+		// families issued before sessions were tracked start one on their next refresh.
+		if session, err = a.startSession(ctx, user.ID, companyID, storedToken.FamilyID, false); err != nil {
+			logger.Errorf(ctx, ops, "failed to start session: %v", err)
+			return nil, entity.UnknownError(err)
+		}
+	}
+
+	authResponse, err = a.jwtGenerator.CreateAccessToken(user.ID, companyID, user.Email, role, session.ID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate user access token: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if err := a.issueRefreshToken(ctx, authResponse, user.ID, companyID, storedToken.FamilyID, storedToken.Remember); err != nil {
+		logger.Errorf(ctx, ops, "failed to issue refresh token: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return authResponse, nil
+}
+
+// SignOut revokes the whole family of the given refresh token,
+// so neither the token nor any token rotated from it can be used anymore.
+func (a *Authenticator) SignOut(ctx context.Context, refreshToken string) (err error) {
+	const ops = "Authenticator.SignOut"
+
+	if refreshToken == "" {
+		return entity.ErrInvalidRefreshToken
+	}
+
+	storedToken, err := a.refreshTokenRepository.FindByHash(ctx, pkg.HashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrInvalidRefreshToken
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve refresh token: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if err := a.refreshTokenRepository.RevokeRefreshTokenFamily(ctx, storedToken.FamilyID); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	a.recordUserActionByID(ctx, entity.AuditActionUserSignOut, storedToken.UserID, nil, nil)
+
+	return nil
+}
+
+// issueRefreshToken creates and persists a new refresh token in the given family
+// and sets it on the given authResponse. companyID is the company the access tokens are issued for, 0 for none.
+func (a *Authenticator) issueRefreshToken(ctx context.Context, authResponse *entity.AuthResponse, userID, companyID int, familyID string, remember bool) error {
+	refreshToken, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := a.config.RefreshTokenTTL
+	if remember {
+		ttl = a.config.RememberRefreshTokenTTL
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	var tokenCompanyID *int
+	if companyID != 0 {
+		tokenCompanyID = &companyID
+	}
+
+	if _, err := a.refreshTokenRepository.CreateRefreshToken(ctx, entity.RefreshToken{
+		UserID:    userID,
+		CompanyID: tokenCompanyID,
+		FamilyID:  familyID,
+		TokenHash: pkg.HashToken(refreshToken),
+		Remember:  remember,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	authResponse.RefreshToken = refreshToken
+	authResponse.RefreshTokenExpiresAt = expiresAt.Format(time.RFC3339)
+	return nil
+}
+
 // UserSignIn handles user authentication by validating the provided email and password.
-// It returns an access token upon successful authentication.
-func (a *Authenticator) UserSignIn(ctx context.Context, email, password string, remember bool) (user *entity.User, company *entity.Company, accessToken string, err error) {
+// It returns an access token and a refresh token upon successful authentication.
+// Failed attempts are counted per email and per clientIP, which are temporarily locked after too many failures.
+// When the user enabled two-factor authentication only a challenge token is returned,
+// to be completed with CompleteTwoFactorSignIn.
+func (a *Authenticator) UserSignIn(ctx context.Context, email, password, clientIP string, remember bool) (user *entity.User, company *entity.Company, authResponse *entity.AuthResponse, err error) {
 	const ops = "Authenticator.UserSignIn"
 
 	if email == "" || password == "" {
-		return nil, nil, "", entity.ErrInvalidSignInPayload
+		return nil, nil, nil, entity.ErrInvalidSignInPayload
 	}
 
 	if _, err := mail.ParseAddress(email); err != nil {
-		return nil, nil, "", entity.ErrUserInvalidEmailAddress
+		return nil, nil, nil, entity.ErrUserInvalidEmailAddress
+	}
+
+	if err := a.checkSignInThrottle(ctx, email, clientIP); err != nil {
+		return nil, nil, nil, err
 	}
 
 	user, err = a.userRepository.FindByEmail(ctx, email)
 	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
-			return nil, nil, "", entity.ErrInvalidSignInPayload
+		if errors.Is(err, sql.ErrNoRows) {
+			a.recordSignInFailure(ctx, email, clientIP)
+			return nil, nil, nil, entity.ErrInvalidSignInPayload
 		}
 
 		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
-		return nil, nil, "", entity.UnknownError(err)
+		return nil, nil, nil, entity.UnknownError(err)
 	}
 
 	if !a.passwordHasher.ComparePassword(password, user.Password) {
-		return nil, nil, "", entity.ErrInvalidSignInPayload
+		a.recordSignInFailure(ctx, email, clientIP)
+		return nil, nil, nil, entity.ErrInvalidSignInPayload
 	}
 
+	a.rehashPasswordIfNeeded(ctx, user, password)
+
 	if user.CompanyID != nil {
 		company, err = a.companyRepository.FindByID(ctx, user.GetCompanyID())
 		if err != nil {
 			logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
-			return nil, nil, "", entity.UnknownError(err)
+			return nil, nil, nil, entity.UnknownError(err)
+		}
+	}
+
+	if user.IsTwoFactorEnabled() {
+		authResponse, err = a.createTwoFactorChallenge(ctx, user, remember, nil)
+		if err != nil {
+			return nil, nil, nil, err
 		}
+
+		return user, company, authResponse, nil
 	}
 
-	authResponse, err := a.GenerateAccessToken(ctx, user.ID, pointer.GetInt(user.CompanyID), user.Email, user.Role)
+	// with two-factor authentication the failures are only forgotten by CompleteTwoFactorSignIn.
+	a.resetSignInFailures(ctx, email)
+
+	authResponse, err = a.GenerateAccessToken(ctx, user.ID, pointer.GetInt(user.CompanyID), user.Email, user.Role, remember)
 	if err != nil {
 		logger.Errorf(ctx, ops, "failed to generate accessToken: %v", err)
-		return nil, nil, "", entity.UnknownError(err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	a.recordUserAction(ctx, entity.AuditActionUserSignIn, user, nil, map[string]any{"method": "password"})
+
+	return user, company, authResponse, nil
+}
+
+// rehashPasswordIfNeeded upgrades the password hash of the user when it was produced with an outdated
+// algorithm or weaker parameters, which is only possible while the plain-text password is known.
+// Failing to upgrade the hash must not fail the sign in, so errors are only logged.
+func (a *Authenticator) rehashPasswordIfNeeded(ctx context.Context, user *entity.User, password string) {
+	const ops = "Authenticator.rehashPasswordIfNeeded"
+
+	if !a.passwordHasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := a.passwordHasher.HashPassword(password)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to hash plain password: %v", err)
+		return
+	}
+
+	if _, err := a.userRepository.RehashPassword(ctx, user.ID, user.Password, hashedPassword); err != nil {
+		logger.Errorf(ctx, ops, "failed to store rehashed password: %v", err)
+		return
+	}
+
+	user.Password = hashedPassword
+}
+
+// recordUserAction records an action a user performed on their own account.
+// The user is the actor, the action is recorded for their company.
+func (a *Authenticator) recordUserAction(ctx context.Context, action entity.AuditAction, user *entity.User, before, after any) {
+	a.auditRecorder.Record(ctx, entity.AuditLog{
+		CompanyID:  user.CompanyID,
+		ActorID:    pointer.ToInt(user.ID),
+		Action:     action,
+		TargetType: entity.AuditTargetUser,
+		TargetID:   strconv.Itoa(user.ID),
+	}, before, after)
+}
+
+// recordUserActionByID behaves like recordUserAction for actions only knowing the ID of the user.
+func (a *Authenticator) recordUserActionByID(ctx context.Context, action entity.AuditAction, userID int, before, after any) {
+	const ops = "Authenticator.recordUserActionByID"
+
+	user, err := a.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		user = &entity.User{ID: userID}
 	}
 
-	return user, company, authResponse.AccessToken, nil
+	a.recordUserAction(ctx, action, user, before, after)
 }
 
 // GetUserByID ...
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// defaultImpersonationTTL is used when the configured impersonation token duration is not set.
+const defaultImpersonationTTL = 30 * time.Minute
+
+// BackOfficeCompanyRepository defines the company-related database operations needed by the platform operators.
+type BackOfficeCompanyRepository interface {
+	FindByID(ctx context.Context, ID int) (*entity.Company, error)
+	ListCompanyUsage(ctx context.Context, search, status string, limit, offset int) ([]entity.CompanyUsage, int, error)
+	UpdateCompanySuspension(ctx context.Context, companyID int, suspendedAt *time.Time, reason *string) (bool, error)
+	UpdateCompanyPlan(ctx context.Context, companyID int, planCode string) (bool, error)
+}
+
+// BackOfficePlanRepository defines the plan-related database operations needed to change the plan of a company.
+type BackOfficePlanRepository interface {
+	FindPlan(ctx context.Context, code string) (*entity.Plan, error)
+}
+
+// BackOfficeUserRepository defines the user-related database operations needed to impersonate company admins.
+type BackOfficeUserRepository interface {
+	FindCompanyMemberWithRole(ctx context.Context, companyID int, role entity.UserRole, userID int) (*entity.User, error)
+}
+
+// BackOfficeEventRepository defines the event-related database operations needed to browse the events of every company.
+type BackOfficeEventRepository interface {
+	ListAllEvents(ctx context.Context, companyID int, limit, offset int) ([]entity.Event, int, error)
+}
+
+// ImpersonationTokenIssuer defines the contract for issuing the access tokens of a platform operator acting as a user.
+type ImpersonationTokenIssuer interface {
+	CreateImpersonationToken(userID int, companyID int, email string, userRole entity.UserRole, impersonatorID int, ttl time.Duration) (*entity.AuthResponse, error)
+}
+
+// BackOfficeService provides the business logic of the back office used by the platform operators
+// to oversee every company: usage statistics, plans, suspension and impersonation of company admins.
+type BackOfficeService struct {
+	companyRepository BackOfficeCompanyRepository
+	userRepository    BackOfficeUserRepository
+	eventRepository   BackOfficeEventRepository
+	planRepository    BackOfficePlanRepository
+	tokenIssuer       ImpersonationTokenIssuer
+	auditRecorder     AuditRecorder
+	impersonationTTL  time.Duration
+}
+
+// NewBackOfficeService initializes a new BackOfficeService.
+// impersonationTTL is the lifetime of the access tokens issued to impersonate a company admin.
+func NewBackOfficeService(
+	companyRepository BackOfficeCompanyRepository,
+	userRepository BackOfficeUserRepository,
+	eventRepository BackOfficeEventRepository,
+	planRepository BackOfficePlanRepository,
+	tokenIssuer ImpersonationTokenIssuer,
+	auditRecorder AuditRecorder,
+	impersonationTTL time.Duration,
+) *BackOfficeService {
+	if impersonationTTL <= 0 {
+		impersonationTTL = defaultImpersonationTTL
+	}
+
+	return &BackOfficeService{
+		companyRepository: companyRepository,
+		userRepository:    userRepository,
+		eventRepository:   eventRepository,
+		planRepository:    planRepository,
+		tokenIssuer:       tokenIssuer,
+		auditRecorder:     auditRecorder,
+		impersonationTTL:  impersonationTTL,
+	}
+}
+
+// ListCompanies retrieves a paginated list of the companies whose name contains search, newest first,
+// with their usage statistics. status is either "active", "suspended" or empty for both.
+func (s *BackOfficeService) ListCompanies(ctx context.Context, search, status string, request entity.PaginationRequest) (response entity.PaginationResponse, err error) {
+	const ops = "BackOfficeService.ListCompanies"
+
+	offset := (request.Page - 1) * request.PerPage
+	companies, totalRecords, err := s.companyRepository.ListCompanyUsage(ctx, strings.TrimSpace(search), status, request.PerPage, offset)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to list companies: %v", err)
+		return response, entity.UnknownError(err)
+	}
+
+	return entity.PaginationResponse{
+		Records:      companies,
+		Page:         request.Page,
+		PerPage:      request.PerPage,
+		LastPage:     (totalRecords + request.PerPage - 1) / request.PerPage,
+		TotalRecords: totalRecords,
+	}, nil
+}
+
+// SuspendCompany suspends a company: its users and API keys are refused until it is reactivated.
+// Operators can not suspend the company they belong to, which would lock them out of the back office.
+func (s *BackOfficeService) SuspendCompany(ctx context.Context, operatorCompanyID, companyID int, reason string) (*entity.Company, error) {
+	const ops = "BackOfficeService.SuspendCompany"
+
+	if companyID == operatorCompanyID {
+		return nil, entity.ErrCompanySuspendSelf
+	}
+
+	company, err := s.getCompany(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	previousState := companySuspensionAuditState(*company)
+	company.SuspendedAt = pointer.ToTime(time.Now())
+	company.SuspensionReason = optionalString(&reason)
+
+	updated, err := s.companyRepository.UpdateCompanySuspension(ctx, companyID, company.SuspendedAt, company.SuspensionReason)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to suspend company: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if !updated {
+		return nil, entity.ErrCompanyNotFound
+	}
+
+	s.recordCompanyAction(ctx, entity.AuditActionCompanySuspend, companyID, previousState, companySuspensionAuditState(*company))
+
+	return company, nil
+}
+
+// ReactivateCompany lifts the suspension of a company.
+func (s *BackOfficeService) ReactivateCompany(ctx context.Context, companyID int) (*entity.Company, error) {
+	const ops = "BackOfficeService.ReactivateCompany"
+
+	company, err := s.getCompany(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !company.IsSuspended() {
+		return nil, entity.ErrCompanyNotSuspended
+	}
+
+	previousState := companySuspensionAuditState(*company)
+	company.SuspendedAt = nil
+	company.SuspensionReason = nil
+
+	updated, err := s.companyRepository.UpdateCompanySuspension(ctx, companyID, nil, nil)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to reactivate company: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if !updated {
+		return nil, entity.ErrCompanyNotFound
+	}
+
+	s.recordCompanyAction(ctx, entity.AuditActionCompanyReactivate, companyID, previousState, companySuspensionAuditState(*company))
+
+	return company, nil
+}
+
+// ChangeCompanyPlan subscribes a company to the plan identified by planCode.
+// Usage above the limits of the new plan is kept, only new events, guests and messages are refused.
+func (s *BackOfficeService) ChangeCompanyPlan(ctx context.Context, companyID int, planCode string) (*entity.Company, error) {
+	const ops = "BackOfficeService.ChangeCompanyPlan"
+
+	company, err := s.getCompany(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := s.planRepository.FindPlan(ctx, strings.TrimSpace(planCode))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrPlanNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve plan: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	previousPlanCode := company.PlanCode
+	company.PlanCode = plan.Code
+
+	updated, err := s.companyRepository.UpdateCompanyPlan(ctx, companyID, plan.Code)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update company plan: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if !updated {
+		return nil, entity.ErrCompanyNotFound
+	}
+
+	s.recordCompanyAction(ctx, entity.AuditActionCompanyPlanChange, companyID, map[string]any{"plan": previousPlanCode}, map[string]any{"plan": plan.Code})
+
+	return company, nil
+}
+
+// Impersonate issues an access token letting the operator act as an admin of the company, to investigate
+// what the admin sees. userID picks the admin, the oldest admin of the company is impersonated when it is 0.
+// No refresh token is issued, so the impersonation ends when the access token expires.
+// Every action performed with the token is recorded in the audit log with the operator as impersonator.
+func (s *BackOfficeService) Impersonate(ctx context.Context, operatorID, companyID, userID int) (user *entity.User, company *entity.Company, authResponse *entity.AuthResponse, err error) {
+	const ops = "BackOfficeService.Impersonate"
+
+	company, err = s.getCompany(ctx, companyID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	user, err = s.userRepository.FindCompanyMemberWithRole(ctx, companyID, entity.UserRoleEOOrganizer, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil, entity.ErrImpersonationTargetNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve company admin: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	authResponse, err = s.tokenIssuer.CreateImpersonationToken(user.ID, companyID, user.Email, user.Role, operatorID, s.impersonationTTL)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate impersonation token: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	s.auditRecorder.Record(ctx, entity.AuditLog{
+		CompanyID:  pointer.ToInt(companyID),
+		Action:     entity.AuditActionUserImpersonate,
+		TargetType: entity.AuditTargetUser,
+		TargetID:   strconv.Itoa(user.ID),
+	}, nil, map[string]any{"expiresAt": authResponse.ExpiresAt})
+
+	logger.Infof(ctx, ops, "operator %d impersonates user %d of company %d", operatorID, user.ID, companyID)
+
+	return user, company, authResponse, nil
+}
+
+// ListEvents retrieves a paginated list of the events of every company, newest first,
+// or of the given company when companyID is not 0.
+func (s *BackOfficeService) ListEvents(ctx context.Context, companyID int, request entity.PaginationRequest) (response entity.PaginationResponse, err error) {
+	const ops = "BackOfficeService.ListEvents"
+
+	offset := (request.Page - 1) * request.PerPage
+	events, totalRecords, err := s.eventRepository.ListAllEvents(ctx, companyID, request.PerPage, offset)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to list events: %v", err)
+		return response, entity.UnknownError(err)
+	}
+
+	return entity.PaginationResponse{
+		Records:      events,
+		Page:         request.Page,
+		PerPage:      request.PerPage,
+		LastPage:     (totalRecords + request.PerPage - 1) / request.PerPage,
+		TotalRecords: totalRecords,
+	}, nil
+}
+
+func (s *BackOfficeService) getCompany(ctx context.Context, companyID int) (*entity.Company, error) {
+	const ops = "BackOfficeService.getCompany"
+
+	company, err := s.companyRepository.FindByID(ctx, companyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrCompanyNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return company, nil
+}
+
+// recordCompanyAction records an action of an operator on a company, visible in the audit log of the company.
+func (s *BackOfficeService) recordCompanyAction(ctx context.Context, action entity.AuditAction, companyID int, before, after any) {
+	s.auditRecorder.Record(ctx, entity.AuditLog{
+		CompanyID:  &companyID,
+		Action:     action,
+		TargetType: entity.AuditTargetCompany,
+		TargetID:   strconv.Itoa(companyID),
+	}, before, after)
+}
+
+// companySuspensionAuditState returns the suspension fields of a company recorded in the audit log.
+func companySuspensionAuditState(company entity.Company) map[string]any {
+	return map[string]any{
+		"suspendedAt":      company.SuspendedAt,
+		"suspensionReason": company.SuspensionReason,
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+// companyLogoExtensions maps the image types accepted as company logo to their file extension.
+var companyLogoExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/webp": ".webp",
+}
+
+// CompanyProfileRepository defines the contract for reading and updating the profile of a company.
+type CompanyProfileRepository interface {
+	FindByID(ctx context.Context, ID int) (*entity.Company, error)
+	UpdateCompanyProfile(ctx context.Context, company entity.Company) (bool, error)
+	UpdateCompanyLogo(ctx context.Context, companyID int, logoURL *string) (bool, error)
+}
+
+// FileStorage defines the contract for storing files served to the public, such as company logos.
+type FileStorage interface {
+	Put(ctx context.Context, key, contentType string, content io.Reader) (url string, err error)
+	Delete(ctx context.Context, url string) error
+}
+
+// CompanyService provides business logic for managing the profile of a company.
+type CompanyService struct {
+	companyRepository CompanyProfileRepository
+	fileStorage       FileStorage
+	auditRecorder     AuditRecorder
+}
+
+// NewCompanyService initializes a new CompanyService.
+func NewCompanyService(companyRepository CompanyProfileRepository, fileStorage FileStorage, auditRecorder AuditRecorder) *CompanyService {
+	return &CompanyService{
+		companyRepository: companyRepository,
+		fileStorage:       fileStorage,
+		auditRecorder:     auditRecorder,
+	}
+}
+
+// GetCompany retrieves the profile of a company.
+func (s *CompanyService) GetCompany(ctx context.Context, companyID int) (*entity.Company, error) {
+	const ops = "CompanyService.GetCompany"
+
+	company, err := s.companyRepository.FindByID(ctx, companyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrCompanyNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return company, nil
+}
+
+// UpdateCompanyProfile replaces the name, contact details and description of a company.
+// Empty optional fields are cleared. The phone number has to start with its country code and is
+// stored as `+<country code><number>`, the website has to be an http or https url.
+func (s *CompanyService) UpdateCompanyProfile(ctx context.Context, companyID int, profile entity.Company) (*entity.Company, error) {
+	const ops = "CompanyService.UpdateCompanyProfile"
+
+	existingCompany, err := s.GetCompany(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	company := *existingCompany
+	company.Name = strings.TrimSpace(profile.Name)
+	company.Email = strings.TrimSpace(profile.Email)
+	company.Phone = strings.TrimSpace(profile.Phone)
+	company.Address = optionalString(profile.Address)
+	company.Website = optionalString(profile.Website)
+	company.Description = optionalString(profile.Description)
+
+	if company.Name == "" {
+		return nil, entity.ErrCompanyNameEmpty
+	}
+
+	if company.Email != "" {
+		address, err := mail.ParseAddress(company.Email)
+		if err != nil || address.Address != company.Email {
+			return nil, entity.ErrCompanyInvalidEmail
+		}
+	}
+
+	if company.Phone != "" {
+		phone, err := entity.NormalizePhoneNumber(company.Phone)
+		if err != nil {
+			return nil, entity.ErrCompanyInvalidPhone
+		}
+		company.Phone = phone
+	}
+
+	if company.Website != nil {
+		website, err := url.Parse(*company.Website)
+		if err != nil || (website.Scheme != "http" && website.Scheme != "https") || website.Host == "" {
+			return nil, entity.ErrCompanyInvalidWebsite
+		}
+	}
+
+	updated, err := s.companyRepository.UpdateCompanyProfile(ctx, company)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update company: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if !updated {
+		return nil, entity.ErrCompanyNotFound
+	}
+
+	s.recordCompanyAction(ctx, entity.AuditActionCompanyUpdate, companyID, companyAuditState(*existingCompany), companyAuditState(company))
+
+	return &company, nil
+}
+
+// UpdateCompanyLogo stores a new logo for a company and removes the previous one.
+// The logo has to be a png, jpeg or webp image of at most entity.MaxCompanyLogoSize bytes,
+// its type is detected from its content.
+func (s *CompanyService) UpdateCompanyLogo(ctx context.Context, companyID int, logo io.Reader) (*entity.Company, error) {
+	const ops = "CompanyService.UpdateCompanyLogo"
+
+	company, err := s.GetCompany(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := io.ReadAll(io.LimitReader(logo, entity.MaxCompanyLogoSize+1))
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to read logo: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if len(content) == 0 {
+		return nil, entity.ErrCompanyLogoMissing
+	}
+
+	if len(content) > entity.MaxCompanyLogoSize {
+		return nil, entity.ErrCompanyLogoTooLarge
+	}
+
+	contentType := http.DetectContentType(content)
+	extension, supported := companyLogoExtensions[contentType]
+	if !supported {
+		return nil, entity.ErrCompanyLogoInvalidType
+	}
+
+	// a new key for every upload, so caches never serve the previous logo.
+	suffix, err := pkg.GenerateRandomString(12)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate logo key: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	key := fmt.Sprintf("companies/%d/logo-%s%s", companyID, suffix, extension)
+	logoURL, err := s.fileStorage.Put(ctx, key, contentType, bytes.NewReader(content))
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to store logo: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if err := s.setCompanyLogo(ctx, company, &logoURL); err != nil {
+		if deleteErr := s.fileStorage.Delete(ctx, logoURL); deleteErr != nil {
+			logger.Errorf(ctx, ops, "failed to delete unused logo: %v", deleteErr)
+		}
+		return nil, err
+	}
+
+	return company, nil
+}
+
+// DeleteCompanyLogo removes the logo of a company.
+func (s *CompanyService) DeleteCompanyLogo(ctx context.Context, companyID int) error {
+	company, err := s.GetCompany(ctx, companyID)
+	if err != nil {
+		return err
+	}
+
+	if company.LogoURL == nil {
+		return nil
+	}
+
+	return s.setCompanyLogo(ctx, company, nil)
+}
+
+// setCompanyLogo replaces the logo of the company and deletes the previous file.
+// Failing to delete the previous file leaves an orphan file but must not fail the update, so errors are only logged.
+func (s *CompanyService) setCompanyLogo(ctx context.Context, company *entity.Company, logoURL *string) error {
+	const ops = "CompanyService.setCompanyLogo"
+
+	updated, err := s.companyRepository.UpdateCompanyLogo(ctx, company.ID, logoURL)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update company logo: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !updated {
+		return entity.ErrCompanyNotFound
+	}
+
+	previousLogoURL := company.LogoURL
+	company.LogoURL = logoURL
+
+	if previousLogoURL != nil {
+		if err := s.fileStorage.Delete(ctx, *previousLogoURL); err != nil {
+			logger.Errorf(ctx, ops, "failed to delete previous logo: %v", err)
+		}
+	}
+
+	s.recordCompanyAction(
+		ctx,
+		entity.AuditActionCompanyLogoUpdate,
+		company.ID,
+		map[string]any{"logoUrl": previousLogoURL},
+		map[string]any{"logoUrl": logoURL},
+	)
+
+	return nil
+}
+
+// recordCompanyAction records an action performed on the profile of a company.
+func (s *CompanyService) recordCompanyAction(ctx context.Context, action entity.AuditAction, companyID int, before, after any) {
+	s.auditRecorder.Record(ctx, entity.AuditLog{
+		CompanyID:  &companyID,
+		Action:     action,
+		TargetType: entity.AuditTargetCompany,
+		TargetID:   strconv.Itoa(companyID),
+	}, before, after)
+}
+
+// companyAuditState returns the fields of the profile of a company recorded in the audit log.
+func companyAuditState(company entity.Company) map[string]any {
+	return map[string]any{
+		"name":        company.Name,
+		"email":       company.Email,
+		"phone":       company.Phone,
+		"address":     company.Address,
+		"website":     company.Website,
+		"description": company.Description,
+	}
+}
+
+// optionalString trims the value and returns nil when it is empty.
+func optionalString(value *string) *string {
+	trimmed := strings.TrimSpace(pointer.GetString(value))
+	if trimmed == "" {
+		return nil
+	}
+
+	return &trimmed
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// ListCompanies retrieves every company the user belongs to, with their role in each company.
+func (a *Authenticator) ListCompanies(ctx context.Context, userID int) ([]entity.CompanyMembership, error) {
+	const ops = "Authenticator.ListCompanies"
+
+	memberships, err := a.userRepository.ListUserMemberships(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to list user memberships: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return memberships, nil
+}
+
+// SwitchCompany issues new tokens for another company the user belongs to, with their role in that company.
+// The company becomes the current company of the user, which they are signed in to next time.
+// Existing sessions are kept, each of them stays in the company it was issued for.
+// A session started with the single sign-on of a company is locked to it and can not switch.
+func (a *Authenticator) SwitchCompany(ctx context.Context, userID, sessionID, companyID int) (user *entity.User, company *entity.Company, authResponse *entity.AuthResponse, err error) {
+	const ops = "Authenticator.SwitchCompany"
+
+	if sessionID != 0 {
+		session, err := a.sessionRepository.FindUserSessionByID(ctx, userID, sessionID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil, nil, entity.ErrSessionNotFound
+			}
+
+			logger.Errorf(ctx, ops, "failed to retrieve session: %v", err)
+			return nil, nil, nil, entity.UnknownError(err)
+		}
+
+		if session.CompanyLocked {
+			return nil, nil, nil, entity.ErrCompanySwitchLocked
+		}
+	}
+
+	user, err = a.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil, entity.ErrUserNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	membership, err := a.userRepository.FindCompanyMembership(ctx, userID, companyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil, entity.ErrCompanyMembershipNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve company membership: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	switched, err := a.userRepository.SetCurrentCompany(ctx, userID, companyID)
+	if err != nil {
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	// the membership was removed in the meantime.
+	if !switched {
+		return nil, nil, nil, entity.ErrCompanyMembershipNotFound
+	}
+
+	company, err = a.companyRepository.FindByID(ctx, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	previousCompanyID := user.CompanyID
+	user.CompanyID = &membership.CompanyID
+	user.Role = membership.Role
+
+	authResponse, err = a.GenerateAccessToken(ctx, user.ID, companyID, user.Email, user.Role, false)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate accessToken: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	a.recordUserAction(ctx, entity.AuditActionUserCompanySwitch, user, map[string]any{"companyId": previousCompanyID}, map[string]any{"companyId": companyID})
+
+	return user, company, authResponse, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+// SendEmailVerification issues a single-use email verification token for the given user
+// and delivers the verification link through the configured notifier.
+// Previously issued links are invalidated.
+func (a *Authenticator) SendEmailVerification(ctx context.Context, userID int) (err error) {
+	const ops = "Authenticator.SendEmailVerification"
+
+	user, err := a.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if user.IsEmailVerified() {
+		return entity.ErrEmailAlreadyVerified
+	}
+
+	if err := a.emailVerificationRepository.InvalidateUserEmailVerificationTokens(ctx, user.ID); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	verificationToken, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate email verification token: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	expiresAt := time.Now().Add(a.config.EmailVerificationTokenTTL)
+	if _, err := a.emailVerificationRepository.CreateEmailVerificationToken(ctx, entity.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: pkg.HashToken(verificationToken),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	verificationLink := fmt.Sprintf("%s?token=%s", a.config.EmailVerificationURL, url.QueryEscape(verificationToken))
+	if err := a.notifier.Notify(ctx, entity.Notification{
+		Recipient: user.Email,
+		Subject:   "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address using the link below. The link expires at %s.\n\n%s",
+			user.GetName(),
+			expiresAt.Format(time.RFC1123),
+			verificationLink,
+		),
+	}); err != nil {
+		logger.Errorf(ctx, ops, "failed to deliver email verification link: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	return nil
+}
+
+// VerifyEmail consumes an email verification token and marks the email address of its owner as verified.
+func (a *Authenticator) VerifyEmail(ctx context.Context, verificationToken string) (err error) {
+	const ops = "Authenticator.VerifyEmail"
+
+	if verificationToken == "" {
+		return entity.ErrInvalidEmailVerificationToken
+	}
+
+	storedToken, err := a.emailVerificationRepository.FindByHash(ctx, pkg.HashToken(verificationToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrInvalidEmailVerificationToken
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve email verification token: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if storedToken.UsedAt != nil {
+		return entity.ErrInvalidEmailVerificationToken
+	}
+
+	if storedToken.IsExpired() {
+		return entity.ErrEmailVerificationTokenIsExpired
+	}
+
+	used, err := a.emailVerificationRepository.UseEmailVerificationToken(ctx, storedToken.ID)
+	if err != nil {
+		return entity.UnknownError(err)
+	}
+
+	if !used {
+		return entity.ErrInvalidEmailVerificationToken
+	}
+
+	if err := a.userRepository.MarkEmailVerified(ctx, storedToken.UserID); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	a.recordUserActionByID(ctx, entity.AuditActionUserEmailVerify, storedToken.UserID, map[string]any{"emailVerified": false}, map[string]any{"emailVerified": true})
+
+	return nil
+}
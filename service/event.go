@@ -3,28 +3,45 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mhdiiilham/gosm/entity"
 	"github.com/mhdiiilham/gosm/logger"
 )
 
+// cancellationNotificationBatchSize is the number of guests of a cancelled event retrieved and told at once.
+const cancellationNotificationBatchSize = 500
+
 // EventRepository defines the contract for event-related database operations.
 type EventRepository interface {
 	CreateEvent(ctx context.Context, event entity.Event) (createdEvent *entity.Event, err error)
-	GetEvent(ctx context.Context, tx *sql.Tx, userID, eventID int) (event *entity.Event, err error)
-	GetEvents(ctx context.Context, companyID int, limit, offset int) ([]entity.Event, int, error)
+	GetEvent(ctx context.Context, tx *sql.Tx, companyID, eventID int) (event *entity.Event, err error)
+	GetEvents(ctx context.Context, companyID int, filter entity.EventListFilter, limit, offset int) ([]entity.Event, int, error)
 	AddGuests(ctx context.Context, eventID int, guestList []entity.Guest) (numberOfSuccess int, err error)
-	GetGuests(ctx context.Context, eventID int) (response []entity.Guest, err error)
-	DeleteGuests(ctx context.Context, userID int, guestIDs []int) error
-	UpdateGuestVIPStatus(ctx context.Context, guestID int, vipStatus bool) error
+	GetGuests(ctx context.Context, companyID, eventID int, filter entity.GuestListFilter, after *entity.GuestCursor, limit int) ([]entity.Guest, error)
+	GetGuestListVersion(ctx context.Context, eventID int) (string, error)
+	DeleteGuests(ctx context.Context, companyID, eventID int, guestIDs []int, expectedVersion string) (bool, error)
 	GetGuest(ctx context.Context, barcodeID string) (guest *entity.Guest, err error)
-	UpdateEvent(ctx context.Context, event entity.Event) (err error)
+	UpdateEvent(ctx context.Context, event entity.Event, expectedVersion int) (*entity.Event, error)
+	UpdateEventStatus(ctx context.Context, event entity.Event, previousStatus entity.EventStatus, expectedVersion int) (*entity.Event, error)
+	GetEventStatus(ctx context.Context, eventID int) (entity.EventStatus, error)
+	GetGuestEventStatus(ctx context.Context, barcodeID string) (entity.EventStatus, error)
 	UpdateGuestInvitation(ctx context.Context, guest entity.Guest) (err error)
 	UpdateGuestAttendingStatus(ctx context.Context, guestID int, isAttending bool, message string) (err error)
-	DeleteEvent(ctx context.Context, eventID int) (bool, error)
-	SetGuestIsArrived(ctx context.Context, barcodeID string, isArrived bool) (err error)
+	DeleteEvent(ctx context.Context, companyID, eventID, expectedVersion int) (bool, error)
+	SetGuestIsArrived(ctx context.Context, eventID int, barcodeID string, isArrived bool) (bool, error)
 	UpdateGuest(ctx context.Context, guestID, name, phone, message string, isAttending bool) error
 	GetGuestMessages(ctx context.Context, eventID string) ([]entity.GuestMessages, error)
+	GetStaffEvents(ctx context.Context, companyID, userID int, filter entity.EventListFilter, limit, offset int) ([]entity.Event, int, error)
+	GetEventStaffList(ctx context.Context, eventID int) ([]entity.EventStaff, error)
+	AssignEventStaff(ctx context.Context, eventID, userID int, permissions []entity.Permission) (bool, error)
+	RemoveEventStaff(ctx context.Context, eventID, userID int) (bool, error)
 }
 
 // KirimWAClient defines an interface for sending WhatsApp messages.
@@ -36,68 +53,165 @@ type KirimWAClient interface {
 type EventService struct {
 	eventRepository         EventRepository
 	kirimWAClient           KirimWAClient
+	auditRecorder           AuditRecorder
+	quotaChecker            EventQuotaChecker
+	messageQuota            MessageQuotaConsumer
 	eventRepositoryRunTxFun func(ctx context.Context, fn entity.TransactionFunc) error
 }
 
 // NewEventService initializes a new EventService with a given EventRepository.
+// Every mutation is recorded with auditRecorder, new events and guests are refused when
+// quotaChecker reports they exceed the plan of the company. The WhatsApp messages sent to the guests
+// of cancelled events are counted against the plan of the company with messageQuota.
 func NewEventService(
 	eventRepository EventRepository,
 	kirimWAClient KirimWAClient,
+	auditRecorder AuditRecorder,
+	quotaChecker EventQuotaChecker,
+	messageQuota MessageQuotaConsumer,
 	eventRepositoryRunTxFun func(ctx context.Context, fn entity.TransactionFunc) error,
 ) *EventService {
 	return &EventService{
 		eventRepository:         eventRepository,
 		kirimWAClient:           kirimWAClient,
+		auditRecorder:           auditRecorder,
+		quotaChecker:            quotaChecker,
+		messageQuota:            messageQuota,
 		eventRepositoryRunTxFun: eventRepositoryRunTxFun,
 	}
 }
 
+// recordEventAction records an action performed on an event of a company.
+func (s *EventService) recordEventAction(ctx context.Context, action entity.AuditAction, companyID, eventID int, before, after any) {
+	s.auditRecorder.Record(ctx, entity.AuditLog{
+		CompanyID:  &companyID,
+		Action:     action,
+		TargetType: entity.AuditTargetEvent,
+		TargetID:   strconv.Itoa(eventID),
+	}, before, after)
+}
+
+// guestAuditFields returns the names of the fields given for a guest. Audit logs record them instead of
+// their values, so the personal data of a guest is only kept in the guest list, where it can be erased.
+func guestAuditFields(guest entity.Guest) []string {
+	fields := []string{}
+	for field, value := range map[string]string{"name": guest.Name, "email": guest.Email, "phone": guest.Phone, "message": guest.Message} {
+		if value != "" {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+
+	return fields
+}
+
+// recordGuestAction records an action changing a guest, the company is the one of the request, if any.
+func (s *EventService) recordGuestAction(ctx context.Context, action entity.AuditAction, guestID string, after any) {
+	s.auditRecorder.Record(ctx, entity.AuditLog{
+		Action:     action,
+		TargetType: entity.AuditTargetGuest,
+		TargetID:   guestID,
+	}, nil, after)
+}
+
 // CreateEvent handles the creation of a new event.
-// It generates a unique UUID for the event before persisting it.
+// Events are created as draft unless they are created as published right away.
 func (s *EventService) CreateEvent(ctx context.Context, eventRequest entity.Event) (createdEvent *entity.Event, err error) {
 	const ops = "EventService.CreateEvent"
 
+	switch eventRequest.Status {
+	case "":
+		eventRequest.Status = entity.EventStatusDraft
+	case entity.EventStatusDraft, entity.EventStatusPublished:
+	default:
+		return nil, entity.ErrEventInitialStatus
+	}
+
+	if err := s.quotaChecker.CheckEventQuota(ctx, eventRequest.Company.ID); err != nil {
+		return nil, err
+	}
+
 	createdEvent, err = s.eventRepository.CreateEvent(ctx, eventRequest)
 	if err != nil {
 		logger.Errorf(ctx, ops, "failed to create event: %v", err)
 		return nil, entity.UnknownError(err)
 	}
 
+	s.recordEventAction(ctx, entity.AuditActionEventCreate, eventRequest.Company.ID, createdEvent.ID, nil, createdEvent)
+
 	return createdEvent, nil
 }
 
-// GetEvent retrieves a specific event for a user based on the event UUID.
-// If no event is found, it returns nil without an error.
-func (s *EventService) GetEvent(ctx context.Context, userID, eventID int) (*entity.Event, error) {
+// GetEvent retrieves a specific event of a company based on the event ID.
+// Events of other companies are reported as not found.
+func (s *EventService) GetEvent(ctx context.Context, companyID, eventID int) (*entity.Event, error) {
 	const ops = "EventService.GetEvent"
 
 	var targetEvent *entity.Event
 	if err := s.eventRepositoryRunTxFun(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		event, err := s.eventRepository.GetEvent(ctx, tx, userID, eventID)
+		event, err := s.eventRepository.GetEvent(ctx, tx, companyID, eventID)
 		if err != nil {
-			logger.Errorf(ctx, ops, "failed to get event: %v", err)
 			return err
 		}
 		targetEvent = event
 		return nil
 	}); err != nil {
-		return nil, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrEventNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to get event: %v", err)
+		return nil, entity.UnknownError(err)
 	}
 
 	return targetEvent, nil
 }
 
 // GetEvents retrieves a paginated list of events for a specific company.
+// The events are filtered and sorted on the entity.EventField* keys of request.Field, see eventListFilter.
 func (s *EventService) GetEvents(ctx context.Context, companyID int, request entity.PaginationRequest) (response entity.PaginationResponse, err error) {
 	const ops = "EventService.GetEvents"
 
+	filter, err := eventListFilter(request.Field)
+	if err != nil {
+		return response, err
+	}
+
 	offset := (request.Page - 1) * request.PerPage
-	events, totalRecords, err := s.eventRepository.GetEvents(ctx, companyID, request.PerPage, offset)
+	events, totalRecords, err := s.eventRepository.GetEvents(ctx, companyID, filter, request.PerPage, offset)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to get events: %v", err)
+		return response, entity.UnknownError(err)
+	}
+
+	lastPage := (totalRecords + request.PerPage - 1) / request.PerPage
+
+	return entity.PaginationResponse{
+		Records:      events,
+		Page:         request.Page,
+		PerPage:      request.PerPage,
+		LastPage:     int(lastPage),
+		TotalRecords: totalRecords,
+	}, nil
+}
+
+// GetStaffEvents retrieves a paginated list of the events of a company a user is assigned to as staff,
+// filtered and sorted like GetEvents.
+func (s *EventService) GetStaffEvents(ctx context.Context, companyID, userID int, request entity.PaginationRequest) (response entity.PaginationResponse, err error) {
+	const ops = "EventService.GetStaffEvents"
+
+	filter, err := eventListFilter(request.Field)
 	if err != nil {
-		logger.Errorf(ctx, ops, "failed to get events")
 		return response, err
 	}
 
+	offset := (request.Page - 1) * request.PerPage
+	events, totalRecords, err := s.eventRepository.GetStaffEvents(ctx, companyID, userID, filter, request.PerPage, offset)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to get events: %v", err)
+		return response, entity.UnknownError(err)
+	}
+
 	lastPage := (totalRecords + request.PerPage - 1) / request.PerPage
 
 	return entity.PaginationResponse{
@@ -109,24 +223,282 @@ func (s *EventService) GetEvents(ctx context.Context, companyID int, request ent
 	}, nil
 }
 
-// AddGuests insert multple of guest into an event.
-func (s *EventService) AddGuests(ctx context.Context, eventID int, guestList []entity.Guest) (numberOfSuccess int, err error) {
-	return s.eventRepository.AddGuests(ctx, eventID, guestList)
+// eventListFilter builds the filter of the event list from the fields of a pagination request.
+// Text fields are strings, the period bounds are time.Time and the creator is a user ID.
+// Events are sorted on their creation date, newest first, unless another column or direction is asked for.
+func eventListFilter(fields map[string]any) (entity.EventListFilter, error) {
+	filter := entity.EventListFilter{
+		SortBy: entity.EventSortFieldCreatedAt,
+		Order:  entity.SortOrderDesc,
+	}
+
+	if search, ok := fields[entity.EventFieldSearch].(string); ok {
+		filter.Search = strings.TrimSpace(search)
+	}
+
+	if eventType, ok := fields[entity.EventFieldType].(string); ok && eventType != "" {
+		filter.Type = entity.ParseEventType(eventType)
+		if string(filter.Type) != eventType {
+			return filter, entity.ErrEventListInvalidType
+		}
+	}
+
+	if from, ok := fields[entity.EventFieldFrom].(time.Time); ok {
+		filter.From = &from
+	}
+
+	if to, ok := fields[entity.EventFieldTo].(time.Time); ok {
+		filter.To = &to
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return filter, entity.ErrEventListInvalidPeriod
+	}
+
+	if timing, ok := fields[entity.EventFieldTiming].(string); ok && timing != "" {
+		filter.Timing = entity.EventTiming(strings.ToLower(timing))
+		if !slices.Contains([]entity.EventTiming{entity.EventTimingUpcoming, entity.EventTimingOngoing, entity.EventTimingPast}, filter.Timing) {
+			return filter, entity.ErrEventListInvalidTiming
+		}
+	}
+
+	if lifecycle, ok := fields[entity.EventFieldLifecycle].(string); ok && lifecycle != "" {
+		if filter.Status, ok = entity.ParseEventStatus(strings.ToLower(lifecycle)); !ok {
+			return filter, entity.ErrEventListInvalidLifecycle
+		}
+	}
+
+	if createdBy, ok := fields[entity.EventFieldCreatedBy].(int); ok {
+		filter.CreatedBy = createdBy
+	}
+
+	if sortBy, ok := fields[entity.EventFieldSort].(string); ok && sortBy != "" {
+		filter.SortBy = entity.EventSortField(sortBy)
+		if !slices.Contains([]entity.EventSortField{
+			entity.EventSortFieldTitle,
+			entity.EventSortFieldStartDate,
+			entity.EventSortFieldEndDate,
+			entity.EventSortFieldCreatedAt,
+			entity.EventSortFieldGuestCount,
+		}, filter.SortBy) {
+			return filter, entity.ErrEventListInvalidSort
+		}
+	}
+
+	if order, ok := fields[entity.EventFieldOrder].(string); ok && order != "" {
+		filter.Order = entity.SortOrder(strings.ToLower(order))
+		if filter.Order != entity.SortOrderAsc && filter.Order != entity.SortOrderDesc {
+			return filter, entity.ErrEventListInvalidSort
+		}
+	}
+
+	return filter, nil
+}
+
+// GetEventStaff retrieves every staff member assigned to an event of a company.
+func (s *EventService) GetEventStaff(ctx context.Context, companyID, eventID int) ([]entity.EventStaff, error) {
+	const ops = "EventService.GetEventStaff"
+
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return nil, err
+	}
+
+	staffList, err := s.eventRepository.GetEventStaffList(ctx, eventID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to get event staff: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return staffList, nil
 }
 
-// DeleteGuests deletes list of selected guests.
-func (s *EventService) DeleteGuests(ctx context.Context, userID int, guestIDs []int) (err error) {
-	return s.eventRepository.DeleteGuests(ctx, userID, guestIDs)
+// AssignEventStaff assigns a member of the event's company to the event with the given permissions.
+// Assigning an already assigned member replaces their permissions.
+func (s *EventService) AssignEventStaff(ctx context.Context, companyID, eventID, userID int, permissions []entity.Permission) error {
+	const ops = "EventService.AssignEventStaff"
+
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if !slices.Contains(entity.EventPermissions, permission) {
+			return entity.ErrEventInvalidPermission
+		}
+	}
+
+	assigned, err := s.eventRepository.AssignEventStaff(ctx, eventID, userID, permissions)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to assign event staff: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !assigned {
+		return entity.ErrCompanyMemberNotFound
+	}
+
+	s.recordEventAction(ctx, entity.AuditActionEventStaffAssign, companyID, eventID, nil, map[string]any{
+		"userId":      userID,
+		"permissions": permissions,
+	})
+
+	return nil
 }
 
-// UpdateGuestVIPStatus update the guest's vip status.
-func (s *EventService) UpdateGuestVIPStatus(ctx context.Context, guestIDs int, vipStatus bool) (err error) {
-	return s.eventRepository.UpdateGuestVIPStatus(ctx, guestIDs, vipStatus)
+// RemoveEventStaff unassigns a staff member from an event of a company.
+func (s *EventService) RemoveEventStaff(ctx context.Context, companyID, eventID, userID int) error {
+	const ops = "EventService.RemoveEventStaff"
+
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return err
+	}
+
+	removed, err := s.eventRepository.RemoveEventStaff(ctx, eventID, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to remove event staff: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !removed {
+		return entity.ErrEventStaffNotFound
+	}
+
+	s.recordEventAction(ctx, entity.AuditActionEventStaffRemove, companyID, eventID, map[string]any{"userId": userID}, nil)
+
+	return nil
 }
 
-// UpdateEvent update given event.
-func (s *EventService) UpdateEvent(ctx context.Context, event entity.Event) (err error) {
-	return s.eventRepository.UpdateEvent(ctx, event)
+// AddGuests insert multple of guest into an event of a company.
+// No guest is added when the list would exceed the guests per event of the plan of the company.
+func (s *EventService) AddGuests(ctx context.Context, companyID, eventID int, guestList []entity.Guest) (numberOfSuccess int, err error) {
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return 0, err
+	}
+
+	if err := s.quotaChecker.CheckGuestQuota(ctx, eventID, len(guestList)); err != nil {
+		return 0, err
+	}
+
+	numberOfSuccess, err = s.eventRepository.AddGuests(ctx, eventID, guestList)
+	if numberOfSuccess > 0 {
+		s.recordEventAction(ctx, entity.AuditActionGuestAdd, companyID, eventID, nil, map[string]any{"numberOfGuests": numberOfSuccess})
+	}
+
+	return numberOfSuccess, err
+}
+
+// CheckGuestQuota returns entity.ErrPlanGuestQuotaExceeded when adding numberOfGuests to an event of a company
+// would exceed the plan of the company, so imports running in the background can be refused up front.
+func (s *EventService) CheckGuestQuota(ctx context.Context, companyID, eventID, numberOfGuests int) error {
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return err
+	}
+
+	return s.quotaChecker.CheckGuestQuota(ctx, eventID, numberOfGuests)
+}
+
+// RegisterGuest adds a guest answering the public invitation of an event.
+// Only published and ongoing events accept answers to their invitation.
+func (s *EventService) RegisterGuest(ctx context.Context, eventID int, guest entity.Guest) (err error) {
+	const ops = "EventService.RegisterGuest"
+
+	status, err := s.eventRepository.GetEventStatus(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrEventNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to get event status: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !status.AcceptsRSVP() {
+		return entity.ErrEventClosedForRSVP
+	}
+
+	if err := s.quotaChecker.CheckGuestQuota(ctx, eventID, 1); err != nil {
+		return err
+	}
+
+	if _, err = s.eventRepository.AddGuests(ctx, eventID, []entity.Guest{guest}); err != nil {
+		return err
+	}
+
+	s.auditRecorder.Record(ctx, entity.AuditLog{
+		Action:     entity.AuditActionGuestRegister,
+		TargetType: entity.AuditTargetEvent,
+		TargetID:   strconv.Itoa(eventID),
+	}, nil, map[string]any{"fields": guestAuditFields(guest), "isAttending": guest.IsAttending})
+
+	return nil
+}
+
+// DeleteGuests deletes guests of an event of a company, while its guest list is still at expectedVersion,
+// as returned by GetGuestListVersion. The guests are deleted whatever the version of the guest list when expectedVersion is empty.
+func (s *EventService) DeleteGuests(ctx context.Context, companyID, eventID int, guestIDs []int, expectedVersion string) (err error) {
+	const ops = "EventService.DeleteGuests"
+
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return err
+	}
+
+	matched, err := s.eventRepository.DeleteGuests(ctx, companyID, eventID, guestIDs, expectedVersion)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to delete guests: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !matched {
+		return entity.ErrGuestListVersionMismatch
+	}
+
+	s.recordEventAction(ctx, entity.AuditActionGuestDelete, companyID, eventID, map[string]any{"guestIds": guestIDs}, nil)
+
+	return nil
+}
+
+// UpdateEvent changes the supplied fields of an event of a company and returns the updated event.
+// The end date of the event must stay after its start date, whichever of them is changed.
+// The event is only changed while it is at expectedVersion, or at any version when expectedVersion is 0,
+// entity.ErrEventVersionMismatch is returned when someone else changed it in the meantime.
+func (s *EventService) UpdateEvent(ctx context.Context, companyID, eventID, expectedVersion int, update entity.EventUpdate) (*entity.Event, error) {
+	const ops = "EventService.UpdateEvent"
+
+	existingEvent, err := s.GetEvent(ctx, companyID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedVersion != 0 && existingEvent.Version != expectedVersion {
+		return nil, entity.ErrEventVersionMismatch
+	}
+
+	if update.Title != nil && strings.TrimSpace(*update.Title) == "" {
+		return nil, entity.ErrEventTitleEmpty
+	}
+
+	if update.GuestCount != nil && *update.GuestCount < 0 {
+		return nil, entity.ErrEventInvalidGuestCount
+	}
+
+	event := update.ApplyTo(*existingEvent)
+	if !event.EndDate.After(event.StartDate) {
+		return nil, entity.ErrEventInvalidSchedule
+	}
+
+	updatedEvent, err := s.eventRepository.UpdateEvent(ctx, event, expectedVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.eventChangeConflict(ctx, companyID, eventID)
+		}
+
+		logger.Errorf(ctx, ops, "failed to update event: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	s.recordEventAction(ctx, entity.AuditActionEventUpdate, companyID, eventID, existingEvent, updatedEvent)
+
+	return updatedEvent, nil
 }
 
 // SendGuestInvitation sends an invitation message to a guest for a specific event.
@@ -136,22 +508,237 @@ func (s *EventService) SendGuestInvitation(ctx context.Context, userID, eventUUI
 
 // UpdateGuestAttendingStatus update guest's attending status and message.
 func (s *EventService) UpdateGuestAttendingStatus(ctx context.Context, guestID int, isAttending bool, message string) (err error) {
-	return s.eventRepository.UpdateGuestAttendingStatus(ctx, guestID, isAttending, message)
+	if err := s.eventRepository.UpdateGuestAttendingStatus(ctx, guestID, isAttending, message); err != nil {
+		return err
+	}
+
+	s.recordGuestAction(ctx, entity.AuditActionGuestUpdate, strconv.Itoa(guestID), map[string]any{
+		"fields":      []string{"isAttending", "message"},
+		"isAttending": isAttending,
+	})
+
+	return nil
 }
 
-// DeleteEvent soft delete an event based on given event uuid.
-func (s *EventService) DeleteEvent(ctx context.Context, eventID int) (success bool, err error) {
-	return s.eventRepository.DeleteEvent(ctx, eventID)
+// DeleteEvent soft delete an event of a company based on given event id.
+// The event is only deleted while it is at expectedVersion, or at any version when expectedVersion is 0,
+// entity.ErrEventVersionMismatch is returned when someone else changed it in the meantime.
+func (s *EventService) DeleteEvent(ctx context.Context, companyID, eventID, expectedVersion int) (success bool, err error) {
+	const ops = "EventService.DeleteEvent"
+
+	existingEvent, err := s.GetEvent(ctx, companyID, eventID)
+	if err != nil {
+		return false, err
+	}
+
+	if expectedVersion != 0 && existingEvent.Version != expectedVersion {
+		return false, entity.ErrEventVersionMismatch
+	}
+
+	deleted, err := s.eventRepository.DeleteEvent(ctx, companyID, eventID, expectedVersion)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to delete event: %v", err)
+		return false, entity.UnknownError(err)
+	}
+
+	if !deleted {
+		return false, s.eventChangeConflict(ctx, companyID, eventID)
+	}
+
+	s.recordEventAction(ctx, entity.AuditActionEventDelete, companyID, eventID, existingEvent, nil)
+
+	return true, nil
 }
 
-// SetGuestIsArrived set an guest is_arrived status.
-func (s *EventService) SetGuestIsArrived(ctx context.Context, barcodeID string, isArrived bool) (err error) {
-	return s.eventRepository.SetGuestIsArrived(ctx, barcodeID, isArrived)
+// ChangeEventStatus moves an event of a company to another stage of its lifecycle and returns the updated event:
+// drafts are published, published events start, ongoing events complete, events which are not over can be cancelled,
+// and drafts, completed and cancelled events can be archived. The guests of a cancelled event are told by WhatsApp,
+// reason is added to their message when it is not empty.
+// The event is only changed while it is at expectedVersion, or at any version when expectedVersion is 0,
+// entity.ErrEventVersionMismatch is returned when someone else changed it in the meantime.
+func (s *EventService) ChangeEventStatus(ctx context.Context, companyID, eventID, expectedVersion int, status entity.EventStatus, reason string) (*entity.Event, error) {
+	const ops = "EventService.ChangeEventStatus"
+
+	existingEvent, err := s.GetEvent(ctx, companyID, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedVersion != 0 && existingEvent.Version != expectedVersion {
+		return nil, entity.ErrEventVersionMismatch
+	}
+
+	if !existingEvent.Status.CanTransitionTo(status) {
+		return nil, entity.ErrEventStatusTransition
+	}
+
+	event := *existingEvent
+	event.Status = status
+
+	updatedEvent, err := s.eventRepository.UpdateEventStatus(ctx, event, existingEvent.Status, expectedVersion)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.eventChangeConflict(ctx, companyID, eventID)
+		}
+
+		logger.Errorf(ctx, ops, "failed to update event status: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	s.recordEventAction(
+		ctx,
+		entity.AuditActionEventStatusChange,
+		companyID,
+		eventID,
+		map[string]any{"status": existingEvent.Status},
+		map[string]any{"status": updatedEvent.Status, "reason": reason},
+	)
+
+	if updatedEvent.Status == entity.EventStatusCancelled {
+		// the guests are told in the background, the request must not wait for thousands of messages.
+		go s.notifyEventCancelled(context.WithoutCancel(ctx), *updatedEvent, strings.TrimSpace(reason))
+	}
+
+	return updatedEvent, nil
 }
 
-// GetGuests ...
-func (s *EventService) GetGuests(ctx context.Context, eventID int) (guests []entity.Guest, err error) {
-	return s.eventRepository.GetGuests(ctx, eventID)
+// notifyEventCancelled sends a WhatsApp message to every guest of a cancelled event having a phone number.
+// The guests are retrieved page by page, each page of messages is counted against the plan of the company
+// before it is sent, and the remaining guests are not told once the plan does not allow more messages.
+func (s *EventService) notifyEventCancelled(ctx context.Context, event entity.Event, reason string) {
+	const ops = "EventService.notifyEventCancelled"
+
+	// it runs in the background, a panic would otherwise bring the whole server down.
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logger.Errorf(ctx, ops, "recovered from a panic while notifying the guests of event %d: %v", event.ID, recovered)
+		}
+	}()
+
+	message := fmt.Sprintf("We are sorry to let you know that %s, planned on %s, has been cancelled.", event.Title, event.StartDate.Format("2 January 2006"))
+	if reason != "" {
+		message = fmt.Sprintf("%s\n\n%s", message, reason)
+	}
+
+	var after *entity.GuestCursor
+	numberOfNotified := 0
+	for {
+		guests, err := s.eventRepository.GetGuests(ctx, event.Company.ID, event.ID, entity.GuestListFilter{}, after, cancellationNotificationBatchSize)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to get guests of event %d: %v", event.ID, err)
+			return
+		}
+
+		recipients := []entity.Guest{}
+		for _, guest := range guests {
+			if guest.Phone != "" {
+				recipients = append(recipients, guest)
+			}
+		}
+
+		if len(recipients) > 0 {
+			if err := s.messageQuota.ConsumeMessageQuota(ctx, event.Company.ID, len(recipients)); err != nil {
+				logger.Warn(ctx, ops, "stopped telling the guests of event %d about its cancellation: %v", event.ID, err)
+				return
+			}
+		}
+
+		for _, guest := range recipients {
+			if _, _, err := s.kirimWAClient.SendMessage(ctx, guest.Phone, fmt.Sprintf("Hi %s,\n\n%s", guest.Name, message)); err != nil {
+				logger.Errorf(ctx, ops, "failed to tell guest %d about the cancellation: %v", guest.ID, err)
+				continue
+			}
+			numberOfNotified++
+		}
+
+		if len(guests) < cancellationNotificationBatchSize {
+			break
+		}
+
+		last := guests[len(guests)-1]
+		after = &entity.GuestCursor{IsVIP: last.IsVIP, ID: last.ID}
+	}
+
+	logger.Infof(ctx, ops, "told %d guests of event %d about its cancellation", numberOfNotified, event.ID)
+}
+
+// SetGuestIsArrived set is_arrived status of a guest of an event of a company.
+func (s *EventService) SetGuestIsArrived(ctx context.Context, companyID, eventID int, barcodeID string, isArrived bool) (err error) {
+	const ops = "EventService.SetGuestIsArrived"
+
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return err
+	}
+
+	updated, err := s.eventRepository.SetGuestIsArrived(ctx, eventID, barcodeID, isArrived)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update guest: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !updated {
+		return entity.ErrGuestNotFound
+	}
+
+	s.auditRecorder.Record(ctx, entity.AuditLog{
+		CompanyID:  &companyID,
+		Action:     entity.AuditActionGuestCheckIn,
+		TargetType: entity.AuditTargetGuest,
+		TargetID:   barcodeID,
+	}, nil, map[string]any{"eventId": eventID, "isArrived": isArrived})
+
+	return nil
+}
+
+// GetGuestListVersion retrieves the version of the guest list of an event of a company,
+// which changes whenever one of its guests is added, changed or deleted.
+func (s *EventService) GetGuestListVersion(ctx context.Context, companyID, eventID int) (string, error) {
+	const ops = "EventService.GetGuestListVersion"
+
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return "", err
+	}
+
+	version, err := s.eventRepository.GetGuestListVersion(ctx, eventID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to get guest list version: %v", err)
+		return "", entity.UnknownError(err)
+	}
+
+	return version, nil
+}
+
+// GetGuests retrieves a page of at most limit guests of an event of a company matching the filter,
+// VIP guests first. The page starts after cursor, the NextCursor of the previous page, or at the first guest when it is empty.
+func (s *EventService) GetGuests(ctx context.Context, companyID, eventID int, filter entity.GuestListFilter, cursor string, limit int) (page entity.GuestPage, err error) {
+	const ops = "EventService.GetGuests"
+
+	after, err := decodeGuestCursor(cursor)
+	if err != nil {
+		return page, err
+	}
+
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return page, err
+	}
+
+	filter.Search = strings.TrimSpace(filter.Search)
+
+	// one more guest than asked for is retrieved to know whether another page follows.
+	guests, err := s.eventRepository.GetGuests(ctx, companyID, eventID, filter, after, limit+1)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to get guests: %v", err)
+		return page, entity.UnknownError(err)
+	}
+
+	if len(guests) > limit {
+		guests = guests[:limit]
+		page.NextCursor = encodeGuestCursor(guests[limit-1])
+	}
+
+	page.Guests = guests
+
+	return page, nil
 }
 
 // GetGuest ...
@@ -159,10 +746,80 @@ func (s *EventService) GetGuest(ctx context.Context, barcodeID string) (guest *e
 	return s.eventRepository.GetGuest(ctx, barcodeID)
 }
 
+// UpdateGuest changes the answer of a guest, identified by its barcode, to the public invitation of its event.
+// Only published and ongoing events accept answers to their invitation.
 func (s *EventService) UpdateGuest(ctx context.Context, guestID, name, phone, message string, isAttending bool) error {
-	return s.eventRepository.UpdateGuest(ctx, guestID, name, phone, message, isAttending)
+	const ops = "EventService.UpdateGuest"
+
+	status, err := s.eventRepository.GetGuestEventStatus(ctx, guestID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrGuestNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to get event status: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !status.AcceptsRSVP() {
+		return entity.ErrEventClosedForRSVP
+	}
+
+	if err := s.eventRepository.UpdateGuest(ctx, guestID, name, phone, message, isAttending); err != nil {
+		return err
+	}
+
+	s.recordGuestAction(ctx, entity.AuditActionGuestUpdate, guestID, map[string]any{
+		"fields":      []string{"name", "phone", "message", "isAttending"},
+		"isAttending": isAttending,
+	})
+
+	return nil
 }
 
 func (s *EventService) GetGuestMessages(ctx context.Context, eventID string) ([]entity.GuestMessages, error) {
 	return s.eventRepository.GetGuestMessages(ctx, eventID)
 }
+
+// eventChangeConflict returns why a change of an event conditioned on its version changed nothing:
+// entity.ErrEventNotFound when the event was deleted in the meantime, entity.ErrEventVersionMismatch otherwise.
+func (s *EventService) eventChangeConflict(ctx context.Context, companyID, eventID int) error {
+	if _, err := s.GetEvent(ctx, companyID, eventID); err != nil {
+		return err
+	}
+
+	return entity.ErrEventVersionMismatch
+}
+
+// encodeGuestCursor returns the opaque cursor of the page of a guest list following guest.
+func encodeGuestCursor(guest entity.Guest) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatBool(guest.IsVIP) + ":" + strconv.Itoa(guest.ID)))
+}
+
+// decodeGuestCursor parses a cursor returned by encodeGuestCursor, nil is returned for an empty cursor.
+func decodeGuestCursor(cursor string) (*entity.GuestCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, entity.ErrGuestListInvalidCursor
+	}
+
+	isVIP, id, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return nil, entity.ErrGuestListInvalidCursor
+	}
+
+	var after entity.GuestCursor
+	if after.IsVIP, err = strconv.ParseBool(isVIP); err != nil {
+		return nil, entity.ErrGuestListInvalidCursor
+	}
+
+	if after.ID, err = strconv.Atoi(id); err != nil {
+		return nil, entity.ErrGuestListInvalidCursor
+	}
+
+	return &after, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/mhdiiilham/gosm/entity"
+)
+
+// fakeEventRepository keeps events in memory and records the calls changing them. The methods not used
+// by the tests are left to the embedded interface and panic when called.
+type fakeEventRepository struct {
+	EventRepository
+	events  map[int]entity.Event
+	changes []string
+}
+
+func (r *fakeEventRepository) GetEvent(_ context.Context, _ *sql.Tx, companyID, eventID int) (*entity.Event, error) {
+	event, ok := r.events[eventID]
+	if !ok || event.Company.ID != companyID {
+		return nil, sql.ErrNoRows
+	}
+
+	return &event, nil
+}
+
+func (r *fakeEventRepository) AddGuests(_ context.Context, _ int, guestList []entity.Guest) (int, error) {
+	r.changes = append(r.changes, "AddGuests")
+	return len(guestList), nil
+}
+
+func (r *fakeEventRepository) GetGuests(_ context.Context, _, _ int, _ entity.GuestListFilter, _ *entity.GuestCursor, _ int) ([]entity.Guest, error) {
+	return []entity.Guest{{ID: 1, Name: "guest"}}, nil
+}
+
+func (r *fakeEventRepository) DeleteGuests(_ context.Context, _, _ int, _ []int, _ string) (bool, error) {
+	r.changes = append(r.changes, "DeleteGuests")
+	return true, nil
+}
+
+func (r *fakeEventRepository) UpdateEvent(_ context.Context, event entity.Event, _ int) (*entity.Event, error) {
+	r.changes = append(r.changes, "UpdateEvent")
+	return &event, nil
+}
+
+func (r *fakeEventRepository) DeleteEvent(_ context.Context, _, _, _ int) (bool, error) {
+	r.changes = append(r.changes, "DeleteEvent")
+	return true, nil
+}
+
+func (r *fakeEventRepository) SetGuestIsArrived(_ context.Context, _ int, _ string, _ bool) (bool, error) {
+	r.changes = append(r.changes, "SetGuestIsArrived")
+	return true, nil
+}
+
+type fakeAuditRecorder struct {
+	records []entity.AuditLog
+}
+
+func (r *fakeAuditRecorder) Record(_ context.Context, auditLog entity.AuditLog, _, _ any) {
+	r.records = append(r.records, auditLog)
+}
+
+type fakeQuota struct{}
+
+func (fakeQuota) CheckEventQuota(context.Context, int) error          { return nil }
+func (fakeQuota) CheckGuestQuota(context.Context, int, int) error     { return nil }
+func (fakeQuota) ConsumeMessageQuota(context.Context, int, int) error { return nil }
+
+func runWithoutTransaction(ctx context.Context, fn entity.TransactionFunc) error {
+	return fn(ctx, nil)
+}
+
+const (
+	ownCompanyID   = 1
+	otherCompanyID = 2
+	otherEventID   = 20
+)
+
+func newTestEventService() (*EventService, *fakeEventRepository, *fakeAuditRecorder) {
+	repository := &fakeEventRepository{events: map[int]entity.Event{
+		10:           {ID: 10, Title: "own event", Company: entity.IDName{ID: ownCompanyID}, Version: 1},
+		otherEventID: {ID: otherEventID, Title: "other event", Company: entity.IDName{ID: otherCompanyID}, Version: 1},
+	}}
+	auditRecorder := &fakeAuditRecorder{}
+
+	return NewEventService(repository, nil, auditRecorder, fakeQuota{}, fakeQuota{}, runWithoutTransaction), repository, auditRecorder
+}
+
+func TestEventServiceRefusesEventsOfOtherCompanies(t *testing.T) {
+	testCases := []struct {
+		name string
+		call func(ctx context.Context, s *EventService) error
+	}{
+		{
+			name: "GetEvent",
+			call: func(ctx context.Context, s *EventService) error {
+				_, err := s.GetEvent(ctx, ownCompanyID, otherEventID)
+				return err
+			},
+		},
+		{
+			name: "GetGuests",
+			call: func(ctx context.Context, s *EventService) error {
+				_, err := s.GetGuests(ctx, ownCompanyID, otherEventID, entity.GuestListFilter{}, "", 10)
+				return err
+			},
+		},
+		{
+			name: "AddGuests",
+			call: func(ctx context.Context, s *EventService) error {
+				_, err := s.AddGuests(ctx, ownCompanyID, otherEventID, []entity.Guest{{Name: "guest"}})
+				return err
+			},
+		},
+		{
+			name: "DeleteGuests",
+			call: func(ctx context.Context, s *EventService) error {
+				return s.DeleteGuests(ctx, ownCompanyID, otherEventID, []int{1}, "")
+			},
+		},
+		{
+			name: "SetGuestIsArrived",
+			call: func(ctx context.Context, s *EventService) error {
+				return s.SetGuestIsArrived(ctx, ownCompanyID, otherEventID, "barcode", true)
+			},
+		},
+		{
+			name: "UpdateEvent",
+			call: func(ctx context.Context, s *EventService) error {
+				title := "taken over"
+				_, err := s.UpdateEvent(ctx, ownCompanyID, otherEventID, 0, entity.EventUpdate{Title: &title})
+				return err
+			},
+		},
+		{
+			name: "DeleteEvent",
+			call: func(ctx context.Context, s *EventService) error {
+				_, err := s.DeleteEvent(ctx, ownCompanyID, otherEventID, 0)
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, repository, auditRecorder := newTestEventService()
+
+			err := tc.call(context.Background(), s)
+			if !errors.Is(err, entity.ErrEventNotFound) {
+				t.Fatalf("expected %v, got %v", entity.ErrEventNotFound, err)
+			}
+
+			if len(repository.changes) != 0 {
+				t.Errorf("expected the event of the other company to be left alone, got %v", repository.changes)
+			}
+
+			if len(auditRecorder.records) != 0 {
+				t.Errorf("expected nothing to be audited, got %d records", len(auditRecorder.records))
+			}
+		})
+	}
+}
+
+func TestEventServiceGetEventOfOwnCompany(t *testing.T) {
+	s, _, _ := newTestEventService()
+
+	event, err := s.GetEvent(context.Background(), ownCompanyID, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if event.ID != 10 {
+		t.Errorf("expected event 10, got %d", event.ID)
+	}
+}
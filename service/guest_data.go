@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+// nonDigits matches the characters of a phone number which are not digits.
+var nonDigits = regexp.MustCompile(`\D`)
+
+// GuestDataRepository defines the contract for reading and erasing the personal data of the guests of a company.
+type GuestDataRepository interface {
+	FindGuestPersonalData(ctx context.Context, companyID int, email string, phones []string) ([]entity.GuestPersonalData, error)
+	AnonymiseGuests(ctx context.Context, companyID int, email string, phones []string) ([]int, error)
+}
+
+// GuestDataService lets a company honour the requests of a person to access or erase
+// the personal data it stores about them as guest of its events.
+type GuestDataService struct {
+	guestDataRepository GuestDataRepository
+	auditRecorder       AuditRecorder
+}
+
+// NewGuestDataService initializes a new GuestDataService.
+func NewGuestDataService(guestDataRepository GuestDataRepository, auditRecorder AuditRecorder) *GuestDataService {
+	return &GuestDataService{
+		guestDataRepository: guestDataRepository,
+		auditRecorder:       auditRecorder,
+	}
+}
+
+// ExportGuestData retrieves everything stored about the person identified by contact
+// across the events of a company, deleted events included.
+// Guests match on their email address, case-insensitively, or on the digits of their phone number.
+func (s *GuestDataService) ExportGuestData(ctx context.Context, companyID int, contact entity.GuestContact) ([]entity.GuestPersonalData, error) {
+	const ops = "GuestDataService.ExportGuestData"
+
+	email, phones, err := guestContactFilter(contact)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.guestDataRepository.FindGuestPersonalData(ctx, companyID, email, phones)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve guest personal data: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return records, nil
+}
+
+// EraseGuestData anonymises every guest of the events of a company matching contact like ExportGuestData,
+// and returns the number of anonymised guests. Their name, email address, phone number and message are erased,
+// their check-in, attendance and VIP status are kept so the statistics of the events do not change.
+func (s *GuestDataService) EraseGuestData(ctx context.Context, companyID int, contact entity.GuestContact) (int, error) {
+	const ops = "GuestDataService.EraseGuestData"
+
+	email, phones, err := guestContactFilter(contact)
+	if err != nil {
+		return 0, err
+	}
+
+	guestIDs, err := s.guestDataRepository.AnonymiseGuests(ctx, companyID, email, phones)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to anonymise guests: %v", err)
+		return 0, entity.UnknownError(err)
+	}
+
+	// the audit log must not keep the erased data, only the guests which were anonymised.
+	for _, guestID := range guestIDs {
+		s.auditRecorder.Record(ctx, entity.AuditLog{
+			CompanyID:  &companyID,
+			Action:     entity.AuditActionGuestErase,
+			TargetType: entity.AuditTargetGuest,
+			TargetID:   strconv.Itoa(guestID),
+		}, nil, map[string]any{"anonymised": true})
+	}
+
+	return len(guestIDs), nil
+}
+
+// guestContactFilter returns the email address and the phone numbers, as digits only, guests are matched on.
+// A phone number is matched as given and in the format guest phone numbers are stored in.
+func guestContactFilter(contact entity.GuestContact) (email string, phones []string, err error) {
+	email = strings.TrimSpace(contact.Email)
+	phone := strings.TrimSpace(contact.Phone)
+
+	if email == "" && phone == "" {
+		return "", nil, entity.ErrGuestContactMissing
+	}
+
+	phones = []string{}
+	for _, candidate := range []string{phone, pkg.FormatPhoneToWaMe(phone)} {
+		digits := nonDigits.ReplaceAllString(candidate, "")
+		if digits != "" && !slices.Contains(phones, digits) {
+			phones = append(phones, digits)
+		}
+	}
+
+	return email, phones, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+// RequestPasswordReset issues a single-use password reset token for the user owning the given email
+// and delivers the reset link through the configured notifier.
+// Unknown emails are ignored so the endpoint cannot be used to discover registered accounts.
+func (a *Authenticator) RequestPasswordReset(ctx context.Context, email string) (err error) {
+	const ops = "Authenticator.RequestPasswordReset"
+
+	if _, err := mail.ParseAddress(email); err != nil {
+		return entity.ErrUserInvalidEmailAddress
+	}
+
+	user, err := a.userRepository.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Infof(ctx, ops, "password reset requested for unknown email")
+			return nil
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	// only the latest requested link should be usable.
+	if err := a.passwordResetRepository.InvalidateUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	resetToken, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate password reset token: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	expiresAt := time.Now().Add(a.config.PasswordResetTokenTTL)
+	if _, err := a.passwordResetRepository.CreatePasswordResetToken(ctx, entity.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: pkg.HashToken(resetToken),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	resetLink := fmt.Sprintf("%s?token=%s", a.config.PasswordResetURL, url.QueryEscape(resetToken))
+	if err := a.notifier.Notify(ctx, entity.Notification{
+		Recipient: user.Email,
+		Subject:   "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password. The link expires at %s.\n\n%s\n\nIf you did not request a password reset, you can ignore this message.",
+			user.GetName(),
+			expiresAt.Format(time.RFC1123),
+			resetLink,
+		),
+	}); err != nil {
+		logger.Errorf(ctx, ops, "failed to deliver password reset link: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	return nil
+}
+
+// ResetPassword consumes a password reset token and replaces the password of its owner.
+// Every existing session of the user is invalidated once the password is changed.
+func (a *Authenticator) ResetPassword(ctx context.Context, resetToken, newPassword string) (err error) {
+	const ops = "Authenticator.ResetPassword"
+
+	if resetToken == "" {
+		return entity.ErrInvalidPasswordResetToken
+	}
+
+	if newPassword == "" {
+		return entity.ErrUserPasswordEmpty
+	}
+
+	storedToken, err := a.passwordResetRepository.FindByHash(ctx, pkg.HashToken(resetToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrInvalidPasswordResetToken
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve password reset token: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if storedToken.UsedAt != nil {
+		return entity.ErrInvalidPasswordResetToken
+	}
+
+	if storedToken.IsExpired() {
+		return entity.ErrPasswordResetTokenIsExpired
+	}
+
+	used, err := a.passwordResetRepository.UsePasswordResetToken(ctx, storedToken.ID)
+	if err != nil {
+		return entity.UnknownError(err)
+	}
+
+	if !used {
+		return entity.ErrInvalidPasswordResetToken
+	}
+
+	hashedPassword, err := a.passwordHasher.HashPassword(newPassword)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to hash plain password: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if err := a.userRepository.UpdatePassword(ctx, storedToken.UserID, hashedPassword); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	if err := a.passwordResetRepository.InvalidateUserPasswordResetTokens(ctx, storedToken.UserID); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	if err := a.refreshTokenRepository.RevokeUserRefreshTokens(ctx, storedToken.UserID); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	a.recordUserActionByID(ctx, entity.AuditActionUserPasswordReset, storedToken.UserID, nil, nil)
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// DefaultPermissionCacheTTL is used when no permission cache TTL is configured.
+const DefaultPermissionCacheTTL = 5 * time.Minute
+
+// RolePermissionRepository defines the contract for persisting the role permissions customised by companies.
+type RolePermissionRepository interface {
+	GetCompanyRolePermissions(ctx context.Context, companyID int) (map[entity.UserRole][]entity.Permission, error)
+	SetCompanyRolePermissions(ctx context.Context, companyID int, role entity.UserRole, permissions []entity.Permission) error
+	ResetCompanyRolePermissions(ctx context.Context, companyID int, role entity.UserRole) error
+}
+
+type rolePermissionCacheEntry struct {
+	rolePermissions map[entity.UserRole][]entity.Permission
+	expiresAt       time.Time
+}
+
+// PermissionService resolves the permissions granted to each role of a company.
+// Companies start with entity.DefaultRolePermissions and may customise any role but the super admin one.
+// Resolved permissions are cached per company for cacheTTL.
+type PermissionService struct {
+	rolePermissionRepository RolePermissionRepository
+	cacheTTL                 time.Duration
+
+	mu    sync.RWMutex
+	cache map[int]rolePermissionCacheEntry
+}
+
+// NewPermissionService initializes a new PermissionService.
+func NewPermissionService(rolePermissionRepository RolePermissionRepository, cacheTTL time.Duration) *PermissionService {
+	if cacheTTL <= 0 {
+		cacheTTL = DefaultPermissionCacheTTL
+	}
+
+	return &PermissionService{
+		rolePermissionRepository: rolePermissionRepository,
+		cacheTTL:                 cacheTTL,
+		cache:                    map[int]rolePermissionCacheEntry{},
+	}
+}
+
+// GetCompanyRolePermissions returns the permissions granted to every role of a company.
+func (s *PermissionService) GetCompanyRolePermissions(ctx context.Context, companyID int) (map[entity.UserRole][]entity.Permission, error) {
+	const ops = "PermissionService.GetCompanyRolePermissions"
+
+	s.mu.RLock()
+	entry, found := s.cache[companyID]
+	s.mu.RUnlock()
+	if found && time.Now().Before(entry.expiresAt) {
+		return entry.rolePermissions, nil
+	}
+
+	customised, err := s.rolePermissionRepository.GetCompanyRolePermissions(ctx, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to get role permissions: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	rolePermissions := map[entity.UserRole][]entity.Permission{}
+	for role, permissions := range entity.DefaultRolePermissions {
+		rolePermissions[role] = permissions
+	}
+	for role, permissions := range customised {
+		if role == entity.UserRoleSuperAdmin {
+			continue
+		}
+		rolePermissions[role] = permissions
+	}
+
+	s.mu.Lock()
+	s.cache[companyID] = rolePermissionCacheEntry{rolePermissions: rolePermissions, expiresAt: time.Now().Add(s.cacheTTL)}
+	s.mu.Unlock()
+
+	return rolePermissions, nil
+}
+
+// GetRolePermissions returns the permissions granted to a role of a company.
+func (s *PermissionService) GetRolePermissions(ctx context.Context, companyID int, role entity.UserRole) ([]entity.Permission, error) {
+	rolePermissions, err := s.GetCompanyRolePermissions(ctx, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return rolePermissions[role], nil
+}
+
+// UpdateRolePermissions customises the permissions granted to a role of a company.
+// actorRole is the role of the user doing the change, who may not revoke their own permission to manage roles.
+func (s *PermissionService) UpdateRolePermissions(ctx context.Context, companyID int, actorRole, role entity.UserRole, permissions []entity.Permission) error {
+	const ops = "PermissionService.UpdateRolePermissions"
+
+	if err := validateCustomisableRole(role); err != nil {
+		return err
+	}
+
+	for _, permission := range permissions {
+		if !entity.IsValidPermission(permission) {
+			return entity.ErrInvalidPermission
+		}
+	}
+
+	if role == actorRole && !slices.Contains(permissions, entity.PermissionRoleManage) {
+		return entity.ErrPermissionLockout
+	}
+
+	if err := s.rolePermissionRepository.SetCompanyRolePermissions(ctx, companyID, role, permissions); err != nil {
+		logger.Errorf(ctx, ops, "failed to set role permissions: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	s.invalidate(companyID)
+	return nil
+}
+
+// ResetRolePermissions restores the default permissions of a role of a company.
+func (s *PermissionService) ResetRolePermissions(ctx context.Context, companyID int, actorRole, role entity.UserRole) error {
+	const ops = "PermissionService.ResetRolePermissions"
+
+	if err := validateCustomisableRole(role); err != nil {
+		return err
+	}
+
+	if role == actorRole && !slices.Contains(entity.DefaultRolePermissions[role], entity.PermissionRoleManage) {
+		return entity.ErrPermissionLockout
+	}
+
+	if err := s.rolePermissionRepository.ResetCompanyRolePermissions(ctx, companyID, role); err != nil {
+		logger.Errorf(ctx, ops, "failed to reset role permissions: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	s.invalidate(companyID)
+	return nil
+}
+
+func (s *PermissionService) invalidate(companyID int) {
+	s.mu.Lock()
+	delete(s.cache, companyID)
+	s.mu.Unlock()
+}
+
+func validateCustomisableRole(role entity.UserRole) error {
+	if _, found := entity.DefaultRolePermissions[role]; !found || role == entity.UserRoleSuperAdmin {
+		return entity.ErrRoleNotCustomisable
+	}
+
+	return nil
+}
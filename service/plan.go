@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// PlanRepository defines the contract for reading the plans of the companies and counting their usage.
+type PlanRepository interface {
+	GetCompanyPlanUsage(ctx context.Context, companyID int, periodStart time.Time) (*entity.PlanUsage, error)
+	GetEventGuestQuota(ctx context.Context, eventID int) (*entity.Plan, int, error)
+	ConsumeMessageQuota(ctx context.Context, companyID int, periodStart time.Time, count int, limit *int) (bool, error)
+}
+
+// EventQuotaChecker defines the contract for checking the plan of a company allows more events and guests.
+type EventQuotaChecker interface {
+	CheckEventQuota(ctx context.Context, companyID int) error
+	CheckGuestQuota(ctx context.Context, eventID, numberOfGuests int) error
+}
+
+// MessageQuotaConsumer defines the contract for counting the messages sent by a company against its plan.
+type MessageQuotaConsumer interface {
+	ConsumeMessageQuota(ctx context.Context, companyID, numberOfMessages int) error
+}
+
+// PlanService enforces the limits of the plans the companies subscribed to, and reports their usage.
+type PlanService struct {
+	planRepository PlanRepository
+}
+
+// NewPlanService initializes a new PlanService.
+func NewPlanService(planRepository PlanRepository) *PlanService {
+	return &PlanService{planRepository: planRepository}
+}
+
+// GetUsage retrieves the plan of a company and its current usage.
+func (s *PlanService) GetUsage(ctx context.Context, companyID int) (*entity.PlanUsage, error) {
+	const ops = "PlanService.GetUsage"
+
+	periodStart, periodEnd := entity.MessagePeriod(time.Now())
+	usage, err := s.planRepository.GetCompanyPlanUsage(ctx, companyID, periodStart)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrCompanyNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve plan usage: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	usage.PeriodStart = periodStart
+	usage.PeriodEnd = periodEnd
+
+	return usage, nil
+}
+
+// CheckEventQuota returns entity.ErrPlanEventQuotaExceeded when the plan of the company
+// does not allow one more active event.
+func (s *PlanService) CheckEventQuota(ctx context.Context, companyID int) error {
+	usage, err := s.GetUsage(ctx, companyID)
+	if err != nil {
+		return err
+	}
+
+	if !entity.WithinLimit(usage.Plan.MaxActiveEvents, usage.ActiveEvents+1) {
+		return entity.ErrPlanEventQuotaExceeded
+	}
+
+	return nil
+}
+
+// CheckGuestQuota returns entity.ErrPlanGuestQuotaExceeded when the plan of the company owning the event
+// does not allow numberOfGuests more guests for it.
+// The check is not atomic, concurrent additions may slightly exceed the limit.
+func (s *PlanService) CheckGuestQuota(ctx context.Context, eventID, numberOfGuests int) error {
+	const ops = "PlanService.CheckGuestQuota"
+
+	plan, guestCount, err := s.planRepository.GetEventGuestQuota(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrEventNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve guest quota: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !entity.WithinLimit(plan.MaxGuestsPerEvent, guestCount+numberOfGuests) {
+		return entity.ErrPlanGuestQuotaExceeded
+	}
+
+	return nil
+}
+
+// ConsumeMessageQuota counts numberOfMessages about to be sent by a company against the monthly messages of its plan.
+// It returns entity.ErrPlanMessageQuotaExceeded, without counting them, when the plan does not allow them.
+// Messages are counted before being sent, so failing to send them does not give them back.
+func (s *PlanService) ConsumeMessageQuota(ctx context.Context, companyID, numberOfMessages int) error {
+	const ops = "PlanService.ConsumeMessageQuota"
+
+	usage, err := s.GetUsage(ctx, companyID)
+	if err != nil {
+		return err
+	}
+
+	consumed, err := s.planRepository.ConsumeMessageQuota(ctx, companyID, usage.PeriodStart, numberOfMessages, usage.Plan.MaxMessagesPerMonth)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to consume message quota: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !consumed {
+		return entity.ErrPlanMessageQuotaExceeded
+	}
+
+	return nil
+}
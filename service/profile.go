@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// UpdateProfile replaces the name, phone number and job title of a user.
+// Empty optional fields are cleared. The phone number has to start with its country code and is
+// stored as `+<country code><number>`.
+func (a *Authenticator) UpdateProfile(ctx context.Context, userID int, profile entity.User) (*entity.User, error) {
+	const ops = "Authenticator.UpdateProfile"
+
+	existingUser, err := a.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrUserNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	user := *existingUser
+	user.FirstName = strings.TrimSpace(profile.FirstName)
+	user.LastName = optionalString(profile.LastName)
+	user.PhoneNumber = optionalString(profile.PhoneNumber)
+	user.JobTitle = optionalString(profile.JobTitle)
+
+	if user.FirstName == "" {
+		return nil, entity.ErrUserFirstNameEmpty
+	}
+
+	if user.PhoneNumber != nil {
+		phoneNumber, err := entity.NormalizePhoneNumber(*user.PhoneNumber)
+		if err != nil {
+			return nil, entity.ErrUserInvalidPhoneNumber
+		}
+		user.PhoneNumber = &phoneNumber
+	}
+
+	updated, err := a.userRepository.UpdateProfile(ctx, user)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to update user profile: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if !updated {
+		return nil, entity.ErrUserNotFound
+	}
+
+	a.recordUserAction(ctx, entity.AuditActionUserProfileUpdate, &user, userProfileAuditState(*existingUser), userProfileAuditState(user))
+
+	return &user, nil
+}
+
+// ChangePassword replaces the password of a user who knows their current password.
+// Every other session of the user is invalidated, the returned tokens keep the caller signed in.
+func (a *Authenticator) ChangePassword(ctx context.Context, userID int, currentPassword, newPassword string) (authResponse *entity.AuthResponse, err error) {
+	const ops = "Authenticator.ChangePassword"
+
+	if newPassword == "" {
+		return nil, entity.ErrUserPasswordEmpty
+	}
+
+	user, err := a.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrUserNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if currentPassword == "" || !a.passwordHasher.ComparePassword(currentPassword, user.Password) {
+		return nil, entity.ErrInvalidCurrentPassword
+	}
+
+	if currentPassword == newPassword {
+		return nil, entity.ErrNewPasswordSameAsCurrent
+	}
+
+	hashedPassword, err := a.passwordHasher.HashPassword(newPassword)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to hash plain password: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if err := a.userRepository.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	if err := a.passwordResetRepository.InvalidateUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	if err := a.refreshTokenRepository.RevokeUserRefreshTokens(ctx, user.ID); err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	a.recordUserAction(ctx, entity.AuditActionUserPasswordChange, user, nil, nil)
+
+	authResponse, err = a.GenerateAccessToken(ctx, user.ID, pointer.GetInt(user.CompanyID), user.Email, user.Role, false)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate accessToken: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return authResponse, nil
+}
+
+// userProfileAuditState returns the fields of the profile of a user recorded in the audit log.
+func userProfileAuditState(user entity.User) map[string]any {
+	return map[string]any{
+		"firstName":   user.FirstName,
+		"lastName":    user.LastName,
+		"phoneNumber": user.PhoneNumber,
+		"jobTitle":    user.JobTitle,
+	}
+}
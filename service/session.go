@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+// ListSessions retrieves the sessions of the user which are neither signed out nor expired, most recently seen first.
+func (a *Authenticator) ListSessions(ctx context.Context, userID int) ([]entity.Session, error) {
+	const ops = "Authenticator.ListSessions"
+
+	sessions, err := a.sessionRepository.ListActiveUserSessions(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to list user sessions: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs out a session of the user: its refresh tokens and access tokens are refused right away.
+func (a *Authenticator) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	const ops = "Authenticator.RevokeSession"
+
+	revoked, err := a.sessionRepository.RevokeUserSession(ctx, userID, sessionID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke session: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !revoked {
+		return entity.ErrSessionNotFound
+	}
+
+	a.recordUserActionByID(ctx, entity.AuditActionUserSessionRevoke, userID, nil, map[string]any{"sessionId": sessionID})
+
+	return nil
+}
+
+// RevokeOtherSessions signs out every session of the user but currentSessionID, and returns the number of revoked sessions.
+func (a *Authenticator) RevokeOtherSessions(ctx context.Context, userID, currentSessionID int) (int, error) {
+	const ops = "Authenticator.RevokeOtherSessions"
+
+	revoked, err := a.sessionRepository.RevokeOtherUserSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to revoke other sessions: %v", err)
+		return 0, entity.UnknownError(err)
+	}
+
+	if revoked > 0 {
+		a.recordUserActionByID(ctx, entity.AuditActionUserSessionRevoke, userID, nil, map[string]any{
+			"keptSessionId":   currentSessionID,
+			"revokedSessions": revoked,
+		})
+	}
+
+	return revoked, nil
+}
+
+// startSession records a new session of the user for the given refresh token family,
+// with the device and IP address of the request it was started from.
+// A company locked session can not switch to another company of the user.
+func (a *Authenticator) startSession(ctx context.Context, userID, companyID int, familyID string, companyLocked bool) (*entity.Session, error) {
+	metadata := pkg.RequestMetadataFromContext(ctx)
+
+	var sessionCompanyID *int
+	if companyID != 0 {
+		sessionCompanyID = &companyID
+	}
+
+	return a.sessionRepository.CreateSession(ctx, entity.Session{
+		UserID:        userID,
+		CompanyID:     sessionCompanyID,
+		FamilyID:      familyID,
+		Device:        pkg.DescribeDevice(metadata.UserAgent),
+		IP:            metadata.IP,
+		UserAgent:     metadata.UserAgent,
+		CompanyLocked: companyLocked,
+	})
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// SignInAttemptStore defines an interface for keeping track of failed sign in attempts.
+// GetSignInAttempt returns an attempt without failures when the key never failed.
+type SignInAttemptStore interface {
+	GetSignInAttempt(ctx context.Context, key string) (*entity.SignInAttempt, error)
+	RecordSignInFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*entity.SignInAttempt, error)
+	LockSignIn(ctx context.Context, key string, until time.Time) error
+	ResetSignInAttempts(ctx context.Context, key string) error
+}
+
+// SignInThrottleConfig holds the limits applied to failed sign in attempts.
+// Once a key reaches its maximum number of failures it is locked for BaseLockout,
+// the lockout doubles with every further failure up to MaxLockout.
+// Failures are forgotten after FailureWindow without any new failure.
+type SignInThrottleConfig struct {
+	MaxFailuresPerEmail int
+	MaxFailuresPerIP    int
+	BaseLockout         time.Duration
+	MaxLockout          time.Duration
+	FailureWindow       time.Duration
+}
+
+const (
+	// defaultMaxSignInFailuresPerEmail is used when the configured maximum failures per email is not set.
+	defaultMaxSignInFailuresPerEmail = 5
+
+	// defaultMaxSignInFailuresPerIP is used when the configured maximum failures per IP address is not set.
+	// It is higher than the per email one as several users may share the same address.
+	defaultMaxSignInFailuresPerIP = 20
+
+	// defaultSignInBaseLockout is used when the configured base lockout duration is not set.
+	defaultSignInBaseLockout = time.Minute
+
+	// defaultSignInMaxLockout is used when the configured maximum lockout duration is not set.
+	defaultSignInMaxLockout = time.Hour
+
+	// defaultSignInFailureWindow is used when the configured failure window is not set.
+	defaultSignInFailureWindow = time.Hour
+)
+
+func (c SignInThrottleConfig) withDefaults() SignInThrottleConfig {
+	if c.MaxFailuresPerEmail <= 0 {
+		c.MaxFailuresPerEmail = defaultMaxSignInFailuresPerEmail
+	}
+
+	if c.MaxFailuresPerIP <= 0 {
+		c.MaxFailuresPerIP = defaultMaxSignInFailuresPerIP
+	}
+
+	if c.BaseLockout <= 0 {
+		c.BaseLockout = defaultSignInBaseLockout
+	}
+
+	if c.MaxLockout <= 0 {
+		c.MaxLockout = defaultSignInMaxLockout
+	}
+
+	if c.FailureWindow <= 0 {
+		c.FailureWindow = defaultSignInFailureWindow
+	}
+
+	return c
+}
+
+// lockoutFor returns how long a key is locked after the given number of failures, zero when it is not locked.
+func (c SignInThrottleConfig) lockoutFor(failures, maxFailures int) time.Duration {
+	if failures < maxFailures {
+		return 0
+	}
+
+	lockout := c.BaseLockout
+	for i := maxFailures; i < failures && lockout < c.MaxLockout; i++ {
+		lockout *= 2
+	}
+
+	return min(lockout, c.MaxLockout)
+}
+
+// checkSignInThrottle returns entity.ErrSignInLocked when sign in is locked for the email or the IP address.
+func (a *Authenticator) checkSignInThrottle(ctx context.Context, email, clientIP string) error {
+	const ops = "Authenticator.checkSignInThrottle"
+
+	now := time.Now()
+	for _, key := range signInThrottleKeys(email, clientIP) {
+		attempt, err := a.signInAttemptStore.GetSignInAttempt(ctx, key)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to retrieve sign in attempt: %v", err)
+			return entity.UnknownError(err)
+		}
+
+		if attempt.IsLocked(now) {
+			return entity.ErrSignInLocked
+		}
+	}
+
+	return nil
+}
+
+// recordSignInFailure counts a failed sign in attempt against the email and the IP address,
+// and locks the ones that reached their maximum number of failures.
+// Failing to record the attempt must not change the response, so errors are only logged.
+func (a *Authenticator) recordSignInFailure(ctx context.Context, email, clientIP string) {
+	const ops = "Authenticator.recordSignInFailure"
+
+	config := a.config.SignInThrottle
+	now := time.Now()
+	for _, key := range signInThrottleKeys(email, clientIP) {
+		attempt, err := a.signInAttemptStore.RecordSignInFailure(ctx, key, now, config.FailureWindow)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to record sign in failure: %v", err)
+			continue
+		}
+
+		maxFailures := config.MaxFailuresPerEmail
+		if strings.HasPrefix(key, "ip:") {
+			maxFailures = config.MaxFailuresPerIP
+		}
+
+		lockout := config.lockoutFor(attempt.Failures, maxFailures)
+		if lockout == 0 {
+			continue
+		}
+
+		lockedUntil := now.Add(lockout)
+		if err := a.signInAttemptStore.LockSignIn(ctx, key, lockedUntil); err != nil {
+			logger.Errorf(ctx, ops, "failed to lock sign in: %v", err)
+			continue
+		}
+
+		logger.Warn(ctx, ops, "sign in locked for %s until %s after %d failed attempts", key, lockedUntil.Format(time.RFC3339), attempt.Failures)
+
+		auditLog := entity.AuditLog{
+			Action:     entity.AuditActionUserSignInLocked,
+			TargetType: entity.AuditTargetSignIn,
+			TargetID:   key,
+			IP:         clientIP,
+		}
+
+		// lockouts of an existing account are shown to its company.
+		if strings.HasPrefix(key, "email:") {
+			if user, err := a.userRepository.FindByEmail(ctx, email); err == nil {
+				auditLog.CompanyID = user.CompanyID
+			}
+		}
+
+		a.auditRecorder.Record(ctx, auditLog, nil, map[string]any{
+			"failures":    attempt.Failures,
+			"lockedUntil": lockedUntil,
+		})
+	}
+}
+
+// resetSignInFailures forgets the failed attempts of the email after a successful sign in.
+// Failures of the IP address are kept, a single valid account must not unlock an address trying many others.
+func (a *Authenticator) resetSignInFailures(ctx context.Context, email string) {
+	const ops = "Authenticator.resetSignInFailures"
+
+	if err := a.signInAttemptStore.ResetSignInAttempts(ctx, signInThrottleKeys(email, "")[0]); err != nil {
+		logger.Errorf(ctx, ops, "failed to reset sign in attempts: %v", err)
+	}
+}
+
+// signInThrottleKeys returns the keys failed sign in attempts are counted against.
+func signInThrottleKeys(email, clientIP string) []string {
+	keys := []string{fmt.Sprintf("email:%s", strings.ToLower(strings.TrimSpace(email)))}
+	if clientIP != "" {
+		keys = append(keys, fmt.Sprintf("ip:%s", clientIP))
+	}
+
+	return keys
+}
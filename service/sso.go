@@ -0,0 +1,457 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+	"github.com/mhdiiilham/gosm/thirdparty/oidc"
+)
+
+// SSORepository defines the contract for persisting single sign-on configurations, login states and identities.
+type SSORepository interface {
+	GetCompanySSOConfig(ctx context.Context, companyID int) (*entity.CompanySSOConfig, error)
+	UpsertCompanySSOConfig(ctx context.Context, config entity.CompanySSOConfig) (*entity.CompanySSOConfig, error)
+	DeleteCompanySSOConfig(ctx context.Context, companyID int) (bool, error)
+	CreateLoginState(ctx context.Context, state entity.SSOLoginState) (*entity.SSOLoginState, error)
+	FindLoginStateByHash(ctx context.Context, stateHash string) (*entity.SSOLoginState, error)
+	UseLoginState(ctx context.Context, stateID int) (bool, error)
+	FindUserIdentity(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error)
+	CreateUserIdentity(ctx context.Context, userID int, issuer, subject string) error
+}
+
+// SSOUserRepository defines the user-related database operations needed to sign users in with single sign-on.
+type SSOUserRepository interface {
+	CreateUser(ctx context.Context, newUser entity.User, companyID *int) (createdUser *entity.User, err error)
+	FindByEmail(ct context.Context, email string) (existingUser *entity.User, err error)
+	GetUserByID(ctx context.Context, userID int) (targetUser *entity.User, err error)
+	MarkEmailVerified(ctx context.Context, userID int) error
+	FindCompanyMembership(ctx context.Context, userID, companyID int) (*entity.CompanyMembership, error)
+	ListUserMemberships(ctx context.Context, userID int) ([]entity.CompanyMembership, error)
+}
+
+// OIDCProvider defines the contract for talking to OpenID Connect identity providers.
+type OIDCProvider interface {
+	AuthorizationURL(ctx context.Context, issuer string, request oidc.AuthorizationRequest) (string, error)
+	Exchange(ctx context.Context, issuer, nonce string, request oidc.TokenRequest) (*oidc.Claims, error)
+}
+
+// AccessTokenIssuer defines the contract for issuing the access and refresh tokens of a signed in user.
+type AccessTokenIssuer interface {
+	SignInWithSSO(ctx context.Context, user *entity.User, companyID int) (authResponse *entity.AuthResponse, err error)
+}
+
+// defaultSSOStateTTL is used when the configured single sign-on state duration is not set.
+const defaultSSOStateTTL = 10 * time.Minute
+
+// SSOService signs company members in with the OpenID Connect provider of their company,
+// using the authorization code flow with PKCE. Members signing in for the first time are provisioned
+// with the default role of the company, then receive the same tokens as a password sign in.
+type SSOService struct {
+	ssoRepository     SSORepository
+	userRepository    SSOUserRepository
+	companyRepository CompanyRepository
+	passwordHasher    PasswordHasher
+	oidcProvider      OIDCProvider
+	accessTokenIssuer AccessTokenIssuer
+	redirectURL       string
+	stateTTL          time.Duration
+	allowInsecure     bool
+}
+
+// NewSSOService initializes a new SSOService.
+// redirectURL is the frontend page the identity provider sends the user back to with the `code` and `state` query parameters.
+// Issuers have to be https URLs, unless allowInsecureIssuer lets development providers be served over plain http.
+func NewSSOService(
+	ssoRepository SSORepository,
+	userRepository SSOUserRepository,
+	companyRepository CompanyRepository,
+	passwordHasher PasswordHasher,
+	oidcProvider OIDCProvider,
+	accessTokenIssuer AccessTokenIssuer,
+	redirectURL string,
+	stateTTL time.Duration,
+	allowInsecureIssuer bool,
+) *SSOService {
+	if stateTTL <= 0 {
+		stateTTL = defaultSSOStateTTL
+	}
+
+	return &SSOService{
+		ssoRepository:     ssoRepository,
+		userRepository:    userRepository,
+		companyRepository: companyRepository,
+		passwordHasher:    passwordHasher,
+		oidcProvider:      oidcProvider,
+		accessTokenIssuer: accessTokenIssuer,
+		redirectURL:       redirectURL,
+		stateTTL:          stateTTL,
+		allowInsecure:     allowInsecureIssuer,
+	}
+}
+
+// GetConfig retrieves the single sign-on configuration of a company.
+func (s *SSOService) GetConfig(ctx context.Context, companyID int) (*entity.CompanySSOConfig, error) {
+	const ops = "SSOService.GetConfig"
+
+	config, err := s.ssoRepository.GetCompanySSOConfig(ctx, companyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrSSONotConfigured
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve sso config: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return config, nil
+}
+
+// UpdateConfig creates or replaces the single sign-on configuration of a company.
+// The stored client secret is kept when no new one is given.
+func (s *SSOService) UpdateConfig(ctx context.Context, companyID int, config entity.CompanySSOConfig) (*entity.CompanySSOConfig, error) {
+	const ops = "SSOService.UpdateConfig"
+
+	config.CompanyID = companyID
+	config.Issuer = strings.TrimSpace(config.Issuer)
+	config.ClientID = strings.TrimSpace(config.ClientID)
+
+	// the server fetches the discovery document of the issuer, which must not point it at internal plain http services.
+	issuerURL, err := url.Parse(config.Issuer)
+	if err != nil || (issuerURL.Scheme != "https" && !(s.allowInsecure && issuerURL.Scheme == "http")) || issuerURL.Host == "" || config.ClientID == "" {
+		return nil, entity.ErrSSOInvalidConfig
+	}
+
+	if !slices.Contains(entity.InvitableRoles, config.DefaultRole) {
+		return nil, entity.ErrSSOInvalidDefaultRole
+	}
+
+	if config.ClientSecret == "" {
+		existing, err := s.ssoRepository.GetCompanySSOConfig(ctx, companyID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf(ctx, ops, "failed to retrieve sso config: %v", err)
+			return nil, entity.UnknownError(err)
+		}
+
+		if existing != nil {
+			config.ClientSecret = existing.ClientSecret
+		}
+	}
+
+	updated, err := s.ssoRepository.UpsertCompanySSOConfig(ctx, config)
+	if err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	return updated, nil
+}
+
+// DeleteConfig removes the single sign-on configuration of a company.
+// Members provisioned by single sign-on keep their account and can reset their password.
+func (s *SSOService) DeleteConfig(ctx context.Context, companyID int) error {
+	deleted, err := s.ssoRepository.DeleteCompanySSOConfig(ctx, companyID)
+	if err != nil {
+		return entity.UnknownError(err)
+	}
+
+	if !deleted {
+		return entity.ErrSSONotConfigured
+	}
+
+	return nil
+}
+
+// StartLogin creates a login state for the company and returns the URL of its identity provider
+// the user has to be sent to.
+func (s *SSOService) StartLogin(ctx context.Context, companyID int) (string, error) {
+	const ops = "SSOService.StartLogin"
+
+	config, err := s.GetConfig(ctx, companyID)
+	if err != nil {
+		return "", err
+	}
+
+	if !config.Enabled {
+		return "", entity.ErrSSONotConfigured
+	}
+
+	state, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate sso state: %v", err)
+		return "", entity.UnknownError(err)
+	}
+
+	nonce, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate sso nonce: %v", err)
+		return "", entity.UnknownError(err)
+	}
+
+	codeVerifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate pkce code verifier: %v", err)
+		return "", entity.UnknownError(err)
+	}
+
+	authorizationURL, err := s.oidcProvider.AuthorizationURL(ctx, config.Issuer, oidc.AuthorizationRequest{
+		ClientID:      config.ClientID,
+		RedirectURI:   s.redirectURL,
+		State:         state,
+		Nonce:         nonce,
+		CodeChallenge: oidc.CodeChallenge(codeVerifier),
+	})
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to build authorization url: %v", err)
+		return "", entity.UnknownError(err)
+	}
+
+	if _, err := s.ssoRepository.CreateLoginState(ctx, entity.SSOLoginState{
+		CompanyID:    companyID,
+		StateHash:    pkg.HashToken(state),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}); err != nil {
+		return "", entity.UnknownError(err)
+	}
+
+	return authorizationURL, nil
+}
+
+// CompleteLogin exchanges the authorization code the identity provider sent back along with the state
+// for the signed in user, provisioning them in the company of the state when they sign in for the first time.
+// An existing account is only linked by its email when it belongs to this company alone.
+// The session started is locked to the company: it can not switch to the other companies of the user.
+// Users with two-factor authentication enabled get a challenge to complete instead of the tokens.
+func (s *SSOService) CompleteLogin(ctx context.Context, state, code string) (user *entity.User, company *entity.Company, authResponse *entity.AuthResponse, err error) {
+	const ops = "SSOService.CompleteLogin"
+
+	if state == "" || code == "" {
+		return nil, nil, nil, entity.ErrInvalidSSOState
+	}
+
+	loginState, err := s.ssoRepository.FindLoginStateByHash(ctx, pkg.HashToken(state))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil, entity.ErrInvalidSSOState
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve sso login state: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	if loginState.UsedAt != nil {
+		return nil, nil, nil, entity.ErrInvalidSSOState
+	}
+
+	if loginState.IsExpired() {
+		return nil, nil, nil, entity.ErrSSOStateIsExpired
+	}
+
+	used, err := s.ssoRepository.UseLoginState(ctx, loginState.ID)
+	if err != nil {
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	// another request completed this login in the meantime.
+	if !used {
+		return nil, nil, nil, entity.ErrInvalidSSOState
+	}
+
+	config, err := s.GetConfig(ctx, loginState.CompanyID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if !config.Enabled {
+		return nil, nil, nil, entity.ErrSSONotConfigured
+	}
+
+	claims, err := s.oidcProvider.Exchange(ctx, config.Issuer, loginState.Nonce, oidc.TokenRequest{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		RedirectURI:  s.redirectURL,
+		Code:         code,
+		CodeVerifier: loginState.CodeVerifier,
+	})
+	if err != nil {
+		logger.Warn(ctx, ops, "failed to exchange authorization code of company %d: %v", config.CompanyID, err)
+		return nil, nil, nil, entity.ErrSSOLoginFailed
+	}
+
+	user, err = s.resolveUser(ctx, config, claims)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	company, err = s.companyRepository.FindByID(ctx, config.CompanyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	authResponse, err = s.accessTokenIssuer.SignInWithSSO(ctx, user, config.CompanyID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return user, company, authResponse, nil
+}
+
+// resolveUser returns the user linked to the identity of the claims.
+// An identity seen for the first time is linked to the company member with the same verified email,
+// or to a new member created with the default role of the company.
+func (s *SSOService) resolveUser(ctx context.Context, config *entity.CompanySSOConfig, claims *oidc.Claims) (*entity.User, error) {
+	const ops = "SSOService.resolveUser"
+
+	identity, err := s.ssoRepository.FindUserIdentity(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		user, err := s.userRepository.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+			return nil, entity.UnknownError(err)
+		}
+
+		if err := s.useCompanyMembership(ctx, user, config.CompanyID); err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf(ctx, ops, "failed to retrieve user identity: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, entity.ErrSSOEmailNotVerified
+	}
+
+	user, err := s.userRepository.FindByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		if err := s.linkableAccount(ctx, user, config.CompanyID); err != nil {
+			return nil, err
+		}
+
+		if err := s.useCompanyMembership(ctx, user, config.CompanyID); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		user, err = s.provisionUser(ctx, config, claims)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if err := s.ssoRepository.CreateUserIdentity(ctx, user.ID, claims.Issuer, claims.Subject); err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	return user, nil
+}
+
+// linkableAccount makes sure an existing account can be linked to an identity of the company by its email.
+// Only accounts which belong to this company alone are linked: the identity provider is configured by
+// the company admins, who must not be able to sign in to an account which is also a member of other companies.
+func (s *SSOService) linkableAccount(ctx context.Context, user *entity.User, companyID int) error {
+	const ops = "SSOService.linkableAccount"
+
+	memberships, err := s.userRepository.ListUserMemberships(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to list user memberships: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	for _, membership := range memberships {
+		if membership.CompanyID != companyID {
+			return entity.ErrSSOAccountLinkRefused
+		}
+	}
+
+	return nil
+}
+
+// useCompanyMembership sets the company and the role of the user to their membership of the given company.
+// Users who do not belong to the company can not sign in with its identity provider.
+func (s *SSOService) useCompanyMembership(ctx context.Context, user *entity.User, companyID int) error {
+	const ops = "SSOService.useCompanyMembership"
+
+	membership, err := s.userRepository.FindCompanyMembership(ctx, user.ID, companyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.ErrSSOAccountConflict
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve company membership: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	user.CompanyID = &membership.CompanyID
+	user.Role = membership.Role
+
+	return nil
+}
+
+// provisionUser creates a member of the company from the claims of the identity provider.
+// The user gets an unusable random password, they can still set one with the password reset.
+func (s *SSOService) provisionUser(ctx context.Context, config *entity.CompanySSOConfig, claims *oidc.Claims) (*entity.User, error) {
+	const ops = "SSOService.provisionUser"
+
+	randomPassword, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate password: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	hashedPassword, err := s.passwordHasher.HashPassword(randomPassword)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to hash plain password: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	user := entity.User{
+		FirstName: claims.GivenName,
+		Email:     claims.Email,
+		Password:  hashedPassword,
+		Role:      config.DefaultRole,
+		CompanyID: &config.CompanyID,
+	}
+
+	if claims.FamilyName != "" {
+		user.LastName = pointer.ToString(claims.FamilyName)
+	}
+
+	if user.FirstName == "" {
+		user.FirstName = claims.Name
+	}
+
+	if user.FirstName == "" {
+		user.FirstName = strings.Split(claims.Email, "@")[0]
+	}
+
+	createdUser, err := s.userRepository.CreateUser(ctx, user, user.CompanyID)
+	if err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	if err := s.userRepository.MarkEmailVerified(ctx, createdUser.ID); err != nil {
+		return nil, entity.UnknownError(err)
+	}
+	createdUser.EmailVerifiedAt = pointer.ToTime(time.Now())
+
+	logger.Infof(ctx, ops, "provisioned user %d in company %d from %s", createdUser.ID, config.CompanyID, claims.Issuer)
+	return createdUser, nil
+}
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+// TeamUserRepository defines the user-related database operations needed to manage a company's members.
+type TeamUserRepository interface {
+	CreateUser(ctx context.Context, newUser entity.User, companyID *int) (createdUser *entity.User, err error)
+	FindByEmail(ct context.Context, email string) (existingUser *entity.User, err error)
+	GetUserByID(ctx context.Context, userID int) (targetUser *entity.User, err error)
+	CreateInvitedUser(ctx context.Context, tx *sql.Tx, newUser entity.User, companyID int, emailVerified bool) (*entity.User, error)
+	AddCompanyMember(ctx context.Context, tx *sql.Tx, companyID, userID int, role entity.UserRole) (bool, error)
+	FindCompanyMembership(ctx context.Context, userID, companyID int) (*entity.CompanyMembership, error)
+	ListCompanyMembers(ctx context.Context, companyID int) ([]entity.User, error)
+	UpdateCompanyMemberRole(ctx context.Context, companyID, userID int, role entity.UserRole) (bool, error)
+	RemoveCompanyMember(ctx context.Context, companyID, userID int) (bool, error)
+}
+
+// InvitationRepository defines an interface for company invitation related database operations.
+type InvitationRepository interface {
+	CreateInvitation(ctx context.Context, invitation entity.CompanyInvitation) (*entity.CompanyInvitation, error)
+	FindByHash(ctx context.Context, tokenHash string) (*entity.CompanyInvitation, error)
+	ListPendingInvitations(ctx context.Context, companyID int) ([]entity.CompanyInvitation, error)
+	AcceptInvitation(ctx context.Context, tx *sql.Tx, invitationID int) (bool, error)
+	RevokeInvitation(ctx context.Context, companyID, invitationID int) (bool, error)
+}
+
+// TeamAPIKeyRepository defines the API key related database operations needed to manage a company's members.
+type TeamAPIKeyRepository interface {
+	RevokeUserAPIKeys(ctx context.Context, companyID, userID int) error
+}
+
+// defaultInvitationTTL is used when the configured invitation duration is not set.
+const defaultInvitationTTL = 7 * 24 * time.Hour
+
+// TeamService provides business logic for managing the members of a company.
+type TeamService struct {
+	userRepository         TeamUserRepository
+	companyRepository      CompanyRepository
+	invitationRepository   InvitationRepository
+	refreshTokenRepository RefreshTokenRepository
+	apiKeyRepository       TeamAPIKeyRepository
+	passwordHasher         PasswordHasher
+	notifier               Notifier
+	kirimWAClient          KirimWAClient
+	messageQuota           MessageQuotaConsumer
+	invitationTTL          time.Duration
+	invitationURL          string
+	runTx                  func(ctx context.Context, fn entity.TransactionFunc) error
+}
+
+// NewTeamService initializes a new TeamService.
+// Invitations sent by WhatsApp are counted against the plan of the company with messageQuota.
+// invitationURL is the frontend page receiving the invitation token as `token` query parameter.
+// An invitation is consumed together with the user or membership it creates, within a transaction run by runTx.
+func NewTeamService(
+	userRepository TeamUserRepository,
+	companyRepository CompanyRepository,
+	invitationRepository InvitationRepository,
+	refreshTokenRepository RefreshTokenRepository,
+	apiKeyRepository TeamAPIKeyRepository,
+	passwordHasher PasswordHasher,
+	notifier Notifier,
+	kirimWAClient KirimWAClient,
+	messageQuota MessageQuotaConsumer,
+	invitationTTL time.Duration,
+	invitationURL string,
+	runTx func(ctx context.Context, fn entity.TransactionFunc) error,
+) *TeamService {
+	if invitationTTL <= 0 {
+		invitationTTL = defaultInvitationTTL
+	}
+
+	return &TeamService{
+		userRepository:         userRepository,
+		companyRepository:      companyRepository,
+		invitationRepository:   invitationRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		apiKeyRepository:       apiKeyRepository,
+		passwordHasher:         passwordHasher,
+		notifier:               notifier,
+		kirimWAClient:          kirimWAClient,
+		messageQuota:           messageQuota,
+		invitationTTL:          invitationTTL,
+		invitationURL:          invitationURL,
+		runTx:                  runTx,
+	}
+}
+
+// InviteMember creates an invitation to join the given company and sends it
+// by email through the notifier, or by WhatsApp when only a phone number is given.
+func (s *TeamService) InviteMember(ctx context.Context, companyID, invitedBy int, email, phone string, role entity.UserRole) (*entity.CompanyInvitation, error) {
+	const ops = "TeamService.InviteMember"
+
+	if !slices.Contains(entity.InvitableRoles, role) {
+		return nil, entity.ErrInvitationInvalidRole
+	}
+
+	if email == "" && phone == "" {
+		return nil, entity.ErrInvitationMissingContact
+	}
+
+	invitation := entity.CompanyInvitation{
+		CompanyID: companyID,
+		Role:      role,
+		InvitedBy: invitedBy,
+		ExpiresAt: time.Now().Add(s.invitationTTL),
+	}
+
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, entity.ErrUserInvalidEmailAddress
+		}
+		invitation.Email = &email
+	}
+
+	if phone != "" {
+		invitation.Phone = pointer.ToString(pkg.FormatPhoneToWaMe(phone))
+	}
+
+	company, err := s.companyRepository.FindByID(ctx, companyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	// WhatsApp messages count against the plan of the company, emails do not.
+	if invitation.Email == nil {
+		if err := s.messageQuota.ConsumeMessageQuota(ctx, companyID, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	invitationToken, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate invitation token: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+	invitation.TokenHash = pkg.HashToken(invitationToken)
+
+	createdInvitation, err := s.invitationRepository.CreateInvitation(ctx, invitation)
+	if err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	invitationLink := fmt.Sprintf("%s?token=%s", s.invitationURL, url.QueryEscape(invitationToken))
+	message := fmt.Sprintf(
+		"You have been invited to join %s on Pumbook as %s. Accept the invitation before %s:\n\n%s",
+		company.Name,
+		role,
+		createdInvitation.ExpiresAt.Format(time.RFC1123),
+		invitationLink,
+	)
+
+	if createdInvitation.Email != nil {
+		if err := s.notifier.Notify(ctx, entity.Notification{
+			Recipient: email,
+			Subject:   fmt.Sprintf("Join %s on Pumbook", company.Name),
+			Body:      message,
+		}); err != nil {
+			logger.Errorf(ctx, ops, "failed to deliver invitation by email: %v", err)
+			return nil, entity.UnknownError(err)
+		}
+	} else {
+		if _, _, err := s.kirimWAClient.SendMessage(ctx, pointer.Get(createdInvitation.Phone), message); err != nil {
+			logger.Errorf(ctx, ops, "failed to deliver invitation by whatsapp: %v", err)
+			return nil, entity.UnknownError(err)
+		}
+	}
+
+	return createdInvitation, nil
+}
+
+// ListInvitations retrieves the pending invitations of the given company.
+func (s *TeamService) ListInvitations(ctx context.Context, companyID int) ([]entity.CompanyInvitation, error) {
+	invitations, err := s.invitationRepository.ListPendingInvitations(ctx, companyID)
+	if err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation revokes a pending invitation of the given company.
+func (s *TeamService) RevokeInvitation(ctx context.Context, companyID, invitationID int) error {
+	revoked, err := s.invitationRepository.RevokeInvitation(ctx, companyID, invitationID)
+	if err != nil {
+		return entity.UnknownError(err)
+	}
+
+	if !revoked {
+		return entity.ErrInvitationNotFound
+	}
+
+	return nil
+}
+
+// GetInvitation retrieves a pending invitation and its company from an invitation token,
+// so the invitee can see what they are about to join.
+func (s *TeamService) GetInvitation(ctx context.Context, invitationToken string) (*entity.CompanyInvitation, *entity.Company, error) {
+	const ops = "TeamService.GetInvitation"
+
+	invitation, err := s.findPendingInvitation(ctx, invitationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	company, err := s.companyRepository.FindByID(ctx, invitation.CompanyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+		return nil, nil, entity.UnknownError(err)
+	}
+
+	return invitation, company, nil
+}
+
+// AcceptInvitation consumes an invitation and creates the invited user as a member of the inviting company
+// with the invited role. The email address is considered verified when the invitation was sent by email.
+func (s *TeamService) AcceptInvitation(ctx context.Context, invitationToken string, user entity.User) (*entity.User, *entity.Company, error) {
+	const ops = "TeamService.AcceptInvitation"
+
+	invitation, err := s.findPendingInvitation(ctx, invitationToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if invitation.Email != nil {
+		user.Email = pointer.Get(invitation.Email)
+	}
+
+	if invitation.Phone != nil {
+		user.PhoneNumber = invitation.Phone
+	}
+
+	if _, err := mail.ParseAddress(user.Email); err != nil {
+		return nil, nil, entity.ErrUserInvalidEmailAddress
+	}
+
+	if user.FirstName == "" {
+		return nil, nil, entity.ErrUserFirstNameEmpty
+	}
+
+	if user.Password == "" {
+		return nil, nil, entity.ErrUserPasswordEmpty
+	}
+
+	if _, err := s.userRepository.FindByEmail(ctx, user.Email); err == nil {
+		return nil, nil, entity.ErrUserExisted
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, nil, entity.UnknownError(err)
+	}
+
+	hashedPassword, err := s.passwordHasher.HashPassword(user.Password)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to hash plain password: %v", err)
+		return nil, nil, entity.UnknownError(err)
+	}
+
+	user.Password = hashedPassword
+	user.Role = invitation.Role
+	user.CompanyID = &invitation.CompanyID
+
+	// the invitation is only consumed together with the creation of its user.
+	var createdUser *entity.User
+	if err := s.runTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		accepted, err := s.invitationRepository.AcceptInvitation(ctx, tx, invitation.ID)
+		if err != nil {
+			return err
+		}
+
+		if !accepted {
+			return entity.ErrInvalidInvitationToken
+		}
+
+		createdUser, err = s.userRepository.CreateInvitedUser(ctx, tx, user, invitation.CompanyID, invitation.Email != nil)
+		return err
+	}); err != nil {
+		if errors.Is(err, entity.ErrInvalidInvitationToken) {
+			return nil, nil, err
+		}
+
+		logger.Errorf(ctx, ops, "failed to accept invitation: %v", err)
+		return nil, nil, entity.UnknownError(err)
+	}
+
+	if invitation.Email != nil {
+		createdUser.EmailVerifiedAt = pointer.ToTime(time.Now())
+	}
+
+	company, err := s.companyRepository.FindByID(ctx, invitation.CompanyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+		return nil, nil, entity.UnknownError(err)
+	}
+
+	return createdUser, company, nil
+}
+
+// JoinCompany consumes an invitation on behalf of a signed in user, who becomes a member of the inviting company
+// with the invited role. Invitations sent by email can only be used by the user owning that email address.
+// The user keeps their other memberships and can switch to the new company.
+func (s *TeamService) JoinCompany(ctx context.Context, invitationToken string, userID int) (*entity.Company, error) {
+	const ops = "TeamService.JoinCompany"
+
+	invitation, err := s.findPendingInvitation(ctx, invitationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrUserNotFound
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if invitation.Email != nil && !strings.EqualFold(pointer.Get(invitation.Email), user.Email) {
+		return nil, entity.ErrInvitationEmailMismatch
+	}
+
+	// checked before consuming the invitation, so it can still be used by the right person.
+	if _, err := s.userRepository.FindCompanyMembership(ctx, user.ID, invitation.CompanyID); err == nil {
+		return nil, entity.ErrCompanyMemberExisted
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf(ctx, ops, "failed to retrieve company membership: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	// the invitation is only consumed together with the membership it grants.
+	if err := s.runTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		accepted, err := s.invitationRepository.AcceptInvitation(ctx, tx, invitation.ID)
+		if err != nil {
+			return err
+		}
+
+		if !accepted {
+			return entity.ErrInvalidInvitationToken
+		}
+
+		added, err := s.userRepository.AddCompanyMember(ctx, tx, invitation.CompanyID, user.ID, invitation.Role)
+		if err != nil {
+			return err
+		}
+
+		if !added {
+			return entity.ErrCompanyMemberExisted
+		}
+
+		return nil
+	}); err != nil {
+		if errors.Is(err, entity.ErrInvalidInvitationToken) || errors.Is(err, entity.ErrCompanyMemberExisted) {
+			return nil, err
+		}
+
+		logger.Errorf(ctx, ops, "failed to accept invitation: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	company, err := s.companyRepository.FindByID(ctx, invitation.CompanyID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return company, nil
+}
+
+// ListMembers retrieves every member of the given company.
+func (s *TeamService) ListMembers(ctx context.Context, companyID int) ([]entity.User, error) {
+	members, err := s.userRepository.ListCompanyMembers(ctx, companyID)
+	if err != nil {
+		return nil, entity.UnknownError(err)
+	}
+
+	return members, nil
+}
+
+// ChangeMemberRole changes the role of a member of the given company.
+// Admins can not change their own role, so a company always keeps at least one admin.
+func (s *TeamService) ChangeMemberRole(ctx context.Context, companyID, actorID, memberID int, role entity.UserRole) error {
+	if actorID == memberID {
+		return entity.ErrCompanyMemberIsSelf
+	}
+
+	if !slices.Contains(entity.InvitableRoles, role) {
+		return entity.ErrInvitationInvalidRole
+	}
+
+	updated, err := s.userRepository.UpdateCompanyMemberRole(ctx, companyID, memberID, role)
+	if err != nil {
+		return entity.UnknownError(err)
+	}
+
+	if !updated {
+		return entity.ErrCompanyMemberNotFound
+	}
+
+	return nil
+}
+
+// RemoveMember removes a member from the given company, signs out their sessions of that company
+// and revokes the API keys they created for it.
+// The member keeps their account and their other memberships.
+func (s *TeamService) RemoveMember(ctx context.Context, companyID, actorID, memberID int) error {
+	if actorID == memberID {
+		return entity.ErrCompanyMemberIsSelf
+	}
+
+	removed, err := s.userRepository.RemoveCompanyMember(ctx, companyID, memberID)
+	if err != nil {
+		return entity.UnknownError(err)
+	}
+
+	if !removed {
+		return entity.ErrCompanyMemberNotFound
+	}
+
+	if err := s.refreshTokenRepository.RevokeUserCompanyRefreshTokens(ctx, memberID, companyID); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	if err := s.apiKeyRepository.RevokeUserAPIKeys(ctx, companyID, memberID); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	return nil
+}
+
+func (s *TeamService) findPendingInvitation(ctx context.Context, invitationToken string) (*entity.CompanyInvitation, error) {
+	const ops = "TeamService.findPendingInvitation"
+
+	if invitationToken == "" {
+		return nil, entity.ErrInvalidInvitationToken
+	}
+
+	invitation, err := s.invitationRepository.FindByHash(ctx, pkg.HashToken(invitationToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrInvalidInvitationToken
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve invitation: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil {
+		return nil, entity.ErrInvalidInvitationToken
+	}
+
+	if invitation.IsExpired() {
+		return nil, entity.ErrInvitationIsExpired
+	}
+
+	return invitation, nil
+}
+
+// SetRequireAdminTwoFactor sets whether the admins of the given company have to enable two-factor authentication.
+// Admins without two-factor are asked to enrol before they can use the API again.
+func (s *TeamService) SetRequireAdminTwoFactor(ctx context.Context, companyID int, required bool) error {
+	if err := s.companyRepository.UpdateRequireAdminTwoFactor(ctx, companyID, required); err != nil {
+		return entity.UnknownError(err)
+	}
+
+	return nil
+}
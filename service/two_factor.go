@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AlekSi/pointer"
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+	"github.com/mhdiiilham/gosm/pkg"
+)
+
+const (
+	// twoFactorRecoveryCodeCount is the number of recovery codes generated at once.
+	twoFactorRecoveryCodeCount = 10
+
+	// twoFactorMaxChallengeAttempts is the number of codes which can be tried on a sign in challenge before it is locked.
+	twoFactorMaxChallengeAttempts = 5
+)
+
+// SetupTwoFactor generates a new TOTP secret for the user and returns what is needed to register it in an authenticator app.
+// Two-factor authentication is only enabled once the user confirms a code with EnableTwoFactor.
+func (a *Authenticator) SetupTwoFactor(ctx context.Context, userID int) (*entity.TwoFactorSetup, error) {
+	const ops = "Authenticator.SetupTwoFactor"
+
+	user, err := a.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if user.IsTwoFactorEnabled() {
+		return nil, entity.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := pkg.GenerateTOTPSecret()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate totp secret: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	stored, err := a.userRepository.SetTwoFactorSecret(ctx, userID, secret)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to store totp secret: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	// two-factor was enabled by another request in the meantime.
+	if !stored {
+		return nil, entity.ErrTwoFactorAlreadyEnabled
+	}
+
+	return &entity.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: pkg.TOTPProvisioningURI(a.config.TwoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor enables two-factor authentication once the user proves their authenticator app works
+// with a valid code, and returns the recovery codes. Recovery codes are only available at this point.
+func (a *Authenticator) EnableTwoFactor(ctx context.Context, userID int, code string) ([]string, error) {
+	const ops = "Authenticator.EnableTwoFactor"
+
+	user, err := a.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if user.IsTwoFactorEnabled() {
+		return nil, entity.ErrTwoFactorAlreadyEnabled
+	}
+
+	if user.TwoFactorSecret == nil {
+		return nil, entity.ErrTwoFactorNotSetUp
+	}
+
+	if !pkg.ValidateTOTP(*user.TwoFactorSecret, code, time.Now()) {
+		return nil, entity.ErrInvalidTwoFactorCode
+	}
+
+	recoveryCodes, err := a.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate recovery codes: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	enabled, err := a.userRepository.EnableTwoFactor(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to enable two-factor: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if !enabled {
+		return nil, entity.ErrTwoFactorAlreadyEnabled
+	}
+
+	a.recordUserAction(ctx, entity.AuditActionUserTwoFactorEnable, user, map[string]any{"twoFactorEnabled": false}, map[string]any{"twoFactorEnabled": true})
+
+	return recoveryCodes, nil
+}
+
+// DisableTwoFactor disables two-factor authentication of the user after checking a TOTP or recovery code.
+// Users who are admin of any company requiring two-factor authentication may not disable it.
+func (a *Authenticator) DisableTwoFactor(ctx context.Context, userID int, code string) error {
+	const ops = "Authenticator.DisableTwoFactor"
+
+	user, err := a.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !user.IsTwoFactorEnabled() {
+		return entity.ErrTwoFactorNotEnabled
+	}
+
+	memberships, err := a.userRepository.ListUserMemberships(ctx, user.ID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to list user memberships: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	for _, membership := range memberships {
+		if !slices.Contains(entity.AdminRoles, membership.Role) {
+			continue
+		}
+
+		company, err := a.companyRepository.FindByID(ctx, membership.CompanyID)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+			return entity.UnknownError(err)
+		}
+
+		if company.RequireAdminTwoFactor {
+			return entity.ErrTwoFactorRequiredByCompany
+		}
+	}
+
+	valid, err := a.verifyTwoFactorCode(ctx, user, code)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to verify two-factor code: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if !valid {
+		return entity.ErrInvalidTwoFactorCode
+	}
+
+	if err := a.userRepository.DisableTwoFactor(ctx, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to disable two-factor: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	if err := a.twoFactorRepository.DeleteRecoveryCodes(ctx, userID); err != nil {
+		logger.Errorf(ctx, ops, "failed to delete recovery codes: %v", err)
+		return entity.UnknownError(err)
+	}
+
+	a.recordUserAction(ctx, entity.AuditActionUserTwoFactorDisable, user, map[string]any{"twoFactorEnabled": true}, map[string]any{"twoFactorEnabled": false})
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user after checking a TOTP code.
+func (a *Authenticator) RegenerateRecoveryCodes(ctx context.Context, userID int, code string) ([]string, error) {
+	const ops = "Authenticator.RegenerateRecoveryCodes"
+
+	user, err := a.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	if !user.IsTwoFactorEnabled() {
+		return nil, entity.ErrTwoFactorNotEnabled
+	}
+
+	if !pkg.ValidateTOTP(*user.TwoFactorSecret, code, time.Now()) {
+		return nil, entity.ErrInvalidTwoFactorCode
+	}
+
+	recoveryCodes, err := a.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate recovery codes: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	a.recordUserAction(ctx, entity.AuditActionUserRecoveryCodesRegenerate, user, nil, nil)
+
+	return recoveryCodes, nil
+}
+
+// CompleteTwoFactorSignIn exchanges the challenge token returned by UserSignIn or a single sign-on and a TOTP or recovery code
+// for an access token and a refresh token. A challenge can be used once and is locked after too many attempts.
+// Wrong codes are counted as failed sign in attempts of the email of the user and of clientIP, so asking for new challenges
+// does not allow more guesses, and the failures of the email are only forgotten once the second factor succeeds.
+func (a *Authenticator) CompleteTwoFactorSignIn(ctx context.Context, challengeToken, code, clientIP string) (user *entity.User, company *entity.Company, authResponse *entity.AuthResponse, err error) {
+	const ops = "Authenticator.CompleteTwoFactorSignIn"
+
+	if challengeToken == "" {
+		return nil, nil, nil, entity.ErrInvalidTwoFactorChallenge
+	}
+
+	challenge, err := a.twoFactorRepository.FindChallengeByHash(ctx, pkg.HashToken(challengeToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil, entity.ErrInvalidTwoFactorChallenge
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve challenge: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	if challenge.UsedAt != nil || challenge.Attempts >= twoFactorMaxChallengeAttempts {
+		return nil, nil, nil, entity.ErrInvalidTwoFactorChallenge
+	}
+
+	if challenge.IsExpired() {
+		return nil, nil, nil, entity.ErrTwoFactorChallengeIsExpired
+	}
+
+	user, err = a.userRepository.GetUserByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, nil, entity.ErrInvalidTwoFactorChallenge
+		}
+
+		logger.Errorf(ctx, ops, "failed to retrieve user: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	// two-factor was disabled since the challenge was issued.
+	if !user.IsTwoFactorEnabled() {
+		return nil, nil, nil, entity.ErrInvalidTwoFactorChallenge
+	}
+
+	if err := a.checkSignInThrottle(ctx, user.Email, clientIP); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the attempt is recorded before the code is checked, so concurrent requests can not try more codes than allowed.
+	claimed, err := a.twoFactorRepository.ClaimChallengeAttempt(ctx, challenge.ID, twoFactorMaxChallengeAttempts)
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to record challenge attempt: %v", err)
+		return nil, nil, nil, entity.UnknownError(err)
+	}
+
+	if !claimed {
+		return nil, nil, nil, entity.ErrInvalidTwoFactorChallenge
+	}
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		a.recordSignInFailure(ctx, user.Email, clientIP)
+		return nil, nil, nil, entity.ErrInvalidTwoFactorCode
+	}
+
+	if pkg.ValidateTOTP(pointer.GetString(user.TwoFactorSecret), code, time.Now()) {
+		used, err := a.twoFactorRepository.UseChallenge(ctx, challenge.ID)
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to use challenge: %v", err)
+			return nil, nil, nil, entity.UnknownError(err)
+		}
+
+		// another request completed this challenge in the meantime.
+		if !used {
+			return nil, nil, nil, entity.ErrInvalidTwoFactorChallenge
+		}
+	} else {
+		// the recovery code is only spent together with the challenge, never on a challenge already used.
+		used, err := a.twoFactorRepository.UseChallengeWithRecoveryCode(ctx, challenge.ID, user.ID, hashRecoveryCode(code))
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to use challenge with recovery code: %v", err)
+			return nil, nil, nil, entity.UnknownError(err)
+		}
+
+		if !used {
+			a.recordSignInFailure(ctx, user.Email, clientIP)
+			return nil, nil, nil, entity.ErrInvalidTwoFactorCode
+		}
+	}
+
+	a.resetSignInFailures(ctx, user.Email)
+
+	method := "password"
+	companyLocked := challenge.SSOCompanyID != nil
+	if companyLocked {
+		// the sign in completes into the company of the single sign-on, with the role the user has there.
+		membership, err := a.userRepository.FindCompanyMembership(ctx, user.ID, *challenge.SSOCompanyID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil, nil, entity.ErrInvalidTwoFactorChallenge
+			}
+
+			logger.Errorf(ctx, ops, "failed to retrieve company membership: %v", err)
+			return nil, nil, nil, entity.UnknownError(err)
+		}
+
+		user.CompanyID = &membership.CompanyID
+		user.Role = membership.Role
+		method = "sso"
+	}
+
+	if user.CompanyID != nil {
+		company, err = a.companyRepository.FindByID(ctx, user.GetCompanyID())
+		if err != nil {
+			logger.Errorf(ctx, ops, "failed to retrieve company: %v", err)
+			return nil, nil, nil, entity.UnknownError(err)
+		}
+	}
+
+	authResponse, err = a.generateAccessToken(ctx, user.ID, user.GetCompanyID(), user.Email, user.Role, challenge.Remember, companyLocked)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	a.recordUserAction(ctx, entity.AuditActionUserSignIn, user, nil, map[string]any{"method": method, "twoFactor": true})
+
+	return user, company, authResponse, nil
+}
+
+// createTwoFactorChallenge persists a new sign in challenge for the user
+// and returns the response asking for the two-factor code.
+// ssoCompanyID is the company of the single sign-on the challenge completes, nil for a password sign in.
+func (a *Authenticator) createTwoFactorChallenge(ctx context.Context, user *entity.User, remember bool, ssoCompanyID *int) (*entity.AuthResponse, error) {
+	const ops = "Authenticator.createTwoFactorChallenge"
+
+	challengeToken, err := pkg.GenerateOpaqueToken()
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to generate challenge token: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	expiresAt := time.Now().Add(a.config.TwoFactorChallengeTTL)
+	if _, err := a.twoFactorRepository.CreateChallenge(ctx, entity.TwoFactorChallenge{
+		UserID:       user.ID,
+		TokenHash:    pkg.HashToken(challengeToken),
+		Remember:     remember,
+		ExpiresAt:    expiresAt,
+		SSOCompanyID: ssoCompanyID,
+	}); err != nil {
+		logger.Errorf(ctx, ops, "failed to store challenge: %v", err)
+		return nil, entity.UnknownError(err)
+	}
+
+	return &entity.AuthResponse{
+		Email:              user.Email,
+		Role:               user.Role,
+		TwoFactorRequired:  true,
+		ChallengeToken:     challengeToken,
+		ChallengeExpiresAt: expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// verifyTwoFactorCode returns true when code is a valid TOTP code of the user,
+// or one of their unused recovery codes, which is then consumed.
+func (a *Authenticator) verifyTwoFactorCode(ctx context.Context, user *entity.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return false, nil
+	}
+
+	if pkg.ValidateTOTP(pointer.GetString(user.TwoFactorSecret), code, time.Now()) {
+		return true, nil
+	}
+
+	return a.twoFactorRepository.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(code))
+}
+
+// replaceRecoveryCodes generates a new set of recovery codes for the user, replacing the previous ones.
+func (a *Authenticator) replaceRecoveryCodes(ctx context.Context, userID int) ([]string, error) {
+	recoveryCodes := make([]string, 0, twoFactorRecoveryCodeCount)
+	codeHashes := make([]string, 0, twoFactorRecoveryCodeCount)
+	for range twoFactorRecoveryCodeCount {
+		random, err := pkg.GenerateRandomString(10)
+		if err != nil {
+			return nil, err
+		}
+
+		recoveryCode := strings.ToLower(random[:5] + "-" + random[5:])
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+		codeHashes = append(codeHashes, hashRecoveryCode(recoveryCode))
+	}
+
+	if err := a.twoFactorRepository.ReplaceRecoveryCodes(ctx, userID, codeHashes); err != nil {
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// hashRecoveryCode hashes a recovery code regardless of its case and dashes, so users may type it loosely.
+func hashRecoveryCode(code string) string {
+	return pkg.HashToken(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", "")))
+}
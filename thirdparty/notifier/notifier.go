@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mhdiiilham/gosm/entity"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// LogNotifier records that notifications were sent by writing their recipient and subject to the application log.
+// It is meant for local development where no mail provider is configured, the body is never logged since it holds
+// links such as password reset links, which anyone reading the logs could use.
+type LogNotifier struct{}
+
+// NewLogNotifier initializes a new LogNotifier.
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify writes the recipient and the subject of the notification to the application log.
+func (n *LogNotifier) Notify(ctx context.Context, notification entity.Notification) error {
+	logger.Infof(
+		ctx,
+		"LogNotifier.Notify",
+		"to=%s subject=%q",
+		notification.Recipient,
+		notification.Subject,
+	)
+	return nil
+}
+
+// FileNotifier delivers notifications by appending them to a file.
+// It is meant for local development and manual testing of flows such as password reset.
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier initializes a new FileNotifier writing to the given path.
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// Notify appends the notification to the configured file.
+func (n *FileNotifier) Notify(ctx context.Context, notification entity.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		logger.Errorf(ctx, "FileNotifier.Notify", "failed to open %s: %v", n.path, err)
+		return err
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(
+		file,
+		"[%s] To: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339),
+		notification.Recipient,
+		notification.Subject,
+		notification.Body,
+	); err != nil {
+		logger.Errorf(ctx, "FileNotifier.Notify", "failed to write notification: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ErrInvalidHeader is returned when the recipient or the subject of a notification would break the email headers.
+var ErrInvalidHeader = errors.New("notification recipient and subject must not contain line breaks")
+
+// SMTPNotifier delivers notifications by email through an SMTP server.
+// The connection is upgraded with STARTTLS whenever the server supports it.
+type SMTPNotifier struct {
+	address string
+	from    string
+	auth    smtp.Auth
+}
+
+// NewSMTPNotifier initializes a new SMTPNotifier sending emails from the given address through host:port.
+// The server is authenticated against with username and password when a username is given.
+func NewSMTPNotifier(host, port, username, password, from string) *SMTPNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPNotifier{
+		address: net.JoinHostPort(host, port),
+		from:    from,
+		auth:    auth,
+	}
+}
+
+// Notify sends the notification as a plain text email to its recipient.
+func (n *SMTPNotifier) Notify(ctx context.Context, notification entity.Notification) error {
+	if strings.ContainsAny(notification.Recipient+notification.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+
+	message := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		n.from,
+		notification.Recipient,
+		notification.Subject,
+		time.Now().Format(time.RFC1123Z),
+		notification.Body,
+	)
+
+	if err := smtp.SendMail(n.address, n.auth, n.from, []string{notification.Recipient}, []byte(message)); err != nil {
+		logger.Errorf(ctx, "SMTPNotifier.Notify", "failed to send notification to %s: %v", notification.Recipient, err)
+		return err
+	}
+
+	return nil
+}
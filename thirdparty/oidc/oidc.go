@@ -0,0 +1,371 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// providerCacheTTL is how long the discovery document and the signing keys of a provider are cached.
+const providerCacheTTL = time.Hour
+
+var (
+	// ErrInvalidIDToken is returned when the ID token is not signed by the provider or its claims do not match.
+	ErrInvalidIDToken = errors.New("oidc: invalid id token")
+
+	// ErrMissingIDToken is returned when the token response of the provider has no ID token.
+	ErrMissingIDToken = errors.New("oidc: token response has no id token")
+
+	// ErrInsecureURL is returned when the issuer, or an endpoint of its discovery document, is not an https URL.
+	ErrInsecureURL = errors.New("oidc: provider urls must use https")
+)
+
+// ProviderMetadata represents the part of the OpenID Connect discovery document used by the client.
+type ProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Claims represents the ID token claims used to identify the user.
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	GivenName     string
+	FamilyName    string
+}
+
+// AuthorizationRequest holds the parameters of an authorization code request with PKCE.
+type AuthorizationRequest struct {
+	ClientID      string
+	RedirectURI   string
+	State         string
+	Nonce         string
+	CodeChallenge string
+}
+
+// TokenRequest holds the parameters required to exchange an authorization code.
+type TokenRequest struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURI  string
+	Code         string
+	CodeVerifier string
+}
+
+type provider struct {
+	metadata  ProviderMetadata
+	keys      map[string]*rsa.PublicKey
+	expiresAt time.Time
+}
+
+// Client talks to OpenID Connect providers. Discovery documents and signing keys are cached per issuer.
+// Providers are only fetched over https, unless allowInsecure is set for development providers.
+type Client struct {
+	httpClient    *http.Client
+	allowInsecure bool
+
+	mu        sync.Mutex
+	providers map[string]provider
+}
+
+// NewClient initializes a new OpenID Connect client.
+// allowInsecure lets it talk to providers served over plain http, it is meant for development only.
+func NewClient(allowInsecure bool) *Client {
+	return &Client{
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+		allowInsecure: allowInsecure,
+		providers:     map[string]provider{},
+	}
+}
+
+// GenerateCodeVerifier returns a random PKCE code verifier.
+func GenerateCodeVerifier() (string, error) {
+	verifier := make([]byte, 32)
+	if _, err := rand.Read(verifier); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(verifier), nil
+}
+
+// CodeChallenge returns the S256 PKCE code challenge of a code verifier.
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthorizationURL returns the URL of the provider the user is sent to in order to sign in.
+func (c *Client) AuthorizationURL(ctx context.Context, issuer string, request AuthorizationRequest) (string, error) {
+	p, err := c.provider(ctx, issuer, false)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("scope", "openid email profile")
+	query.Set("client_id", request.ClientID)
+	query.Set("redirect_uri", request.RedirectURI)
+	query.Set("state", request.State)
+	query.Set("nonce", request.Nonce)
+	query.Set("code_challenge", request.CodeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return p.metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange exchanges an authorization code for tokens and returns the verified claims of the ID token.
+// The ID token has to be issued by issuer, for clientID and carry the given nonce.
+func (c *Client) Exchange(ctx context.Context, issuer, nonce string, request TokenRequest) (*Claims, error) {
+	const ops = "oidc.Client.Exchange"
+
+	p, err := c.provider(ctx, issuer, false)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", request.Code)
+	form.Set("redirect_uri", request.RedirectURI)
+	form.Set("client_id", request.ClientID)
+	form.Set("code_verifier", request.CodeVerifier)
+	if request.ClientSecret != "" {
+		form.Set("client_secret", request.ClientSecret)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpRequest.Header.Set("Accept", "application/json")
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := c.do(httpRequest, &tokenResponse); err != nil {
+		logger.Errorf(ctx, ops, "failed to exchange authorization code: %v", err)
+		return nil, err
+	}
+
+	if tokenResponse.IDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+
+	return c.verifyIDToken(ctx, issuer, request.ClientID, nonce, tokenResponse.IDToken)
+}
+
+func (c *Client) verifyIDToken(ctx context.Context, issuer, clientID, nonce, rawIDToken string) (*Claims, error) {
+	const ops = "oidc.Client.verifyIDToken"
+
+	keyFunc := func(refresh bool) jwt.Keyfunc {
+		return func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+
+			p, err := c.provider(ctx, issuer, refresh)
+			if err != nil {
+				return nil, err
+			}
+
+			kid, _ := token.Header["kid"].(string)
+			if key, found := p.keys[kid]; found {
+				return key, nil
+			}
+
+			// providers signing with a single key may omit the key ID.
+			if kid == "" && len(p.keys) == 1 {
+				for _, key := range p.keys {
+					return key, nil
+				}
+			}
+
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, keyFunc(false))
+	if err != nil {
+		// the provider may have rotated its keys since they were cached.
+		claims = jwt.MapClaims{}
+		_, err = jwt.ParseWithClaims(rawIDToken, claims, keyFunc(true))
+	}
+	if err != nil {
+		logger.Warn(ctx, ops, "failed to verify id token: %v", err)
+		return nil, ErrInvalidIDToken
+	}
+
+	if !claims.VerifyIssuer(issuer, true) || !claims.VerifyAudience(clientID, true) {
+		return nil, ErrInvalidIDToken
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, ErrInvalidIDToken
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, ErrInvalidIDToken
+	}
+
+	result := &Claims{Issuer: issuer, Subject: subject}
+	result.Email, _ = claims["email"].(string)
+	result.Name, _ = claims["name"].(string)
+	result.GivenName, _ = claims["given_name"].(string)
+	result.FamilyName, _ = claims["family_name"].(string)
+
+	// some providers send email_verified as a string.
+	switch emailVerified := claims["email_verified"].(type) {
+	case bool:
+		result.EmailVerified = emailVerified
+	case string:
+		result.EmailVerified = emailVerified == "true"
+	}
+
+	return result, nil
+}
+
+// provider returns the cached metadata and signing keys of an issuer, fetching them when missing,
+// expired or when refresh is true.
+func (c *Client) provider(ctx context.Context, issuer string, refresh bool) (provider, error) {
+	const ops = "oidc.Client.provider"
+
+	c.mu.Lock()
+	p, found := c.providers[issuer]
+	c.mu.Unlock()
+	if found && !refresh && time.Now().Before(p.expiresAt) {
+		return p, nil
+	}
+
+	if err := c.checkURL(issuer); err != nil {
+		return provider{}, err
+	}
+
+	discoveryRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return provider{}, err
+	}
+
+	var metadata ProviderMetadata
+	if err := c.do(discoveryRequest, &metadata); err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch discovery document of %s: %v", issuer, err)
+		return provider{}, err
+	}
+
+	if metadata.Issuer != issuer {
+		return provider{}, fmt.Errorf("oidc: discovery document issuer %q does not match %q", metadata.Issuer, issuer)
+	}
+
+	for _, endpoint := range []string{metadata.AuthorizationEndpoint, metadata.TokenEndpoint, metadata.JWKSURI} {
+		if err := c.checkURL(endpoint); err != nil {
+			return provider{}, err
+		}
+	}
+
+	keysRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, metadata.JWKSURI, nil)
+	if err != nil {
+		return provider{}, err
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := c.do(keysRequest, &jwks); err != nil {
+		logger.Errorf(ctx, ops, "failed to fetch signing keys of %s: %v", issuer, err)
+		return provider{}, err
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range jwks.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			continue
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			continue
+		}
+
+		keys[key.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	p = provider{metadata: metadata, keys: keys, expiresAt: time.Now().Add(providerCacheTTL)}
+
+	c.mu.Lock()
+	c.providers[issuer] = p
+	c.mu.Unlock()
+
+	return p, nil
+}
+
+// checkURL returns ErrInsecureURL when rawURL is not an absolute https URL, or http when insecure providers are allowed.
+func (c *Client) checkURL(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Host == "" {
+		return ErrInsecureURL
+	}
+
+	if parsedURL.Scheme != "https" && !(c.allowInsecure && parsedURL.Scheme == "http") {
+		return ErrInsecureURL
+	}
+
+	return nil
+}
+
+func (c *Client) do(request *http.Request, responseDst any) error {
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: %s %s responded %d: %s", request.Method, request.URL.Path, response.StatusCode, body)
+	}
+
+	return json.Unmarshal(body, responseDst)
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mhdiiilham/gosm/logger"
+)
+
+// ErrInvalidKey is returned when a key would escape the storage directory.
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// LocalStorage stores files in a directory of the local disk, which has to be served at publicURL.
+// It is meant for local development and single instance deployments.
+type LocalStorage struct {
+	dir       string
+	publicURL string
+}
+
+// NewLocalStorage initializes a new LocalStorage writing into dir, whose files are served at publicURL.
+func NewLocalStorage(dir, publicURL string) *LocalStorage {
+	return &LocalStorage{dir: dir, publicURL: strings.TrimSuffix(publicURL, "/")}
+}
+
+// Put stores content under key, replacing any file stored under the same key, and returns its public URL.
+func (s *LocalStorage) Put(ctx context.Context, key, contentType string, content io.Reader) (string, error) {
+	const ops = "LocalStorage.Put"
+
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		logger.Errorf(ctx, ops, "failed to create directory of %s: %v", key, err)
+		return "", err
+	}
+
+	// the file is written next to its destination first, so a failed upload never leaves a partial file behind.
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		logger.Errorf(ctx, ops, "failed to create %s: %v", key, err)
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := io.Copy(tmpFile, content); err != nil {
+		tmpFile.Close()
+		logger.Errorf(ctx, ops, "failed to write %s: %v", key, err)
+		return "", err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Chmod(tmpFile.Name(), 0o644); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		logger.Errorf(ctx, ops, "failed to move %s in place: %v", key, err)
+		return "", err
+	}
+
+	return s.publicURL + "/" + key, nil
+}
+
+// Delete removes the file stored at the given public URL. Files that do not exist,
+// or URLs not pointing to this storage, are ignored.
+func (s *LocalStorage) Delete(ctx context.Context, url string) error {
+	const ops = "LocalStorage.Delete"
+
+	key, found := strings.CutPrefix(url, s.publicURL+"/")
+	if !found {
+		return nil
+	}
+
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Errorf(ctx, ops, "failed to delete %s: %v", key, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.dir, cleaned), nil
+}